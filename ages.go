@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// AgeTracker estimates how long goroutines have been running from
+// periodic snapshots of the running goroutines, since the runtime only
+// reports how long goroutines have been blocked. See
+// [TrackGoroutineAges].
+type AgeTracker struct {
+	interval time.Duration
+	self     int // ID of the goroutine that takes snapshots
+
+	mu sync.Mutex
+	// last is when the latest snapshot was taken.
+	last time.Time
+	// startedAfter holds, for the goroutines of the latest snapshot,
+	// when the snapshot before the one that first saw them was taken,
+	// or the zero time for those already running in the first snapshot.
+	startedAfter map[int]time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// TrackGoroutineAges takes a snapshot of the running goroutines every
+// interval, to estimate how long they've been running, until it's
+// stopped with Stop. Goroutines are told apart by their IDs, which the
+// runtime never reuses. Ages are accurate to the interval, and unknown
+// for the goroutines that were already running when tracking started.
+//
+// It lets long-running checks, such as those of [Handler],
+// [DetectGrowth] and [Detector.Monitor], skip goroutines that were
+// just started and are likely still doing their job:
+//
+//	ages, err := goleak.TrackGoroutineAges(time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	defer ages.Stop()
+//	http.Handle("/debug/goleak", goleak.Handler(ages.IgnoreYoungerThan(time.Minute)))
+//
+// Every snapshot dumps the stacks of all goroutines, so the interval
+// shouldn't be much shorter than the ages of interest.
+func TrackGoroutineAges(interval time.Duration) (*AgeTracker, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("non-positive interval %v", interval)
+	}
+	t := &AgeTracker{
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	t.snapshot()
+
+	started := make(chan int)
+	go t.run(started)
+	t.self = <-started
+	close(started)
+	return t, nil
+}
+
+// Age returns how long the goroutine with the given ID has been running
+// at most. It returns false if the goroutine was already running when
+// tracking started, in which case its age is unknown.
+func (t *AgeTracker) Age(id int) (time.Duration, bool) {
+	return t.age(id, time.Now())
+}
+
+// IgnoreYoungerThan ignores the goroutines that have been running for
+// less than d, and the goroutine that takes the snapshots of the
+// tracker. Goroutines whose age is unknown aren't ignored. Since ages
+// are overestimated by up to the interval of the tracker, goroutines
+// that are almost d old may not be ignored either.
+func (t *AgeTracker) IgnoreYoungerThan(d time.Duration) Option {
+	if d < 0 {
+		invalidOption("IgnoreYoungerThan", "negative age %v", d)
+	}
+	return optionFunc(func(opts *opts) {
+		opts.ageTrackers = append(opts.ageTrackers, t)
+		opts.filters = append(opts.filters, filter{
+			name: fmt.Sprintf("IgnoreYoungerThan(%v)", d),
+			match: func(s stack.Stack) bool {
+				age, ok := t.Age(s.ID())
+				return ok && age < d
+			},
+		})
+	})
+}
+
+// Stop stops taking snapshots and waits for the background goroutine
+// to exit. Goroutines started afterwards are aged from the last
+// snapshot. It is safe to call Stop multiple times.
+func (t *AgeTracker) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+}
+
+func (t *AgeTracker) run(started chan int) {
+	defer close(t.done)
+
+	started <- stack.Current().ID()
+	<-started // wait for self to be set
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.snapshot()
+		}
+	}
+}
+
+// snapshot records the goroutines that are running. Those that exited
+// since the last snapshot are forgotten.
+func (t *AgeTracker) snapshot() {
+	// Goroutines missing from the last snapshot started after it was
+	// taken, so take the time before the stacks.
+	now := time.Now()
+	stacks := stack.All()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	first := t.startedAfter == nil
+	startedAfter := make(map[int]time.Time, len(stacks))
+	for _, s := range stacks {
+		after, ok := t.startedAfter[s.ID()]
+		if !ok && !first {
+			after = t.last
+		}
+		startedAfter[s.ID()] = after
+	}
+	t.startedAfter = startedAfter
+	t.last = now
+}
+
+// age implements Age as of now.
+func (t *AgeTracker) age(id int, now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	after, ok := t.startedAfter[id]
+	if !ok {
+		// Not seen yet, so it started after the last snapshot.
+		return now.Sub(t.last), true
+	}
+	if after.IsZero() {
+		return 0, false
+	}
+	return now.Sub(after), true
+}
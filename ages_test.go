@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackGoroutineAges(t *testing.T) {
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	// Wait for those of other tests to exit, which would be older.
+	require.NoError(t, Find(include))
+	blockedIDs := func() []int { return stackIDs(FilterStacks(stack.All(), include)) }
+
+	bgOld := startBlockedG()
+	defer bgOld.unblock()
+	oldIDs := blockedIDs()
+	require.Len(t, oldIDs, 1)
+
+	ages, err := TrackGoroutineAges(time.Hour)
+	require.NoError(t, err)
+	defer ages.Stop()
+	_, ok := ages.Age(oldIDs[0])
+	assert.False(t, ok, "goroutines running before tracking have no age")
+
+	bgNew := startBlockedG()
+	defer bgNew.unblock()
+	ids := blockedIDs()
+	require.Len(t, ids, 2)
+	newID := ids[0] + ids[1] - oldIDs[0]
+	age, ok := ages.Age(newID)
+	require.True(t, ok)
+	assert.Less(t, age, time.Hour)
+
+	t.Run("snapshots", func(t *testing.T) {
+		first := ages.last
+		ages.snapshot()
+		age, ok := ages.age(newID, first.Add(time.Minute))
+		require.True(t, ok)
+		assert.Equal(t, time.Minute, age, "ages should count from the snapshot before the goroutine was seen")
+		_, ok = ages.Age(oldIDs[0])
+		assert.False(t, ok)
+	})
+
+	t.Run("IgnoreYoungerThan", func(t *testing.T) {
+		assert.Equal(t, oldIDs, stackIDs(FilterStacks(stack.All(), include, ages.IgnoreYoungerThan(time.Hour))))
+		assert.Equal(t, ids, stackIDs(FilterStacks(stack.All(), include, ages.IgnoreYoungerThan(0))))
+		for _, s := range FilterStacks(stack.All(), ages.IgnoreYoungerThan(0)) {
+			assert.NotEqual(t, ages.self, s.ID(), "the tracker should be ignored")
+		}
+	})
+}
+
+func TestTrackGoroutineAgesInBackground(t *testing.T) {
+	defer VerifyNone(t)
+
+	ages, err := TrackGoroutineAges(time.Millisecond)
+	require.NoError(t, err)
+	defer ages.Stop()
+
+	bg := startBlockedG()
+	defer bg.unblock()
+	require.Eventually(t, func() bool {
+		ages.mu.Lock()
+		defer ages.mu.Unlock()
+		for _, after := range ages.startedAfter {
+			if !after.IsZero() {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, time.Millisecond, "new goroutines should be recorded by later snapshots")
+
+	ages.Stop()
+	ages.Stop()
+}
+
+func TestTrackGoroutineAgesErrors(t *testing.T) {
+	_, err := TrackGoroutineAges(0)
+	assert.EqualError(t, err, "non-positive interval 0s")
+}
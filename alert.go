@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// LeakAlert describes leaked goroutines with the same fingerprint,
+// as reported to the function given to [Alert].
+type LeakAlert struct {
+	// Fingerprint identifies the leak; see [stack.Stack.Fingerprint].
+	Fingerprint string
+	// Function is the function on top of the stacks of the goroutines.
+	Function string
+	// Count is the number of goroutines with the fingerprint
+	// that the check found.
+	Count int
+	// FirstSeen is when a check first found the fingerprint.
+	FirstSeen time.Time
+	// Suppressed counts the checks that found the fingerprint since
+	// the previous alert for it, without alerting.
+	Suppressed int
+	// Example is the stack of one of the goroutines.
+	Example stack.Stack
+}
+
+// AlertOption configures [Alert].
+type AlertOption interface {
+	applyAlert(*alerter)
+}
+
+type alertOptionFunc func(*alerter)
+
+func (f alertOptionFunc) applyAlert(a *alerter) { f(a) }
+
+// MinInterval makes [Alert] alert again for a fingerprint that's still
+// leaked at most once per d, instead of only once.
+func MinInterval(d time.Duration) AlertOption {
+	if d <= 0 {
+		invalidOption("MinInterval", "interval %v must be positive", d)
+	}
+	return alertOptionFunc(func(a *alerter) {
+		a.minInterval = d
+	})
+}
+
+// Alert calls fn for the leaks found by checks, e.g. by [Find] or
+// [Verify], once per fingerprint. It's meant for services that check
+// for leaks periodically and forward them to logs or webhooks, where
+// a persistent leak shouldn't alert on every check:
+//
+//	alert := goleak.Alert(func(a goleak.LeakAlert) {
+//		log.Printf("goroutine leak: %d goroutines in %v", a.Count, a.Function)
+//	}, goleak.MinInterval(time.Hour))
+//	for range time.Tick(time.Minute) {
+//		_ = goleak.Find(alert, goleak.IgnoreStartupGoroutines(time.Minute))
+//	}
+//
+// The returned option keeps the state of the alerts, so the same
+// option must be passed to every check. By default, fn is called once
+// per fingerprint for as long as the process runs; with [MinInterval],
+// it's called again for fingerprints that are still leaked after the
+// interval. fn is called synchronously by the check, after [OnLeak].
+func Alert(fn func(LeakAlert), options ...AlertOption) Option {
+	if fn == nil {
+		invalidOption("Alert", "nil function")
+	}
+	a := &alerter{fn: fn, seen: make(map[string]*alertState)}
+	for _, option := range options {
+		option.applyAlert(a)
+	}
+	return optionFunc(func(opts *opts) {
+		opts.alerts = append(opts.alerts, a)
+	})
+}
+
+// alerter deduplicates and rate limits the alerts of an Alert option.
+type alerter struct {
+	fn          func(LeakAlert)
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*alertState
+}
+
+// alertState is what an alerter remembers about a fingerprint.
+type alertState struct {
+	firstSeen  time.Time
+	lastAlert  time.Time
+	suppressed int
+}
+
+// notify alerts for the fingerprints of stacks that are due.
+func (a *alerter) notify(stacks []stack.Stack) {
+	now := time.Now()
+
+	var (
+		alerts []LeakAlert
+		index  = make(map[string]int)
+	)
+	a.mu.Lock()
+	for _, s := range stacks {
+		fp := s.Fingerprint()
+		if i, ok := index[fp]; ok {
+			if i >= 0 {
+				alerts[i].Count++
+			}
+			continue
+		}
+
+		state, ok := a.seen[fp]
+		if !ok {
+			state = &alertState{firstSeen: now}
+			a.seen[fp] = state
+		} else if a.minInterval == 0 || now.Sub(state.lastAlert) < a.minInterval {
+			state.suppressed++
+			index[fp] = -1
+			continue
+		}
+
+		index[fp] = len(alerts)
+		alerts = append(alerts, LeakAlert{
+			Fingerprint: fp,
+			Function:    s.FirstFunction(),
+			Count:       1,
+			FirstSeen:   state.firstSeen,
+			Suppressed:  state.suppressed,
+			Example:     s,
+		})
+		state.lastAlert = now
+		state.suppressed = 0
+	}
+	a.mu.Unlock()
+
+	for _, alert := range alerts {
+		a.fn(alert)
+	}
+}
+
+// alert reports stacks to the Alert options.
+func (o *opts) alert(stacks []stack.Stack) {
+	for _, a := range o.alerts {
+		a.notify(stacks)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlert(t *testing.T) {
+	dump := []byte(`goroutine 7 [chan receive]:
+main.worker()
+	/app/main.go:10 +0x25
+created by main.main in goroutine 1
+	/app/main.go:5 +0x25
+
+goroutine 8 [chan receive]:
+main.worker()
+	/app/main.go:10 +0x25
+created by main.main in goroutine 1
+	/app/main.go:5 +0x25
+
+goroutine 9 [select]:
+main.poller()
+	/app/main.go:20 +0x25
+created by main.main in goroutine 1
+	/app/main.go:6 +0x25
+`)
+
+	t.Run("once per fingerprint", func(t *testing.T) {
+		var alerts []LeakAlert
+		alert := Alert(func(a LeakAlert) { alerts = append(alerts, a) })
+
+		require.Error(t, FindInDump(dump, alert))
+		require.Len(t, alerts, 2)
+		byFunction := make(map[string]LeakAlert)
+		for _, a := range alerts {
+			byFunction[a.Function] = a
+		}
+		worker, poller := byFunction["main.worker"], byFunction["main.poller"]
+		assert.Equal(t, 2, worker.Count)
+		assert.Equal(t, 7, worker.Example.ID())
+		assert.Equal(t, 1, poller.Count)
+		assert.NotEqual(t, worker.Fingerprint, poller.Fingerprint)
+
+		require.Error(t, FindInDump(dump, alert))
+		assert.Len(t, alerts, 2, "persistent leaks are not alerted again")
+	})
+
+	t.Run("MinInterval", func(t *testing.T) {
+		var alerts []LeakAlert
+		alert := Alert(func(a LeakAlert) { alerts = append(alerts, a) }, MinInterval(50*time.Millisecond))
+
+		require.Error(t, FindInDump(dump, alert, IgnoreTopFunction("main.poller")))
+		require.Error(t, FindInDump(dump, alert, IgnoreTopFunction("main.poller")))
+		require.Error(t, FindInDump(dump, alert, IgnoreTopFunction("main.poller")))
+		require.Len(t, alerts, 1)
+		first := alerts[0]
+
+		time.Sleep(60 * time.Millisecond)
+		require.Error(t, FindInDump(dump, alert))
+		require.Len(t, alerts, 3)
+		worker, poller := alerts[1], alerts[2]
+		if worker.Function != "main.worker" {
+			worker, poller = poller, worker
+		}
+		assert.Equal(t, "main.worker", worker.Function)
+		assert.Equal(t, 2, worker.Suppressed, "checks since the previous alert")
+		assert.Equal(t, first.FirstSeen, worker.FirstSeen)
+		assert.Equal(t, "main.poller", poller.Function, "new fingerprints are alerted right away")
+		assert.Zero(t, poller.Suppressed)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		var alerts []LeakAlert
+		require.Error(t, Find(testOptions(), Alert(func(a LeakAlert) { alerts = append(alerts, a) })))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", alerts[0].Function)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Panics(t, func() { Alert(nil) })
+		assert.Panics(t, func() { MinInterval(0) })
+	})
+}
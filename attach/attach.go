@@ -0,0 +1,149 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package attach retrieves goroutine dumps from other Go processes
+// and runs goleak's filters on them.
+//
+// The target process must run a gops agent
+// (https://github.com/google/gops), which most long-running services
+// can enable without exposing pprof over HTTP:
+//
+//	if err := agent.Listen(agent.Options{}); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The process is then inspected by PID without restarting it:
+//
+//	err := attach.Find(ctx, pid, goleak.IgnoreTopFunction("main.worker"))
+package attach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// _stackTraceSignal asks a gops agent for a goroutine dump.
+const _stackTraceSignal byte = 0x1
+
+// Goroutines started by the gops agent itself, which are present
+// in every dump it produces.
+var _agentFunctions = []string{
+	"github.com/google/gops/agent.listen",
+	"github.com/google/gops/agent.handle",
+}
+
+// Find retrieves a goroutine dump from the process with the given PID
+// and looks for unexpected goroutines in it with [goleak.FindInDump].
+// Goroutines that belong to the gops agent are ignored.
+func Find(ctx context.Context, pid int, options ...goleak.Option) error {
+	dump, err := Dump(ctx, pid)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range _agentFunctions {
+		options = append(options, goleak.IgnoreAnyFunction(f))
+	}
+	return goleak.FindInDump(dump, options...)
+}
+
+// Dump retrieves a goroutine dump from the gops agent of the process
+// with the given PID.
+func Dump(ctx context.Context, pid int) ([]byte, error) {
+	addr, err := agentAddr(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to gops agent of process %d: %w", pid, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write([]byte{_stackTraceSignal}); err != nil {
+		return nil, fmt.Errorf("request goroutine dump: %w", err)
+	}
+	dump, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("read goroutine dump: %w", err)
+	}
+	return dump, nil
+}
+
+// agentAddr returns the address the gops agent of the given process
+// listens on, as recorded in its port file.
+func agentAddr(pid int) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+
+	portFile := filepath.Join(dir, strconv.Itoa(pid))
+	b, err := os.ReadFile(portFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("process %d is not running a gops agent: %w", pid, err)
+		}
+		return "", err
+	}
+
+	port := strings.TrimSpace(string(b))
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", fmt.Errorf("bad port %q in %v", port, portFile)
+	}
+	return net.JoinHostPort("127.0.0.1", port), nil
+}
+
+// configDir returns the directory in which gops agents write their
+// port files, following the same rules as gops itself.
+func configDir() (string, error) {
+	if dir := os.Getenv("GOPS_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "gops"), nil
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gops"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("find gops config directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "gops"), nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package attach
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _fakePID = 4242
+
+var _fakeDump = strings.Join([]string{
+	"goroutine 1 [running]:",
+	"runtime/pprof.writeGoroutineStacks({0x7b0f60, 0xc000012345})",
+	"	/usr/lib/go/src/runtime/pprof/pprof.go:703 +0x6a",
+	"github.com/google/gops/agent.handle({0x7b0f60, 0xc000012345}, {0xc000100000, 0x1, 0x1})",
+	"	/go/pkg/mod/github.com/google/gops/agent/agent.go:200 +0x1a",
+	"",
+	"goroutine 7 [chan receive, 12 minutes]:",
+	"main.worker(0xc000020000)",
+	"	/app/main.go:42 +0x25",
+	"created by main.main in goroutine 1",
+	"	/app/main.go:20 +0x85",
+	"",
+}, "\n")
+
+// startFakeAgent serves dump like a gops agent of _fakePID would.
+func startFakeAgent(t *testing.T, dump string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	t.Setenv("GOPS_CONFIG_DIR", dir)
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, strconv.Itoa(_fakePID)), []byte(port), 0o600))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			sig := make([]byte, 1)
+			if _, err := conn.Read(sig); err == nil && sig[0] == _stackTraceSignal {
+				_, _ = conn.Write([]byte(dump))
+			}
+			_ = conn.Close()
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		<-done
+	})
+}
+
+func TestFind(t *testing.T) {
+	startFakeAgent(t, _fakeDump)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dump, err := Dump(ctx, _fakePID)
+	require.NoError(t, err)
+	assert.Equal(t, _fakeDump, string(dump))
+
+	err = Find(ctx, _fakePID)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "main.worker")
+	assert.NotContains(t, err.Error(), "gops/agent", "agent goroutines should be ignored")
+
+	assert.NoError(t, Find(ctx, _fakePID, goleak.IgnoreTopFunction("main.worker")))
+}
+
+func TestFindNoAgent(t *testing.T) {
+	t.Setenv("GOPS_CONFIG_DIR", t.TempDir())
+
+	err := Find(context.Background(), _fakePID)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "is not running a gops agent")
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Await blocks until no goroutines remain other than the calling one and
+// those excluded by the options, or until ctx is done. Unlike [Find], it
+// waits for as long as ctx allows rather than for a number of retries,
+// which suits graceful shutdowns that must wait for workers to exit:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := goleak.Await(ctx, goleak.IgnoreCurrent()); err != nil {
+//		log.Printf("shutdown: %v", err)
+//	}
+//
+// Goroutines are checked with an exponential backoff of up to 100ms.
+// If ctx is done first, Await returns an error that wraps ctx.Err()
+// and describes the remaining goroutines.
+func Await(ctx context.Context, options ...Option) error {
+	cur := stack.Current().ID()
+
+	opts := buildOpts(options...)
+	if opts.cleanup != nil {
+		return errors.New("Cleanup can only be passed to VerifyNone or VerifyTestMain")
+	}
+	defer _dumps.startCheck(cur)()
+
+	for i := 0; ; i++ {
+		stacks := filterStacks(opts.stacks(), cur, opts)
+		if len(stacks) == 0 {
+			return nil
+		}
+
+		// Stop doubling well before the delay overflows.
+		d := min(time.Microsecond<<min(i, 30), opts.maxSleep)
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("found unexpected goroutines after waiting: %w\n%s%s",
+				ctx.Err(), opts.display(stacks), opts.notes(stacks, leakCheck{}))
+		case <-timer.C:
+		}
+	}
+}
+
+// WaitForGoroutine blocks until a goroutine other than the calling one
+// matches matcher, or until ctx is done. It replaces sleeps in tests
+// that must wait for a goroutine to start or to reach a known point:
+//
+//	go srv.Serve(ln)
+//	err := goleak.WaitForGoroutine(ctx, goleak.IgnoreTopFunction("net.(*TCPListener).Accept"))
+//
+// matcher is an option that ignores or includes goroutines, such as
+// [IgnoreTopFunction], [IgnoreAnyFunction] or [IncludeAnyFunction];
+// a goroutine matches if the option would ignore or include it.
+// Several options can be given with [Combine], in which case any of
+// them may match. If ctx is done first, WaitForGoroutine returns an
+// error that wraps ctx.Err().
+func WaitForGoroutine(ctx context.Context, matcher Option) error {
+	return waitFor(ctx, matcher, true)
+}
+
+// WaitForNoGoroutine blocks until no goroutine other than the calling
+// one matches matcher, or until ctx is done. matcher is interpreted as
+// by [WaitForGoroutine]. Unlike [Await], WaitForNoGoroutine waits for
+// the matching goroutines only, regardless of any others:
+//
+//	srv.Close()
+//	err := goleak.WaitForNoGoroutine(ctx, goleak.IgnoreAnyFunction("example.com/server.(*Server).worker"))
+//
+// If ctx is done first, WaitForNoGoroutine returns an error that wraps
+// ctx.Err() and describes the matching goroutines.
+func WaitForNoGoroutine(ctx context.Context, matcher Option) error {
+	return waitFor(ctx, matcher, false)
+}
+
+// waitFor polls until a goroutine matching matcher exists or not,
+// depending on exist, with the same backoff as Await.
+func waitFor(ctx context.Context, matcher Option, exist bool) error {
+	cur := stack.Current().ID()
+
+	opts := buildOnlyOpts(matcher)
+	if len(opts.filters) == 0 && len(opts.includes) == 0 {
+		return errors.New("matcher must ignore or include goroutines")
+	}
+	matches := func(s stack.Stack) bool {
+		if opts.include(s) {
+			return true
+		}
+		for _, f := range opts.filters {
+			if f.match(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; ; i++ {
+		var matched []stack.Stack
+		for _, s := range opts.stacks() {
+			if s.ID() != cur && matches(s) {
+				matched = append(matched, s)
+			}
+		}
+		if (len(matched) > 0) == exist {
+			return nil
+		}
+
+		d := min(time.Microsecond<<min(i, 30), opts.maxSleep)
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if exist {
+				return fmt.Errorf("no matching goroutine after waiting: %w", ctx.Err())
+			}
+			return fmt.Errorf("found matching goroutines after waiting: %w\n%s",
+				ctx.Err(), opts.display(matched))
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwait(t *testing.T) {
+	t.Run("goroutines exit", func(t *testing.T) {
+		bg := startBlockedG()
+		time.AfterFunc(50*time.Millisecond, bg.unblock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, Await(ctx))
+	})
+
+	t.Run("context expires", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := Await(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "blockedG")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, Await(ctx), context.Canceled)
+
+		// A canceled context allows a single check.
+		waitForStable(t)
+		assert.NoError(t, Await(ctx, IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")),
+			"ignored goroutines should not be waited for")
+	})
+
+	t.Run("Cleanup", func(t *testing.T) {
+		assert.ErrorContains(t, Await(context.Background(), Cleanup(func(int) {})), "Cleanup can only be passed")
+	})
+}
+
+func TestWaitForGoroutine(t *testing.T) {
+	const blockFn = "github.com/projectdiscovery/goleak.blockedOnReceive"
+	matcher := IgnoreTopFunction(blockFn)
+
+	t.Run("appears", func(t *testing.T) {
+		ch := make(chan struct{})
+		defer close(ch)
+		time.AfterFunc(50*time.Millisecond, func() { go blockedOnReceive(ch) })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, WaitForGoroutine(ctx, matcher))
+	})
+
+	t.Run("never appears", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := WaitForGoroutine(ctx, IgnoreTopFunction("example.com/missing.worker"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("disappears", func(t *testing.T) {
+		ch := make(chan struct{})
+		go blockedOnReceive(ch)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, WaitForGoroutine(ctx, matcher))
+
+		time.AfterFunc(50*time.Millisecond, func() { close(ch) })
+		require.NoError(t, WaitForNoGoroutine(ctx, matcher))
+	})
+
+	t.Run("never disappears", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := WaitForNoGoroutine(ctx, IncludeAnyFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "blockedG")
+	})
+
+	t.Run("Combine", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		err := WaitForGoroutine(context.Background(), Combine(
+			IgnoreTopFunction("example.com/missing.worker"),
+			IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"),
+		))
+		require.NoError(t, err)
+	})
+
+	t.Run("no matcher", func(t *testing.T) {
+		assert.ErrorContains(t, WaitForGoroutine(context.Background(), Pretty()), "matcher must")
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Baseline is a set of goroutines to ignore, created by [NewBaseline].
+// It's an Option, so it can be passed to Find and Verify calls directly.
+//
+// Baselines are safe for concurrent use. Options built from a baseline
+// see the changes made by Refresh, Release and Merge afterwards.
+type Baseline struct {
+	mu  sync.RWMutex
+	ids map[int]struct{}
+}
+
+var _ Option = (*Baseline)(nil)
+
+func (b *Baseline) apply(opts *opts) {
+	opts.filters = append(opts.filters, filter{name: "IgnoreCurrent()", match: b.contains})
+}
+
+// ignoreExisting ignores the goroutines that are running, like
+// [IgnoreCurrent], for checks that ignore them on their own.
+// Its filter is internal, since it wasn't given by the user.
+func ignoreExisting() Option {
+	b := NewBaseline()
+	return optionFunc(func(opts *opts) {
+		opts.filters = append(opts.filters, filter{name: "IgnoreCurrent()", match: b.contains, internal: true})
+	})
+}
+
+func (b *Baseline) contains(s stack.Stack) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.ids[s.ID()]
+	return ok
+}
+
+// Refresh replaces the recorded goroutines with the current goroutines,
+// so that goroutines started since the baseline was recorded are
+// ignored too. Use it between the phases of long test suites, after
+// setup started more goroutines that are expected to keep running.
+func (b *Baseline) Refresh() {
+	ids := make(map[int]struct{})
+	for _, s := range stack.All() {
+		ids[s.ID()] = struct{}{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ids = ids
+}
+
+// Release forgets the recorded goroutines, so that the baseline
+// no longer ignores any goroutine, and frees their memory.
+func (b *Baseline) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ids = nil
+}
+
+// Merge returns a new baseline that ignores the goroutines
+// recorded by any of the given baselines.
+func (b *Baseline) Merge(others ...*Baseline) *Baseline {
+	ids := make(map[int]struct{})
+	for _, o := range append([]*Baseline{b}, others...) {
+		o.mu.RLock()
+		for id := range o.ids {
+			ids[id] = struct{}{}
+		}
+		o.mu.RUnlock()
+	}
+	return &Baseline{ids: ids}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseline(t *testing.T) {
+	t.Run("refresh", func(t *testing.T) {
+		defer VerifyNone(t)
+
+		b := NewBaseline()
+		bg := startBlockedG()
+		defer bg.unblock()
+		assert.Error(t, Find(testOptions(), b), "goroutine started after the baseline is a leak")
+
+		b.Refresh()
+		assert.NoError(t, Find(testOptions(), b), "goroutine is ignored after refresh")
+	})
+
+	t.Run("release", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		b := NewBaseline()
+		assert.NoError(t, Find(testOptions(), b))
+
+		b.Release()
+		assert.Error(t, Find(testOptions(), b), "released baseline ignores nothing")
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		defer VerifyNone(t)
+
+		bg1 := startBlockedG()
+		defer bg1.unblock()
+		b1 := NewBaseline()
+
+		bg2 := startBlockedG()
+		defer bg2.unblock()
+		b2 := NewBaseline()
+		assert.Error(t, Find(testOptions(), b1), "b1 doesn't have the second goroutine")
+
+		// Releasing the sources doesn't affect the merged baseline.
+		merged := b1.Merge(b2)
+		b1.Release()
+		b2.Release()
+		assert.NoError(t, Find(testOptions(), merged))
+
+		bg3 := startBlockedG()
+		defer bg3.unblock()
+		assert.Error(t, Find(testOptions(), merged))
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// FindBlockedOn returns the goroutines, other than the calling one, that
+// are blocked with the named function anywhere in their stacks, e.g. on
+// a channel operation, mutex or select inside of it. Goroutines that are
+// running or runnable in the function aren't returned.
+//
+// It lets tests wait for a goroutine to reach a known point before they
+// continue, without adding hooks to the code under test:
+//
+//	go worker.Run()
+//	for len(goleak.FindBlockedOn("example.com/worker.(*Worker).Fetch")) == 0 {
+//		runtime.Gosched()
+//	}
+//
+// The function name should be fully qualified, as in [IgnoreAnyFunction].
+func FindBlockedOn(funcName string) []stack.Stack {
+	cur := stack.Current().ID()
+
+	var blocked []stack.Stack
+	for _, s := range stack.All() {
+		if s.ID() == cur || !isBlocked(s) {
+			continue
+		}
+		if s.HasFunction(funcName) {
+			blocked = append(blocked, s)
+		}
+	}
+	return blocked
+}
+
+// isBlocked reports whether the goroutine of s is waiting,
+// rather than running or ready to run.
+func isBlocked(s stack.Stack) bool {
+	switch s.WaitReason() {
+	case "running", "runnable":
+		return false
+	}
+	return true
+}
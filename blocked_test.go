@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blockedOnReceive(ch chan struct{}) {
+	<-ch
+}
+
+func TestFindBlockedOn(t *testing.T) {
+	const fn = "github.com/projectdiscovery/goleak.blockedOnReceive"
+	assert.Empty(t, FindBlockedOn(fn), "no goroutine is blocked yet")
+
+	ch := make(chan struct{})
+	defer close(ch)
+	go blockedOnReceive(ch)
+	go blockedOnReceive(ch)
+
+	var blocked int
+	for i := 0; i < 1000 && blocked < 2; i++ {
+		time.Sleep(time.Millisecond)
+		blocked = len(FindBlockedOn(fn))
+	}
+	require.Equal(t, 2, blocked, "expected both goroutines to block")
+	for _, s := range FindBlockedOn(fn) {
+		assert.Equal(t, "chan receive", s.WaitReason())
+	}
+
+	assert.Empty(t, FindBlockedOn("github.com/projectdiscovery/goleak.TestFindBlockedOn"),
+		"the calling goroutine must not be returned")
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Budget counts the goroutines that the tests of each package leaked,
+// by fingerprint, to ratchet leaks down across CI runs: a run may keep
+// or fix the leaks of the runs before it, but not add new ones.
+//
+// Budgets are built from the [Summary] of a test run with
+// [Summary.Budget], and compared with [CompareBudgets]. The goleak
+// command does both:
+//
+//	GOLEAK_SUMMARY=$PWD/summary.jsonl go test ./...
+//	goleak budget -o new.json summary.jsonl
+//	goleak compare old.json new.json
+type Budget struct {
+	// Packages maps import paths to the leaks of their tests.
+	// Packages whose tests leaked nothing have empty snapshots.
+	Packages map[string]Snapshot `json:"packages"`
+}
+
+// Budget returns the leaks of the summary as a budget. If the tests of
+// a package ran more than once, e.g. with -count, the largest count of
+// each fingerprint is kept.
+func (s Summary) Budget() Budget {
+	b := Budget{Packages: make(map[string]Snapshot)}
+	for _, pkg := range s.Packages {
+		snap, ok := b.Packages[pkg.Package]
+		if !ok {
+			snap = Snapshot{
+				Time:      pkg.Time,
+				Counts:    make(map[string]int),
+				Functions: make(map[string]string),
+				States:    make(map[string]map[string]int),
+			}
+		}
+		counts := make(map[string]int)
+		states := make(map[string]map[string]int)
+		for _, leak := range pkg.Leaks {
+			fp := leak.Fingerprint
+			counts[fp]++
+			if states[fp] == nil {
+				states[fp] = make(map[string]int)
+			}
+			states[fp][leak.State]++
+			snap.Functions[fp] = leak.FirstFunction
+		}
+		for fp, n := range counts {
+			if n > snap.Counts[fp] {
+				snap.Counts[fp] = n
+				snap.States[fp] = states[fp]
+			}
+		}
+		if pkg.Time.After(snap.Time) {
+			snap.Time = pkg.Time
+		}
+		b.Packages[pkg.Package] = snap
+	}
+	return b
+}
+
+// LoadBudget reads a budget that was written as JSON.
+func LoadBudget(path string) (Budget, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Budget{}, err
+	}
+	var budget Budget
+	if err := json.Unmarshal(b, &budget); err != nil {
+		return Budget{}, fmt.Errorf("parse budget %v: %w", path, err)
+	}
+	return budget, nil
+}
+
+// BudgetDiff describes how the leaks of packages changed between
+// two budgets.
+type BudgetDiff struct {
+	// Packages lists the packages whose leaks changed,
+	// sorted by import path.
+	Packages []PackageDiff
+}
+
+// PackageDiff is the change of the leaks of a single package.
+type PackageDiff struct {
+	Package string
+	SnapshotDiff
+}
+
+// CompareBudgets compares the budget of an older run with the budget of
+// a newer one. Packages that are missing from the newer budget, e.g.
+// because they were removed, count as having fixed their leaks.
+func CompareBudgets(older, newer Budget) BudgetDiff {
+	var diff BudgetDiff
+	add := func(pkg string, d SnapshotDiff) {
+		if !d.Empty() || len(d.Changed) > 0 {
+			diff.Packages = append(diff.Packages, PackageDiff{Package: pkg, SnapshotDiff: d})
+		}
+	}
+	for pkg, snap := range newer.Packages {
+		add(pkg, DiffSnapshots(older.Packages[pkg], snap))
+	}
+	for pkg, snap := range older.Packages {
+		if _, ok := newer.Packages[pkg]; !ok {
+			add(pkg, DiffSnapshots(snap, Snapshot{}))
+		}
+	}
+	sort.Slice(diff.Packages, func(i, j int) bool {
+		return diff.Packages[i].Package < diff.Packages[j].Package
+	})
+	return diff
+}
+
+// Regressed reports whether the newer budget has leaks that the older
+// one didn't: new fingerprints, or more goroutines of a fingerprint.
+func (d BudgetDiff) Regressed() bool {
+	for _, pkg := range d.Packages {
+		if len(pkg.Added) > 0 || len(pkg.Grown) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the diff of every package that changed
+// like [SnapshotDiff.String].
+func (d BudgetDiff) String() string {
+	if len(d.Packages) == 0 {
+		return "no changes\n"
+	}
+	var sb strings.Builder
+	for i, pkg := range d.Packages {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "package %v:\n%v", pkg.Package, pkg.SnapshotDiff)
+	}
+	return sb.String()
+}
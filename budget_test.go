@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryBudget(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	worker := LeakedGoroutine{Fingerprint: "a1", FirstFunction: "example.com/a.worker", State: "chan receive"}
+	summary := Summary{Packages: []PackageSummary{
+		{Package: "example.com/a", Time: now, Checked: true, Leaks: []LeakedGoroutine{worker}},
+		{Package: "example.com/a", Time: now.Add(time.Second), Checked: true, Leaks: []LeakedGoroutine{worker, worker}},
+		{Package: "example.com/clean", Time: now, Checked: true},
+	}}
+
+	budget := summary.Budget()
+	require.Len(t, budget.Packages, 2)
+	a := budget.Packages["example.com/a"]
+	assert.Equal(t, map[string]int{"a1": 2}, a.Counts, "the largest count of repeated runs should be kept")
+	assert.Equal(t, map[string]string{"a1": "example.com/a.worker"}, a.Functions)
+	assert.Equal(t, map[string]map[string]int{"a1": {"chan receive": 2}}, a.States)
+	assert.Equal(t, now.Add(time.Second), a.Time)
+	assert.Empty(t, budget.Packages["example.com/clean"].Counts)
+
+	t.Run("load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "budget.json")
+		b, err := json.Marshal(budget)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b, 0o644))
+
+		loaded, err := LoadBudget(path)
+		require.NoError(t, err)
+		assert.Equal(t, budget.Packages["example.com/a"].Counts, loaded.Packages["example.com/a"].Counts)
+
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+		_, err = LoadBudget(path)
+		assert.ErrorContains(t, err, "parse budget")
+	})
+}
+
+func TestCompareBudgets(t *testing.T) {
+	snap := func(counts map[string]int) Snapshot {
+		functions := make(map[string]string)
+		for fp := range counts {
+			functions[fp] = "example.com/pkg.fn" + fp
+		}
+		return Snapshot{Counts: counts, Functions: functions}
+	}
+	older := Budget{Packages: map[string]Snapshot{
+		"example.com/a":       snap(map[string]int{"1": 2, "2": 1}),
+		"example.com/b":       snap(map[string]int{"3": 1}),
+		"example.com/removed": snap(map[string]int{"4": 1}),
+	}}
+
+	t.Run("fixed leaks", func(t *testing.T) {
+		newer := Budget{Packages: map[string]Snapshot{
+			"example.com/a": snap(map[string]int{"1": 1}),
+			"example.com/b": snap(map[string]int{"3": 1}),
+		}}
+		diff := CompareBudgets(older, newer)
+		assert.False(t, diff.Regressed())
+		require.Len(t, diff.Packages, 2)
+		assert.Equal(t, "example.com/a", diff.Packages[0].Package)
+		assert.Equal(t, "example.com/removed", diff.Packages[1].Package)
+		assert.Contains(t, diff.String(), "package example.com/a:\n--- before\n+++ after\n- example.com/pkg.fn2: 1 -> 0 goroutines [2]\n")
+	})
+
+	t.Run("regressions", func(t *testing.T) {
+		newer := Budget{Packages: map[string]Snapshot{
+			"example.com/a":   snap(map[string]int{"1": 3, "2": 1}),
+			"example.com/b":   snap(map[string]int{"3": 1}),
+			"example.com/new": snap(map[string]int{"5": 1}),
+		}}
+		diff := CompareBudgets(older, newer)
+		assert.True(t, diff.Regressed())
+		assert.Contains(t, diff.String(), "+ example.com/pkg.fn1: 2 -> 3 goroutines [1]")
+		assert.Contains(t, diff.String(), "package example.com/new:\n--- before\n+++ after\n+ example.com/pkg.fn5: 0 -> 1 goroutines [5]")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		diff := CompareBudgets(older, older)
+		assert.False(t, diff.Regressed())
+		assert.Equal(t, "no changes\n", diff.String())
+	})
+}
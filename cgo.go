@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _cgoCallbacksFilter is the name of the IgnoreCgoCallbacks filter.
+const _cgoCallbacksFilter = "IgnoreCgoCallbacks()"
+
+// IgnoreCgoCallbacks ignores goroutines that belong to C code rather
+// than to Go code, as happens with cgo libraries such as libpcap or
+// libxml2:
+//
+//   - goroutines blocked in a call into C, e.g. in a capture loop that
+//     C keeps running, which are in a system call in a cgo stub;
+//   - goroutines of threads that C libraries created, which run the
+//     callbacks of these threads into Go, and which the runtime keeps
+//     around once the threads have called into Go.
+//
+// Dumps that show the frames of the runtime, e.g. with
+// GOTRACEBACK=system, also show callbacks from C into Go
+// on threads that Go created, which are ignored too.
+//
+// It also ignores the goroutines that [IgnoreSyscallStacks] does.
+// It is one of the [DefaultFilters]; use [ReportCgoCallbacks] to opt out.
+func IgnoreCgoCallbacks() Option {
+	return addFilter(_cgoCallbacksFilter, isCgoStack)
+}
+
+// ReportCgoCallbacks removes the [IgnoreCgoCallbacks] filter from the
+// [DefaultFilters], so that goroutines blocked in C code or in callbacks
+// from C are reported like others. It is useful to find C calls that
+// never return, e.g. because a library handle is never closed.
+func ReportCgoCallbacks() Option {
+	return DisableDefaultFilter(IgnoreCgoCallbacks())
+}
+
+func isCgoStack(s stack.Stack) bool {
+	if isSyscallStack(s) || s.HasFunction("runtime.cgocallbackg") {
+		return true
+	}
+	if strings.HasPrefix(s.State(), "syscall") {
+		if f := s.FirstFunction(); f == "runtime.cgocall" || isCgoStub(f) {
+			return true
+		}
+	}
+	// Goroutines for threads that C created have no creator, and are
+	// locked to their thread. Only the main goroutine is like them
+	// when it calls runtime.LockOSThread.
+	return s.LockedToThread() && s.CreatedBy() == "" && s.ID() != 1
+}
+
+// isCgoStub reports whether fn is a stub that cgo generates
+// to call a C function, e.g. "main._Cfunc_sleep".
+func isCgoStub(fn string) bool {
+	name := fn[strings.LastIndexByte(fn, '.')+1:]
+	return strings.HasPrefix(name, "_Cfunc_") || strings.HasPrefix(name, "_C2func_")
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreCgoCallbacks(t *testing.T) {
+	tests := []struct {
+		name string
+		dump string
+	}{
+		{
+			name: "blocked in C",
+			dump: `goroutine 6 [syscall]:
+main._Cfunc_pcap_loop(0x7f3a2c000b70, 0xffffffff)
+	_cgo_gotypes.go:46 +0x3a
+main.capture(...)
+	/app/main.go:30
+created by main.main in goroutine 1
+	/app/main.go:26 +0x1e
+`,
+		},
+		{
+			name: "callback from a C thread",
+			dump: `goroutine 17 [chan receive, locked to thread]:
+main.goCallback(...)
+	/app/main.go:23
+`,
+		},
+		{
+			name: "callback with runtime frames",
+			dump: `goroutine 8 [chan receive]:
+main.onPacket()
+	/app/main.go:40 +0x25
+runtime.cgocallbackg1(0x4a5b40, 0x7ffc9e5f8a40, 0x0)
+	/usr/local/go/src/runtime/cgocall.go:329 +0x2b1
+runtime.cgocallbackg(0x4a5b40, 0x7ffc9e5f8a40, 0x0)
+	/usr/local/go/src/runtime/cgocall.go:245 +0x11e
+main._Cfunc_pcap_dispatch(0x7f3a2c000b70, 0x1)
+	_cgo_gotypes.go:52 +0x3a
+created by main.main in goroutine 1
+	/app/main.go:27 +0x1e
+`,
+		},
+		{
+			name: "idle C thread",
+			dump: `goroutine 18 [syscall, locked to thread]:
+runtime.goexit({})
+	/usr/local/go/src/runtime/asm_amd64.s:1700 +0x1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, FindInDump([]byte(tt.dump)))
+			assert.Error(t, FindInDump([]byte(tt.dump), ReportCgoCallbacks()),
+				"ReportCgoCallbacks should report goroutines in C")
+		})
+	}
+}
+
+func TestIgnoreCgoCallbacksGo(t *testing.T) {
+	dumps := []string{
+		// Goroutines blocked in a system call from Go code.
+		`goroutine 6 [syscall]:
+syscall.Syscall(0x0, 0x3, 0xc000180000, 0x8000)
+	/usr/local/go/src/syscall/syscall_linux.go:73 +0x25
+os.(*File).Read(0xc000012078, {0xc000180000, 0x8000, 0x8000})
+	/usr/local/go/src/os/file.go:124 +0x52
+created by main.main in goroutine 1
+	/app/main.go:26 +0x1e
+`,
+		// The main goroutine, locked to its thread.
+		`goroutine 1 [chan receive, locked to thread]:
+main.main()
+	/app/main.go:20 +0x25
+`,
+		// Goroutines locked to their thread by Go code.
+		`goroutine 9 [chan receive, locked to thread]:
+main.worker()
+	/app/main.go:50 +0x25
+created by main.main in goroutine 1
+	/app/main.go:28 +0x1e
+`,
+	}
+	for _, dump := range dumps {
+		assert.Error(t, FindInDump([]byte(dump)), "goroutine should not be ignored: %v", dump)
+	}
+}
+
+func TestReportCgoCallbacksKeepsOtherDefaults(t *testing.T) {
+	opts := buildOpts(ReportCgoCallbacks())
+	require.Len(t, opts.defaultFilters, len(builtinFilters())-1)
+	for _, f := range opts.defaultFilters {
+		assert.NotEqual(t, _cgoCallbacksFilter, f.name)
+	}
+	assert.Len(t, buildOpts().defaultFilters, len(builtinFilters()), "defaults should not be modified")
+}
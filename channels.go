@@ -0,0 +1,177 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Runtime functions that goroutines blocked on a channel operation
+// are in, with the channel as their first argument.
+var _chanOps = map[string]string{
+	"runtime.chanrecv":  "receiving",
+	"runtime.chanrecv1": "receiving",
+	"runtime.chanrecv2": "receiving",
+	"runtime.chansend":  "sending",
+	"runtime.chansend1": "sending",
+}
+
+// chanGroup is the set of leaked goroutines blocked on the same channel.
+type chanGroup struct {
+	addr      uint64
+	receivers []int
+	senders   []int
+}
+
+// firstID returns the lowest ID of the goroutines of the group.
+func (g *chanGroup) firstID() int {
+	ids := append(slices.Clone(g.receivers), g.senders...)
+	return slices.Min(ids)
+}
+
+// channelHints groups leaked goroutines that are blocked on the same
+// channel, so producers and consumers are reported together, and flags
+// channels that no other leaked goroutine could unblock.
+//
+// The channel is only known if the dump has the runtime frames of the
+// blocked goroutines, e.g. in tracebacks of crashes and of
+// GOTRACEBACK=system. Stacks of running programs don't, so goroutines
+// blocked on non-nil channels are only grouped when checking dumps.
+func channelHints(stacks []stack.Stack) string {
+	var (
+		sb     strings.Builder
+		groups []*chanGroup
+		byAddr = make(map[uint64]*chanGroup)
+	)
+	header := func() {
+		if sb.Len() == 0 {
+			sb.WriteString("\nchannel hints:\n")
+		}
+	}
+	for _, s := range stacks {
+		reason := s.WaitReason()
+		if !strings.HasPrefix(reason, "chan ") {
+			continue
+		}
+		if strings.HasSuffix(reason, "(nil chan)") {
+			header()
+			fmt.Fprintf(&sb, "goroutine %d is blocked on a nil channel, which blocks forever.\n", s.ID())
+			continue
+		}
+
+		addr, op, ok := chanOpOf(s)
+		if !ok {
+			continue
+		}
+		g, ok := byAddr[addr]
+		if !ok {
+			g = &chanGroup{addr: addr}
+			byAddr[addr] = g
+			groups = append(groups, g)
+		}
+		if op == "receiving" {
+			g.receivers = append(g.receivers, s.ID())
+		} else {
+			g.senders = append(g.senders, s.ID())
+		}
+	}
+
+	// Leaks are sorted by fingerprint; list goroutines by ID instead.
+	for _, g := range groups {
+		slices.Sort(g.receivers)
+		slices.Sort(g.senders)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].firstID() < groups[j].firstID()
+	})
+	for _, g := range groups {
+		header()
+		fmt.Fprintf(&sb, "channel %#x: ", g.addr)
+		if len(g.receivers) > 0 && len(g.senders) > 0 {
+			fmt.Fprintf(&sb, "%v blocked receiving, %v blocked sending.\n",
+				goroutineList(g.receivers), goroutineList(g.senders))
+			continue
+		}
+
+		blocked, op, counterpart := g.receivers, "receiving", "send on or close it"
+		if len(g.senders) > 0 {
+			blocked, op, counterpart = g.senders, "sending", "receive from it"
+		}
+		var refs []int
+		ptr := map[uint64]struct{}{g.addr: {}}
+		for _, s := range stacks {
+			if !slices.Contains(blocked, s.ID()) && referencesAny(s, ptr) {
+				refs = append(refs, s.ID())
+			}
+		}
+		if len(refs) == 0 {
+			fmt.Fprintf(&sb, "%v blocked %v and no other leaked goroutine references it, so nothing may %v.\n",
+				goroutineList(blocked), op, counterpart)
+			continue
+		}
+		fmt.Fprintf(&sb, "%v blocked %v; %v references it and may be expected to %v.\n",
+			goroutineList(blocked), op, goroutineList(refs), counterpart)
+	}
+	return sb.String()
+}
+
+// chanOpOf returns the address of the channel that s is blocked on
+// and whether it's sending or receiving, if the dump has the frame
+// of the runtime function that blocked.
+func chanOpOf(s stack.Stack) (addr uint64, op string, ok bool) {
+	if !strings.Contains(s.Full(), "runtime.chan") {
+		// Avoid parsing the frames of stacks that can't match.
+		return 0, "", false
+	}
+	for _, entry := range s.Entries() {
+		if entry.IsSource {
+			break
+		}
+		op, ok := _chanOps[entry.Function()]
+		if !ok {
+			continue
+		}
+		// The channel is the first argument.
+		args := entry.Args()
+		if len(args) == 0 || args[0].Value == 0 {
+			return 0, "", false
+		}
+		return args[0].Value, op, true
+	}
+	return 0, "", false
+}
+
+// goroutineList formats goroutine IDs, e.g. "goroutines 7, 8".
+func goroutineList(ids []int) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = fmt.Sprint(id)
+	}
+	if len(ids) == 1 {
+		return "goroutine " + strs[0]
+	}
+	return "goroutines " + strings.Join(strs, ", ")
+}
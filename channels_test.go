@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelHints(t *testing.T) {
+	receiver := func(id, ch string) []string {
+		return []string{
+			"goroutine " + id + " [chan receive]:",
+			"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+			"	/usr/local/go/src/runtime/proc.go:435 +0xce",
+			"runtime.chanrecv(" + ch + ", 0x0, 0x1)",
+			"	/usr/local/go/src/runtime/chan.go:664 +0x445",
+			"runtime.chanrecv1(" + ch + "?, 0x0?)",
+			"	/usr/local/go/src/runtime/chan.go:506 +0x12",
+			"main.consume(...)",
+			"	/app/main.go:20",
+			"created by main.main in goroutine 1",
+			"	/app/main.go:10 +0x1d",
+			"",
+		}
+	}
+	sender := func(id, ch string) []string {
+		return []string{
+			"goroutine " + id + " [chan send]:",
+			"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+			"	/usr/local/go/src/runtime/proc.go:435 +0xce",
+			"runtime.chansend(" + ch + ", 0xc000051f38, 0x1, 0x4a3f12)",
+			"	/usr/local/go/src/runtime/chan.go:283 +0x3d0",
+			"runtime.chansend1(" + ch + "?, 0x0?)",
+			"	/usr/local/go/src/runtime/chan.go:161 +0x12",
+			"main.produce(...)",
+			"	/app/main.go:30",
+			"created by main.main in goroutine 1",
+			"	/app/main.go:11 +0x1d",
+			"",
+		}
+	}
+	owner := []string{
+		"goroutine 20 [select]:",
+		"main.serve(0xc000020060, 0xc000100000)",
+		"	/app/main.go:40 +0x2b",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:12 +0x1d",
+		"",
+	}
+	nilRecv := []string{
+		"goroutine 30 [chan receive (nil chan)]:",
+		"main.wait()",
+		"	/app/main.go:50 +0x1b",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:13 +0x1d",
+		"",
+	}
+	live := []string{
+		"goroutine 40 [chan receive]:",
+		"main.consume()",
+		"	/app/main.go:20 +0x19",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:10 +0x1d",
+		"",
+	}
+	dump := func(goroutines ...[]string) []byte {
+		var lines []string
+		for _, g := range goroutines {
+			lines = append(lines, g...)
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+	hints := func(t *testing.T, goroutines ...[]string) string {
+		err := FindInDump(dump(goroutines...))
+		require.Error(t, err)
+		_, hints, _ := strings.Cut(err.Error(), "\nchannel hints:\n")
+		return hints
+	}
+
+	t.Run("no counterpart", func(t *testing.T) {
+		assert.Equal(t,
+			"channel 0xc000020060: goroutines 7, 8 blocked receiving and no other leaked goroutine references it, so nothing may send on or close it.\n"+
+				"channel 0xc000030000: goroutine 9 blocked sending and no other leaked goroutine references it, so nothing may receive from it.\n",
+			hints(t, receiver("7", "0xc000020060"), receiver("8", "0xc000020060"), sender("9", "0xc000030000")))
+	})
+
+	t.Run("referenced", func(t *testing.T) {
+		assert.Equal(t,
+			"channel 0xc000020060: goroutine 7 blocked receiving; goroutine 20 references it and may be expected to send on or close it.\n",
+			hints(t, receiver("7", "0xc000020060"), owner))
+	})
+
+	t.Run("both directions", func(t *testing.T) {
+		assert.Equal(t,
+			"channel 0xc000020060: goroutine 7 blocked receiving, goroutine 9 blocked sending.\n",
+			hints(t, receiver("7", "0xc000020060"), sender("9", "0xc000020060")))
+	})
+
+	t.Run("nil channel", func(t *testing.T) {
+		assert.Equal(t,
+			"goroutine 30 is blocked on a nil channel, which blocks forever.\n",
+			hints(t, nilRecv))
+	})
+
+	t.Run("no runtime frames", func(t *testing.T) {
+		assert.Empty(t, hints(t, live))
+	})
+
+	t.Run("elided arguments", func(t *testing.T) {
+		assert.Empty(t, hints(t, receiver("7", "...")))
+	})
+}
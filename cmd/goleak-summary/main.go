@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Command goleak-summary renders the leak summary that
+// goleak.VerifyTestMain writes to the file named by the GOLEAK_SUMMARY
+// environment variable, for a repository-wide leak report of a test run:
+//
+//	rm -f goleak-summary.jsonl
+//	GOLEAK_SUMMARY=$PWD/goleak-summary.jsonl go test ./...
+//	goleak-summary -html goleak-summary.html -json goleak-summary.json goleak-summary.jsonl
+//
+// It prints the packages that leaked goroutines, and exits with status 1
+// if there are any and the -fail flag is given.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// errLeaked is returned when packages leaked and -fail was given.
+var errLeaked = errors.New("packages leaked goroutines")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, errLeaked) {
+			fmt.Fprintln(os.Stderr, "goleak-summary:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("goleak-summary", flag.ContinueOnError)
+	htmlPath := flags.String("html", "", "write the summary as HTML to `file`")
+	jsonPath := flags.String("json", "", "write the summary as JSON to `file`")
+	fail := flags.Bool("fail", false, "exit with status 1 if any package leaked")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: goleak-summary [flags] [summary file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	path := os.Getenv("GOLEAK_SUMMARY")
+	switch flags.NArg() {
+	case 0:
+		if path == "" {
+			return errors.New("no summary file given, and GOLEAK_SUMMARY is not set")
+		}
+	case 1:
+		path = flags.Arg(0)
+	default:
+		flags.Usage()
+		return errors.New("too many arguments")
+	}
+
+	summary, err := goleak.ReadSummary(path)
+	if err != nil {
+		return err
+	}
+	if *htmlPath != "" {
+		if err := writeFile(*htmlPath, summary.WriteHTML); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		err := writeFile(*jsonPath, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summary)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	leaked := summary.Leaked()
+	for _, pkg := range leaked {
+		fmt.Fprintf(stdout, "%v: %d leaked goroutines\n", pkg.Package, len(pkg.Leaks))
+	}
+	fmt.Fprintf(stdout, "%d of %d packages leaked goroutines\n", len(leaked), len(summary.Packages))
+	if *fail && len(leaked) > 0 {
+		return errLeaked
+	}
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write %v: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSummary(t *testing.T, pkgs ...goleak.PackageSummary) string {
+	var sb strings.Builder
+	for _, pkg := range pkgs {
+		b, err := json.Marshal(pkg)
+		require.NoError(t, err)
+		sb.Write(append(b, '\n'))
+	}
+	path := filepath.Join(t.TempDir(), "summary.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	path := writeSummary(t,
+		goleak.PackageSummary{Package: "example.com/clean", Checked: true},
+		goleak.PackageSummary{Package: "example.com/leaky", Checked: true, Leaks: []goleak.LeakedGoroutine{
+			{ID: 7, State: "chan receive", FirstFunction: "example.com/leaky.worker", Stack: "example.com/leaky.worker()\n"},
+		}},
+	)
+	dir := t.TempDir()
+	htmlPath, jsonPath := filepath.Join(dir, "summary.html"), filepath.Join(dir, "summary.json")
+
+	var stdout strings.Builder
+	require.NoError(t, run([]string{"-html", htmlPath, "-json", jsonPath, path}, &stdout))
+	assert.Equal(t, "example.com/leaky: 1 leaked goroutines\n1 of 2 packages leaked goroutines\n", stdout.String())
+
+	html, err := os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "example.com/leaky.worker")
+
+	b, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var summary goleak.Summary
+	require.NoError(t, json.Unmarshal(b, &summary))
+	assert.Len(t, summary.Packages, 2)
+
+	t.Run("fail", func(t *testing.T) {
+		assert.ErrorIs(t, run([]string{"-fail", path}, &strings.Builder{}), errLeaked)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("GOLEAK_SUMMARY", path)
+		var stdout strings.Builder
+		require.NoError(t, run(nil, &stdout))
+		assert.Contains(t, stdout.String(), "1 of 2 packages")
+
+		t.Setenv("GOLEAK_SUMMARY", "")
+		assert.ErrorContains(t, run(nil, &strings.Builder{}), "GOLEAK_SUMMARY is not set")
+	})
+}
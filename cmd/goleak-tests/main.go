@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Command goleak-tests lists the tests that leaked goroutines in the
+// output of "go test -json", leakiest first:
+//
+//	go test -json ./... | goleak-tests
+//
+// The output may also be read from files given as arguments.
+// With -summary, the leaks found by goleak.VerifyTestMain are taken
+// from the file that the GOLEAK_SUMMARY environment variable named
+// during the test run. With -json, the results are written as JSON
+// instead of a table.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/testjson"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "goleak-tests:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("goleak-tests", flag.ContinueOnError)
+	summaryPath := flags.String("summary", "", "merge the VerifyTestMain leaks of the GOLEAK_SUMMARY `file`")
+	asJSON := flags.Bool("json", false, "write the results as JSON")
+	top := flags.Int("top", 0, "only list the `n` leakiest tests")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: go test -json ./... | goleak-tests [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	inputs := []io.Reader{stdin}
+	if flags.NArg() > 0 {
+		inputs = inputs[:0]
+		for _, path := range flags.Args() {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			inputs = append(inputs, f)
+		}
+	}
+	results, err := testjson.Analyze(io.MultiReader(inputs...))
+	if err != nil {
+		return err
+	}
+
+	if *summaryPath != "" {
+		summary, err := goleak.ReadSummary(*summaryPath)
+		if err != nil {
+			return err
+		}
+		results = testjson.AddSummary(results, summary)
+	}
+	if *top > 0 && len(results) > *top {
+		results = results[:*top]
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(stdout, "no tests leaked goroutines")
+		return err
+	}
+	return testjson.WriteTable(stdout, results)
+}
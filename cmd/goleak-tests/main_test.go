@@ -0,0 +1,73 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _events = `{"Action":"run","Package":"example.com/a","Test":"TestA"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"    a_test.go:9: found unexpected goroutines:\n"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"        [Goroutine 7 in state chan receive, with example.com/a.worker on top of the stack:\n"}
+{"Action":"fail","Package":"example.com/a","Test":"TestA","Elapsed":0.25}
+{"Action":"output","Package":"example.com/b","Test":"TestB","Output":"    b_test.go:9: found unexpected goroutines:\n"}
+{"Action":"fail","Package":"example.com/b","Test":"TestB","Elapsed":0.5}
+`
+
+func TestRun(t *testing.T) {
+	var stdout strings.Builder
+	require.NoError(t, run(nil, strings.NewReader(_events), &stdout))
+	assert.Contains(t, stdout.String(), "example.com/a  TestA  1           1        250ms    example.com/a.worker (1)")
+	assert.Contains(t, stdout.String(), "example.com/b  TestB  0")
+
+	t.Run("top", func(t *testing.T) {
+		var stdout strings.Builder
+		require.NoError(t, run([]string{"-top", "1", "-json"}, strings.NewReader(_events), &stdout))
+		assert.Contains(t, stdout.String(), `"test": "TestA"`)
+		assert.NotContains(t, stdout.String(), "TestB")
+	})
+
+	t.Run("files and summary", func(t *testing.T) {
+		dir := t.TempDir()
+		events, summary := filepath.Join(dir, "test.json"), filepath.Join(dir, "summary.jsonl")
+		require.NoError(t, os.WriteFile(events, []byte(_events), 0o644))
+		require.NoError(t, os.WriteFile(summary, []byte(
+			`{"package":"example.com/c","checked":true,"leaks":[{"first_function":"example.com/c.run"},{"first_function":"example.com/c.run"}]}`+"\n"), 0o644))
+
+		var stdout strings.Builder
+		require.NoError(t, run([]string{"-summary", summary, events}, strings.NewReader(""), &stdout))
+		lines := strings.Split(stdout.String(), "\n")
+		require.Greater(t, len(lines), 1)
+		assert.Contains(t, lines[1], "example.com/c  TestMain", "the leakiest test should come first")
+	})
+
+	t.Run("no leaks", func(t *testing.T) {
+		var stdout strings.Builder
+		require.NoError(t, run(nil, strings.NewReader(""), &stdout))
+		assert.Equal(t, "no tests leaked goroutines\n", stdout.String())
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Command goleak tracks the goroutine leaks of test runs across CI runs,
+// to ratchet them down over time without failing on existing leaks.
+//
+// Usage:
+//
+//	goleak budget [-o file] summary
+//	goleak compare old new
+//
+// The budget command counts the leaks in the summary that
+// goleak.VerifyTestMain wrote to the file named by the GOLEAK_SUMMARY
+// environment variable, per package and fingerprint, and writes them
+// as JSON to the file given with -o, or to stdout.
+//
+// The compare command compares two budgets, e.g. of the main branch
+// and of a pull request, prints how the leaks changed, and exits with
+// status 1 if the new budget has new fingerprints or more goroutines
+// of a fingerprint than the old one. Fixed leaks don't fail it.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// errRegressed is returned when compare finds new leaks.
+var errRegressed = errors.New("leaks regressed")
+
+const _usage = `usage:
+	goleak budget [-o file] summary
+	goleak compare old new`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, errRegressed) {
+			fmt.Fprintln(os.Stderr, "goleak:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(_usage)
+	}
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "budget":
+		return budget(args, stdout)
+	case "compare":
+		return compare(args, stdout)
+	default:
+		return fmt.Errorf("unknown command %q\n%v", cmd, _usage)
+	}
+}
+
+func budget(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("budget", flag.ContinueOnError)
+	out := flags.String("o", "", "write the budget to `file` instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: goleak budget [-o file] summary")
+	}
+
+	summary, err := goleak.ReadSummary(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(summary.Budget(), "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if *out == "" {
+		_, err := stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(*out, b, 0o644)
+}
+
+func compare(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("usage: goleak compare old new")
+	}
+	older, err := goleak.LoadBudget(args[0])
+	if err != nil {
+		return err
+	}
+	newer, err := goleak.LoadBudget(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := goleak.CompareBudgets(older, newer)
+	fmt.Fprint(stdout, diff)
+	if diff.Regressed() {
+		fmt.Fprintln(stdout, "\ngoleak: new leaks were found; fix them, or accept them by updating the old budget")
+		return errRegressed
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestBudgetAndCompare(t *testing.T) {
+	dir := t.TempDir()
+	oldSummary := writeFile(t, dir, "old.jsonl",
+		`{"package":"example.com/a","checked":true,"leaks":[{"fingerprint":"f1","first_function":"example.com/a.worker","state":"select"}]}`+"\n")
+	newSummary := writeFile(t, dir, "new.jsonl",
+		`{"package":"example.com/a","checked":true,"leaks":[{"fingerprint":"f1","first_function":"example.com/a.worker","state":"select"},`+
+			`{"fingerprint":"f2","first_function":"example.com/a.reaper","state":"sleep"}]}`+"\n")
+
+	oldBudget, newBudget := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+	require.NoError(t, run([]string{"budget", "-o", oldBudget, oldSummary}, &strings.Builder{}))
+	require.NoError(t, run([]string{"budget", "-o", newBudget, newSummary}, &strings.Builder{}))
+
+	var stdout strings.Builder
+	require.NoError(t, run([]string{"budget", oldSummary}, &stdout))
+	assert.Contains(t, stdout.String(), `"f1": 1`)
+
+	t.Run("regressed", func(t *testing.T) {
+		var stdout strings.Builder
+		err := run([]string{"compare", oldBudget, newBudget}, &stdout)
+		assert.ErrorIs(t, err, errRegressed)
+		assert.Contains(t, stdout.String(), "+ example.com/a.reaper: 0 -> 1 goroutines (sleep: 1) [f2]")
+		assert.Contains(t, stdout.String(), "new leaks were found")
+	})
+
+	t.Run("fixed", func(t *testing.T) {
+		var stdout strings.Builder
+		require.NoError(t, run([]string{"compare", newBudget, oldBudget}, &stdout))
+		assert.Contains(t, stdout.String(), "- example.com/a.reaper: 1 -> 0 goroutines (sleep: 1) [f2]")
+	})
+}
+
+func TestUsage(t *testing.T) {
+	assert.ErrorContains(t, run(nil, &strings.Builder{}), "usage:")
+	assert.ErrorContains(t, run([]string{"frobnicate"}, &strings.Builder{}), `unknown command "frobnicate"`)
+	assert.ErrorContains(t, run([]string{"compare", "old.json"}, &strings.Builder{}), "usage: goleak compare")
+	assert.ErrorContains(t, run([]string{"budget"}, &strings.Builder{}), "usage: goleak budget")
+}
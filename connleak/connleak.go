@@ -0,0 +1,255 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package connleak detects network connections and listeners
+// that are never closed.
+//
+// Connections are tracked when they are opened through this package:
+// dial with a [Dialer] instead of a net.Dialer, listen with [Listen] or
+// wrap existing listeners and connections with [WrapListener] and [WrapConn].
+// HTTP clients can be tracked by using the Dialer in their transport:
+//
+//	transport := &http.Transport{DialContext: (&connleak.Dialer{}).DialContext}
+//
+// At the end of a test, check that everything was closed:
+//
+//	defer connleak.VerifyNone(t)
+//
+// Leaks are reported with the stack of the goroutine
+// that opened the connection or listener.
+//
+// Tracked connections are wrapped, so they can't be type-asserted
+// to concrete types like *net.TCPConn. Use NetConn to get the
+// underlying connection.
+package connleak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// _maxDepth is the number of frames recorded for the site
+// that opened a connection.
+const _maxDepth = 32
+
+// _tracked holds the connections and listeners that are not closed yet.
+var _tracked = tracker{open: make(map[*record]struct{})}
+
+type tracker struct {
+	mu     sync.Mutex
+	nextID int
+	open   map[*record]struct{}
+}
+
+// record describes a tracked connection or listener.
+type record struct {
+	id   int
+	kind string // "connection" or "listener"
+	addr string
+	pcs  []uintptr // stack of the site that opened it
+}
+
+// add tracks a connection or listener opened by the caller of the
+// function skip frames above add.
+func (tr *tracker) add(skip int, kind, addr string) *record {
+	pcs := make([]uintptr, _maxDepth)
+	// Skip runtime.Callers and add too.
+	n := runtime.Callers(skip+2, pcs)
+	r := &record{kind: kind, addr: addr, pcs: pcs[:n]}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.nextID++
+	r.id = tr.nextID
+	tr.open[r] = struct{}{}
+	return r
+}
+
+func (tr *tracker) remove(r *record) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	delete(tr.open, r)
+}
+
+// snapshot returns the open connections and listeners, oldest first.
+func (tr *tracker) snapshot() []*record {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	rs := make([]*record, 0, len(tr.open))
+	for r := range tr.open {
+		rs = append(rs, r)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].id < rs[j].id })
+	return rs
+}
+
+// stack formats the frames of the site that opened r,
+// in the format of goroutine stack traces.
+func (r *record) stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(r.pcs)
+	for {
+		frame, more := frames.Next()
+		// Frames of the runtime and testing only add noise.
+		if !strings.HasPrefix(frame.Function, "runtime.") &&
+			!strings.HasPrefix(frame.Function, "testing.") {
+			fmt.Fprintf(&sb, "%v(...)\n\t%v:%v\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Dialer is a [net.Dialer] that tracks the connections it opens.
+type Dialer struct {
+	net.Dialer
+}
+
+// Dial connects like [net.Dialer.Dial] and tracks the connection.
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	return d.dial(context.Background(), network, address)
+}
+
+// DialContext connects like [net.Dialer.DialContext]
+// and tracks the connection.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.dial(ctx, network, address)
+}
+
+// dial implements Dial and DialContext,
+// which must call it directly to get the right stack.
+func (d *Dialer) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	c, err := d.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, r: _tracked.add(2, "connection", network+" "+address)}, nil
+}
+
+// WrapConn tracks an open connection until it is closed,
+// for connections opened without a Dialer.
+func WrapConn(c net.Conn) net.Conn {
+	return &conn{Conn: c, r: _tracked.add(1, "connection", remoteAddr(c))}
+}
+
+type conn struct {
+	net.Conn
+
+	r    *record
+	once sync.Once
+}
+
+func (c *conn) Close() error {
+	c.once.Do(func() { _tracked.remove(c.r) })
+	return c.Conn.Close()
+}
+
+// NetConn returns the underlying connection.
+func (c *conn) NetConn() net.Conn {
+	return c.Conn
+}
+
+func remoteAddr(c net.Conn) string {
+	if addr := c.RemoteAddr(); addr != nil {
+		return addr.Network() + " " + addr.String()
+	}
+	return "unknown address"
+}
+
+// Listen listens like [net.Listen] and tracks the listener
+// and the connections it accepts.
+func Listen(network, address string) (net.Listener, error) {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &listener{Listener: l, r: _tracked.add(1, "listener", network+" "+l.Addr().String())}, nil
+}
+
+// WrapListener tracks an open listener and the connections it accepts
+// until they are closed.
+func WrapListener(l net.Listener) net.Listener {
+	addr := l.Addr()
+	return &listener{Listener: l, r: _tracked.add(1, "listener", addr.Network()+" "+addr.String())}
+}
+
+type listener struct {
+	net.Listener
+
+	r    *record
+	once sync.Once
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, r: _tracked.add(1, "connection", remoteAddr(c))}, nil
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() { _tracked.remove(l.r) })
+	return l.Listener.Close()
+}
+
+// Find returns a descriptive error if any connections or listeners
+// tracked by this package are still open.
+func Find() error {
+	rs := _tracked.snapshot()
+	if len(rs) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("found open connections:\n")
+	for _, r := range rs {
+		fmt.Fprintf(&sb, "%v (%v) opened at:\n%v\n", r.kind, r.addr, r.stack())
+	}
+	return errors.New(sb.String())
+}
+
+type testHelper interface {
+	Helper()
+}
+
+// VerifyNone marks the given TestingT as failed if any connections
+// or listeners tracked by this package are still open.
+//
+//	defer connleak.VerifyNone(t)
+func VerifyNone(t goleak.TestingT) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	if err := Find(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package connleak
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (ft *fakeT) Error(args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprint(args...))
+}
+
+func TestListenAndDial(t *testing.T) {
+	ln, err := Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	err = Find()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "listener (tcp "+ln.Addr().String()+") opened at:\n")
+	assert.Contains(t, err.Error(), "connleak.TestListenAndDial(...)\n\t")
+	assert.NotContains(t, err.Error(), "connleak.Listen(", "frames of connleak should be skipped")
+
+	accepted := make(chan net.Conn)
+	go func() {
+		c, err := ln.Accept()
+		assert.NoError(t, err)
+		accepted <- c
+	}()
+
+	var d Dialer
+	c, err := d.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	server := <-accepted
+	require.NoError(t, ln.Close())
+
+	err = Find()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection (tcp "+ln.Addr().String()+") opened at:\n")
+	assert.NotContains(t, err.Error(), "listener")
+
+	require.NoError(t, c.Close())
+	require.NoError(t, server.Close())
+	assert.NoError(t, Find())
+
+	// Closing again doesn't untrack anything else. The simulated
+	// network of js and wasip1 doesn't fail closing twice.
+	err = c.Close()
+	if runtime.GOOS != "js" && runtime.GOOS != "wasip1" {
+		assert.Error(t, err)
+	}
+	assert.NoError(t, Find())
+}
+
+func TestWrap(t *testing.T) {
+	client, server := net.Pipe()
+	wrapped := WrapConn(client)
+	assert.ErrorContains(t, Find(), "connection (pipe pipe) opened at:")
+	assert.Same(t, client, wrapped.(interface{ NetConn() net.Conn }).NetConn())
+	require.NoError(t, wrapped.Close())
+	require.NoError(t, server.Close())
+	assert.NoError(t, Find())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	wrappedLn := WrapListener(ln)
+	assert.ErrorContains(t, Find(), "listener (tcp "+ln.Addr().String()+")")
+	require.NoError(t, wrappedLn.Close())
+	assert.NoError(t, Find())
+}
+
+func TestHTTPTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	transport := &http.Transport{DialContext: (&Dialer{}).DialContext}
+	client := &http.Client{Transport: transport}
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	_, err = io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	// The idle connection is kept open until the transport lets go of it.
+	assert.ErrorContains(t, Find(), "connection (tcp "+srv.Listener.Addr().String()+")")
+	transport.CloseIdleConnections()
+	assert.NoError(t, Find())
+}
+
+func TestVerifyNone(t *testing.T) {
+	ft := &fakeT{}
+	VerifyNone(ft)
+	assert.Empty(t, ft.errors)
+
+	ln, err := Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	VerifyNone(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "found open connections")
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// LeakCounter counts unexpected goroutines cheaply enough to be called
+// in hot loops, e.g. on every request of a stress test.
+//
+// It takes a full goroutine dump only when the number of goroutines
+// reported by runtime.NumGoroutine differs from the previous count,
+// and otherwise returns the previous result. As a consequence, a leak may
+// go unnoticed until the number of goroutines changes if goroutines exit
+// and start at the same rate. Counts are taken without retries.
+//
+// A LeakCounter is safe for concurrent use.
+type LeakCounter struct {
+	opts *opts
+
+	mu           sync.Mutex
+	numGoroutine int // goroutines at the last dump, or 0 before the first
+	leaks        int
+}
+
+// NewLeakCounter returns a LeakCounter that ignores goroutines like
+// [Find] would with the given options.
+func NewLeakCounter(options ...Option) *LeakCounter {
+	return &LeakCounter{opts: buildOpts(options...)}
+}
+
+// Count returns the number of unexpected goroutines,
+// not counting the calling goroutine.
+func (c *LeakCounter) Count() int {
+	return c.count(func() *opts { return c.opts })
+}
+
+// count implements Count, building options only when a dump is needed
+// so that the fast path stays cheap.
+func (c *LeakCounter) count(buildOpts func() *opts) int {
+	n := runtime.NumGoroutine()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n == c.numGoroutine {
+		return c.leaks
+	}
+
+	opts := buildOpts()
+	stacks := filterStacks(opts.stacks(), stack.Current().ID(), opts)
+	c.numGoroutine, c.leaks = n, len(stacks)
+	return c.leaks
+}
+
+// The LeakCounter behind CurrentLeakCount.
+var _leakCounter LeakCounter
+
+// CurrentLeakCount returns the number of unexpected goroutines,
+// like a [LeakCounter] that is shared by all calls to CurrentLeakCount.
+//
+// The options are only used when a full goroutine dump is needed,
+// so all calls should pass the same options;
+// use separate LeakCounters to count with different options.
+func CurrentLeakCount(options ...Option) int {
+	return _leakCounter.count(func() *opts { return buildOpts(options...) })
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeakCounter(t *testing.T) {
+	getStableAll(t, stack.Current())
+	c := NewLeakCounter()
+	assert.Zero(t, c.Count())
+
+	bg := startBlockedG()
+	assert.Equal(t, 1, c.Count())
+	assert.Equal(t, 1, c.Count(), "cached count should be returned")
+
+	bg.unblock()
+	// Count in this goroutine; assert.Eventually would add its own.
+	deadline := time.Now().Add(time.Second)
+	for c.Count() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Zero(t, c.Count())
+}
+
+func TestCurrentLeakCount(t *testing.T) {
+	getStableAll(t, stack.Current())
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	assert.Equal(t, 1, CurrentLeakCount())
+	assert.Equal(t, 1, CurrentLeakCount())
+}
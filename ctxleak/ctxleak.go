@@ -0,0 +1,206 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package ctxleak detects contexts whose cancel functions are never called.
+//
+// A context created with context.WithCancel, WithTimeout or WithDeadline
+// holds on to resources, and often to goroutines waiting on it, until
+// it's canceled. Create contexts with the functions of this package
+// instead of those of the context package to have them tracked:
+//
+//	ctx, cancel := ctxleak.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//
+// and check that all of them were canceled at the end of a test:
+//
+//	defer ctxleak.VerifyNone(t)
+//
+// Contexts count as canceled once they're done, e.g. because their
+// deadline passed or their parent was canceled, since their resources
+// are released then. Leaks are reported with the stack of the goroutine
+// that created the context.
+package ctxleak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// _maxDepth is the number of frames recorded for the site
+// that created a context.
+const _maxDepth = 32
+
+// _tracked holds the contexts that are not canceled yet.
+var _tracked = tracker{active: make(map[*record]struct{})}
+
+type tracker struct {
+	mu     sync.Mutex
+	nextID int
+	active map[*record]struct{}
+}
+
+// record describes a tracked context.
+type record struct {
+	id   int
+	kind string // name of the constructor, e.g. "WithTimeout"
+	ctx  context.Context
+	pcs  []uintptr // stack of the site that created it
+}
+
+// add tracks a context created by the caller of the function
+// skip frames above add, and returns a function that stops tracking it.
+func (tr *tracker) add(skip int, kind string, ctx context.Context) func() {
+	pcs := make([]uintptr, _maxDepth)
+	// Skip runtime.Callers and add too.
+	n := runtime.Callers(skip+2, pcs)
+	r := &record{kind: kind, ctx: ctx, pcs: pcs[:n]}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.nextID++
+	r.id = tr.nextID
+	tr.active[r] = struct{}{}
+	return func() { tr.remove(r) }
+}
+
+func (tr *tracker) remove(r *record) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	delete(tr.active, r)
+}
+
+// pending returns the contexts that are neither canceled
+// nor done, oldest first.
+func (tr *tracker) pending() []*record {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	var rs []*record
+	for r := range tr.active {
+		if r.ctx.Err() != nil {
+			// Done contexts have released their resources.
+			delete(tr.active, r)
+			continue
+		}
+		rs = append(rs, r)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].id < rs[j].id })
+	return rs
+}
+
+// stack formats the frames of the site that created r,
+// in the format of goroutine stack traces.
+func (r *record) stack() string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(r.pcs)
+	for {
+		frame, more := frames.Next()
+		// Frames of the runtime and testing only add noise.
+		if !strings.HasPrefix(frame.Function, "runtime.") &&
+			!strings.HasPrefix(frame.Function, "testing.") {
+			fmt.Fprintf(&sb, "%v(...)\n\t%v:%v\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// WithCancel is like [context.WithCancel], but tracks the context
+// until cancel is called.
+func WithCancel(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	return ctx, untrack(cancel, _tracked.add(1, "WithCancel", ctx))
+}
+
+// WithCancelCause is like [context.WithCancelCause], but tracks the
+// context until cancel is called.
+func WithCancelCause(parent context.Context) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	done := _tracked.add(1, "WithCancelCause", ctx)
+	return ctx, func(cause error) {
+		done()
+		cancel(cause)
+	}
+}
+
+// WithTimeout is like [context.WithTimeout], but tracks the context
+// until cancel is called or the timeout elapses.
+func WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	return ctx, untrack(cancel, _tracked.add(1, fmt.Sprintf("WithTimeout(%v)", timeout), ctx))
+}
+
+// WithDeadline is like [context.WithDeadline], but tracks the context
+// until cancel is called or the deadline passes.
+func WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(parent, d)
+	return ctx, untrack(cancel, _tracked.add(1, "WithDeadline", ctx))
+}
+
+// untrack returns a cancel function that also stops tracking the context.
+func untrack(cancel context.CancelFunc, done func()) context.CancelFunc {
+	return func() {
+		done()
+		cancel()
+	}
+}
+
+// Find returns a descriptive error if any contexts created by this
+// package are neither canceled nor done.
+func Find() error {
+	rs := _tracked.pending()
+	if len(rs) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("found contexts that were never canceled:\n")
+	for _, r := range rs {
+		fmt.Fprintf(&sb, "%v context created at:\n%v\n", r.kind, r.stack())
+	}
+	return errors.New(sb.String())
+}
+
+type testHelper interface {
+	Helper()
+}
+
+// VerifyNone marks the given TestingT as failed if any contexts
+// created by this package are neither canceled nor done.
+//
+//	defer ctxleak.VerifyNone(t)
+func VerifyNone(t goleak.TestingT) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	if err := Find(); err != nil {
+		t.Error(err)
+	}
+}
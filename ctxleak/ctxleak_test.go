@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ctxleak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (ft *fakeT) Error(args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprint(args...))
+}
+
+func TestWithCancel(t *testing.T) {
+	_, cancel := WithCancel(context.Background())
+	err := Find()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WithCancel context created at:\n"+
+		"github.com/projectdiscovery/goleak/ctxleak.TestWithCancel(...)\n")
+	assert.Contains(t, err.Error(), "ctxleak_test.go:")
+
+	cancel()
+	assert.NoError(t, Find())
+	cancel() // canceling again is a no-op
+	assert.NoError(t, Find())
+}
+
+func TestWithCancelCause(t *testing.T) {
+	ctx, cancel := WithCancelCause(context.Background())
+	assert.ErrorContains(t, Find(), "WithCancelCause context")
+
+	cause := errors.New("great sadness")
+	cancel(cause)
+	assert.NoError(t, Find())
+	assert.Equal(t, cause, context.Cause(ctx))
+}
+
+func TestWithTimeout(t *testing.T) {
+	_, cancel := WithTimeout(context.Background(), time.Hour)
+	assert.ErrorContains(t, Find(), "WithTimeout(1h0m0s) context")
+	cancel()
+	assert.NoError(t, Find())
+
+	// Contexts don't need to be canceled once they're done.
+	ctx, _ := WithTimeout(context.Background(), time.Millisecond)
+	<-ctx.Done()
+	assert.NoError(t, Find())
+}
+
+func TestWithDeadline(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	_, cancel := WithDeadline(parent, time.Now().Add(time.Hour))
+	defer cancel()
+	assert.ErrorContains(t, Find(), "WithDeadline context")
+
+	// Canceling the parent cancels the child.
+	cancelParent()
+	assert.NoError(t, Find())
+}
+
+func TestVerifyNone(t *testing.T) {
+	ft := &fakeT{}
+	VerifyNone(ft)
+	assert.Empty(t, ft.errors)
+
+	_, cancel := WithCancel(context.Background())
+	defer cancel()
+	VerifyNone(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "found contexts that were never canceled")
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import "time"
+
+// Leak checks stop retrying this long before the deadline of the test,
+// which leaves time to report leaks and run the rest of the test
+// before it times out.
+const _deadlineMargin = time.Second
+
+// testDeadline is implemented by testing.T.
+type testDeadline interface {
+	Deadline() (time.Time, bool)
+}
+
+// capRetries stops retries before the deadline of t, if it has one,
+// so that sleeping between retries never makes a test time out
+// with an opaque panic instead of reporting leaks.
+func (o *opts) capRetries(t TestingT) {
+	td, ok := t.(testDeadline)
+	if !ok {
+		return
+	}
+	if deadline, ok := td.Deadline(); ok {
+		o.deadline = deadline.Add(-_deadlineMargin)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineT is a TestingT with a deadline.
+type deadlineT struct {
+	fakeT
+
+	deadline time.Time
+}
+
+func (dt *deadlineT) Deadline() (time.Time, bool) {
+	return dt.deadline, !dt.deadline.IsZero()
+}
+
+func TestVerifyNoneDeadline(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	t.Run("stops retrying before the deadline", func(t *testing.T) {
+		dt := &deadlineT{deadline: time.Now().Add(_deadlineMargin + 50*time.Millisecond)}
+		var stats Stats
+		start := time.Now()
+		VerifyNone(dt, OnStats(func(s Stats) { stats = s }))
+		require.Len(t, dt.errors, 1, "leak should still be reported")
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Less(t, stats.Retries, _defaultRetries)
+	})
+
+	t.Run("past the deadline", func(t *testing.T) {
+		dt := &deadlineT{deadline: time.Now()}
+		var stats Stats
+		VerifyNone(dt, OnStats(func(s Stats) { stats = s }))
+		require.Len(t, dt.errors, 1)
+		assert.Equal(t, 0, stats.Retries, "should not retry")
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		dt := &deadlineT{}
+		var stats Stats
+		VerifyNone(dt, testOptions(), StableAfter(0), OnStats(func(s Stats) { stats = s }))
+		require.Len(t, dt.errors, 1)
+		assert.Equal(t, _defaultRetries, stats.Retries)
+	})
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// syncWait describes how a goroutine blocked in a sync primitive is
+// usually unblocked, to hint at what the goroutines it waits for miss.
+type syncWait struct {
+	primitive string // e.g. "Mutex"
+	hint      string // what a counterpart may be doing wrong
+	orphan    string // likely cause if there is no counterpart
+}
+
+// Functions of sync primitives that goroutines block in.
+// Lock and RLock are usually inlined, so their slow paths are listed too.
+var _syncWaits = map[string]syncWait{
+	"sync.(*WaitGroup).Wait": {
+		primitive: "WaitGroup",
+		hint:      "may be missing a call to Done",
+		orphan:    "a call to Done may be missing, e.g. on an early return",
+	},
+	"sync.(*Mutex).Lock":              _mutexWait,
+	"sync.(*Mutex).lockSlow":          _mutexWait,
+	"internal/sync.(*Mutex).Lock":     _mutexWait,
+	"internal/sync.(*Mutex).lockSlow": _mutexWait,
+	"sync.(*RWMutex).Lock":            _rwMutexWait,
+	"sync.(*RWMutex).RLock": {
+		primitive: "RWMutex",
+		hint:      "may be holding the write lock",
+		orphan:    "it may have been locked for writing without being unlocked",
+	},
+}
+
+var (
+	_mutexWait = syncWait{
+		primitive: "Mutex",
+		hint:      "may be holding the lock",
+		orphan:    "it may have been locked without being unlocked, e.g. on an early return",
+	}
+	_rwMutexWait = syncWait{
+		primitive: "RWMutex",
+		hint:      "may be holding the lock",
+		orphan:    "it may have been locked without being unlocked, e.g. on an early return",
+	}
+)
+
+// deadlockHints cross-references leaked goroutines that are blocked in
+// sync primitives with the other leaked goroutines that reference the
+// same objects, which likely hold the lock or owe a call to Done.
+//
+// Objects are matched by the pointers passed to the functions on the
+// stacks, which the runtime prints unless they were inlined or only
+// kept in registers, so matches are likely but not certain.
+func deadlockHints(stacks []stack.Stack) string {
+	var sb strings.Builder
+	for _, waiter := range stacks {
+		fn, wait, ptrs, ok := syncWaitOf(waiter)
+		if !ok {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\ndeadlock hints:\n")
+		}
+
+		var counterparts []stack.Stack
+		for _, s := range stacks {
+			if s.ID() != waiter.ID() && referencesAny(s, ptrs) {
+				counterparts = append(counterparts, s)
+			}
+		}
+		if len(counterparts) == 0 {
+			fmt.Fprintf(&sb, "goroutine %d is blocked in %v and no other leaked goroutine references the same %v; %v.\n",
+				waiter.ID(), fn, wait.primitive, wait.orphan)
+			continue
+		}
+
+		ids := make([]string, len(counterparts))
+		for i, s := range counterparts {
+			ids[i] = fmt.Sprintf("goroutine %d", s.ID())
+		}
+		fmt.Fprintf(&sb, "goroutine %d is blocked in %v; %v likely references the same %v and %v:\n",
+			waiter.ID(), fn, strings.Join(ids, ", "), wait.primitive, wait.hint)
+		sb.WriteString(waiter.String())
+		for _, s := range counterparts {
+			sb.WriteString(s.String())
+		}
+	}
+	return sb.String()
+}
+
+// syncWaitOf reports whether s is blocked in a sync primitive, and if so
+// returns the function it's blocked in and the pointers passed to the
+// frames up to and including its caller, one of which is the primitive.
+func syncWaitOf(s stack.Stack) (fn string, wait syncWait, ptrs map[uint64]struct{}, ok bool) {
+	if !strings.Contains(s.Full(), "sync.") {
+		// Avoid parsing the frames of stacks that can't match.
+		return "", syncWait{}, nil, false
+	}
+
+	entries := s.Entries()
+	for i, entry := range entries {
+		if entry.IsSource {
+			break
+		}
+		name := entry.Function()
+		w, isWait := _syncWaits[name]
+		if !isWait {
+			continue
+		}
+		// Report the outermost sync function,
+		// e.g. Mutex.Lock rather than Mutex.lockSlow.
+		fn, wait, ok = name, w, true
+		if ptrs == nil {
+			ptrs = make(map[uint64]struct{})
+		}
+		// Collect pointers from the top of the stack
+		// up to the caller of the outermost sync function.
+		for _, e := range entries[:min(i+2, len(entries))] {
+			addPointers(ptrs, e)
+		}
+	}
+	if ok && len(ptrs) == 0 {
+		// Nothing to match counterparts with.
+		ptrs = nil
+	}
+	return fn, wait, ptrs, ok
+}
+
+// referencesAny reports whether any frame of s was passed
+// one of the given pointers.
+func referencesAny(s stack.Stack, ptrs map[uint64]struct{}) bool {
+	if len(ptrs) == 0 {
+		return false
+	}
+	for _, entry := range s.Entries() {
+		found := make(map[uint64]struct{})
+		addPointers(found, entry)
+		for p := range found {
+			if _, ok := ptrs[p]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// _minPointer is the smallest argument taken for a pointer.
+// Smaller integers are unlikely to be addresses.
+const _minPointer = 1 << 28
+
+// addPointers adds the arguments of the function call of entry, like
+//
+//	sync.(*WaitGroup).Wait(0xc0000140a0?)
+//
+// that look like pointers to ptrs.
+func addPointers(ptrs map[uint64]struct{}, entry stack.Entry) {
+	for _, arg := range entry.Args() {
+		if arg.Value >= _minPointer {
+			ptrs[arg.Value] = struct{}{}
+		}
+	}
+}
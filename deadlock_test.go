@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlockHints(t *testing.T) {
+	waitGroupWaiter := []string{
+		"goroutine 7 [sync.WaitGroup.Wait]:",
+		"sync.runtime_SemacquireWaitGroup(0xc0000140a8?)",
+		"	/usr/local/go/src/runtime/sema.go:110 +0x25",
+		"sync.(*WaitGroup).Wait(0xc0000140a0)",
+		"	/usr/local/go/src/sync/waitgroup.go:118 +0x48",
+		"main.run(0xc0000140a0)",
+		"	/app/main.go:30 +0x85",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:12 +0x1d",
+		"",
+	}
+	worker := []string{
+		"goroutine 8 [chan receive]:",
+		"main.work(0xc000100000, 0xc0000140a0)",
+		"	/app/main.go:40 +0x2b",
+		"created by main.run in goroutine 1",
+		"	/app/main.go:25 +0x6a",
+		"",
+	}
+	mutexWaiter := []string{
+		"goroutine 9 [sync.Mutex.Lock]:",
+		"internal/sync.runtime_SemacquireMutex(0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/sema.go:95 +0x25",
+		"internal/sync.(*Mutex).lockSlow(0xc000200000)",
+		"	/usr/local/go/src/internal/sync/mutex.go:149 +0x15a",
+		"internal/sync.(*Mutex).Lock(...)",
+		"	/usr/local/go/src/internal/sync/mutex.go:70",
+		"sync.(*Mutex).Lock(...)",
+		"	/usr/local/go/src/sync/mutex.go:46",
+		"main.update(0x0?)",
+		"	/app/main.go:50 +0x31",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:14 +0x1d",
+		"",
+	}
+	dump := func(goroutines ...[]string) []byte {
+		var lines []string
+		for _, g := range goroutines {
+			lines = append(lines, g...)
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	t.Run("counterpart", func(t *testing.T) {
+		err := FindInDump(dump(waitGroupWaiter, worker))
+		require.Error(t, err)
+		_, hints, ok := strings.Cut(err.Error(), "\ndeadlock hints:\n")
+		require.True(t, ok, "no hints in:\n%v", err)
+		assert.True(t, strings.HasPrefix(hints,
+			"goroutine 7 is blocked in sync.(*WaitGroup).Wait; goroutine 8 likely references the same WaitGroup and may be missing a call to Done:\n"+
+				"Goroutine 7 in state sync.WaitGroup.Wait"), "unexpected hints:\n%v", hints)
+		assert.Contains(t, hints, "Goroutine 8 in state chan receive")
+	})
+
+	t.Run("no counterpart", func(t *testing.T) {
+		err := FindInDump(dump(mutexWaiter, worker))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "\ndeadlock hints:\n"+
+			"goroutine 9 is blocked in sync.(*Mutex).Lock and no other leaked goroutine references the same Mutex; "+
+			"it may have been locked without being unlocked, e.g. on an early return.\n")
+		assert.NotContains(t, err.Error(), "goroutine 8 likely")
+	})
+
+	t.Run("not blocked in sync", func(t *testing.T) {
+		err := FindInDump(dump(worker))
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "deadlock hints")
+	})
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// ErrDetectorStopped is the cause of the errors of the leak checks of
+// a Detector that are interrupted or started after it's stopped.
+var ErrDetectorStopped = errors.New("goleak: detector stopped")
+
+// Detector runs leak checks with a set of options that are built once,
+// for programs and test suites that check for leaks repeatedly. The
+// checks of a Detector share its options, including their compiled
+// filters, and the goroutines recorded with [Detector.IgnoreCurrent].
+//
+// The checks in progress can be interrupted, e.g. when a program that
+// checks itself for leaks shuts down:
+//
+//	d := goleak.NewDetector(goleak.IgnoreCurrent())
+//	go func() {
+//		<-shutdown
+//		d.Stop()
+//	}()
+//	if err := d.Find(ctx); err != nil && !errors.Is(err, goleak.ErrDetectorStopped) {
+//		log.Print(err)
+//	}
+//
+// A Detector is safe for concurrent use.
+type Detector struct {
+	// opts are built once; each check uses a copy.
+	opts *opts
+	ctx  context.Context
+	stop context.CancelCauseFunc
+
+	mu       sync.Mutex
+	baseline *Baseline
+}
+
+// NewDetector returns a Detector whose checks use the given options.
+// Options set with [SetDefaults] or selected with GOLEAK_PROFILE are
+// applied once, when the Detector is created.
+func NewDetector(options ...Option) *Detector {
+	ctx, stop := context.WithCancelCause(context.Background())
+	return &Detector{opts: buildOpts(options...), ctx: ctx, stop: stop}
+}
+
+// Find is like [Find] with the options of the detector. It stops
+// retrying as soon as ctx is done or the detector is stopped, rather
+// than when the retries are exhausted, and then returns an error that
+// wraps ctx.Err(), or ErrDetectorStopped, instead of reporting leaks.
+func (d *Detector) Find(ctx context.Context) error {
+	ctx, release := d.context(ctx)
+	defer release()
+
+	opts := d.checkOpts()
+	opts.ctx = ctx
+	return find(opts)
+}
+
+// VerifyNone is like [VerifyNone] with the options of the detector.
+// If the detector is stopped while retrying, the test fails with an
+// error that wraps ErrDetectorStopped.
+func (d *Detector) VerifyNone(t TestingT) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	ctx, release := d.context(context.Background())
+	defer release()
+
+	opts := d.checkOpts()
+	opts.ctx = ctx
+	verifyNone(t, opts)
+}
+
+// Snapshot is like [TakeSnapshot] with the options of the detector.
+func (d *Detector) Snapshot() Snapshot {
+	opts := d.checkOpts()
+	return newSnapshot(time.Now(), filterStacks(opts.stacks(), stack.Current().ID(), opts))
+}
+
+// Monitor is like [DetectGrowth] with the options of the detector.
+// The returned channel is closed once ctx is done or the detector is
+// stopped. It panics if window is too short, like DetectGrowth.
+func (d *Detector) Monitor(ctx context.Context, window time.Duration, threshold int) <-chan GrowthReport {
+	checkGrowthWindow("Monitor", window)
+	ctx, release := d.context(ctx)
+	context.AfterFunc(ctx, release)
+	return detectGrowth(ctx, window, threshold, d.checkOpts())
+}
+
+// IgnoreCurrent records the goroutines that are running, which later
+// checks of the detector ignore, like with [IgnoreCurrent]. Calling it
+// again records the goroutines running then, in addition.
+func (d *Detector) IgnoreCurrent() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.baseline == nil {
+		d.baseline = NewBaseline()
+		return
+	}
+	d.baseline.Refresh()
+}
+
+// Stop interrupts the checks of the detector in progress,
+// and makes later checks fail with ErrDetectorStopped.
+// It is safe to call Stop multiple times.
+func (d *Detector) Stop() {
+	d.stop(ErrDetectorStopped)
+}
+
+// context returns a context that is done when ctx is done or the
+// detector is stopped, and a function that releases it.
+func (d *Detector) context(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stopped := context.AfterFunc(d.ctx, func() { cancel(context.Cause(d.ctx)) })
+	return ctx, func() {
+		stopped()
+		cancel(nil)
+	}
+}
+
+// checkOpts returns a copy of the options of the detector for a check,
+// which the check may change, with the baseline of the detector.
+func (d *Detector) checkOpts() *opts {
+	opts := d.opts.clone()
+	d.mu.Lock()
+	if d.baseline != nil {
+		d.baseline.apply(opts)
+	}
+	d.mu.Unlock()
+	return opts
+}
+
+// clone returns a copy of o whose slices can be appended to
+// without changing those of o.
+func (o *opts) clone() *opts {
+	c := *o
+	c.filters = slices.Clip(o.filters)
+	c.defaultFilters = slices.Clip(o.defaultFilters)
+	c.includes = slices.Clip(o.includes)
+	c.failFastStates = slices.Clip(o.failFastStates)
+	c.alerts = slices.Clip(o.alerts)
+	c.quarantineMatch = slices.Clip(o.quarantineMatch)
+	c.allowances = slices.Clip(o.allowances)
+	c.rulesWatchers = slices.Clip(o.rulesWatchers)
+	c.poolDetectors = slices.Clip(o.poolDetectors)
+	c.ageTrackers = slices.Clip(o.ageTrackers)
+	return &c
+}
+
+// interrupted returns an error if the check was interrupted
+// through the context of a Detector.
+func (o *opts) interrupted() error {
+	if o.ctx == nil || o.ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("leak check interrupted: %w", context.Cause(o.ctx))
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retryForever makes checks retry until they're interrupted.
+func retryForever() Option {
+	return Options{StableAfter(0), maxSleep(time.Minute), optionFunc(func(opts *opts) {
+		opts.maxRetries = 1 << 30
+	})}
+}
+
+func TestDetectorFind(t *testing.T) {
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	d := NewDetector(testOptions(), include)
+	require.NoError(t, d.Find(context.Background()))
+
+	bg := startBlockedG()
+	err := d.Find(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found unexpected goroutines")
+	bg.unblock()
+}
+
+func TestDetectorInterrupt(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	check := func(t *testing.T, d *Detector, ctx context.Context) error {
+		errc := make(chan error)
+		go func() {
+			errc <- d.Find(ctx)
+		}()
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(10 * time.Second):
+			t.Fatal("check was not interrupted")
+			return nil
+		}
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		var leaked []stack.Stack
+		d := NewDetector(include, retryForever(), OnLeak(func(stacks []stack.Stack) { leaked = stacks }))
+		time.AfterFunc(10*time.Millisecond, d.Stop)
+		err := check(t, d, context.Background())
+		assert.ErrorIs(t, err, ErrDetectorStopped)
+		assert.EqualError(t, err, "leak check interrupted: goleak: detector stopped")
+		assert.Empty(t, leaked, "interrupted checks should not report leaks")
+
+		// Later checks fail right away.
+		assert.ErrorIs(t, d.Find(context.Background()), ErrDetectorStopped)
+		d.Stop()
+	})
+
+	t.Run("context", func(t *testing.T) {
+		d := NewDetector(include, retryForever())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := check(t, d, ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.False(t, errors.Is(err, ErrDetectorStopped))
+
+		assert.NoError(t, d.ctx.Err(), "the detector should not be stopped")
+	})
+}
+
+func TestDetectorVerifyNone(t *testing.T) {
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	d := NewDetector(testOptions(), include)
+
+	ft := &fakeT{}
+	d.VerifyNone(ft)
+	assert.Empty(t, ft.errors)
+
+	bg := startBlockedG()
+	defer bg.unblock()
+	d.VerifyNone(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "found unexpected goroutines")
+
+	ft = &fakeT{}
+	d.Stop()
+	d.VerifyNone(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "leak check interrupted: goleak: detector stopped")
+}
+
+func TestDetectorIgnoreCurrent(t *testing.T) {
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	d := NewDetector(testOptions(), include)
+
+	bg := startBlockedG()
+	defer bg.unblock()
+	assert.Len(t, d.Snapshot().Counts, 1)
+
+	d.IgnoreCurrent()
+	assert.NoError(t, d.Find(context.Background()))
+	assert.Empty(t, d.Snapshot().Counts)
+
+	bg2 := startBlockedG()
+	defer bg2.unblock()
+	assert.Error(t, d.Find(context.Background()))
+	d.IgnoreCurrent()
+	assert.NoError(t, d.Find(context.Background()))
+
+	assert.Error(t, NewDetector(testOptions(), include).Find(context.Background()),
+		"baselines should not be shared between detectors")
+}
+
+func TestDetectorOptionsBuiltOnce(t *testing.T) {
+	defer SetDefaults()
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	d := NewDetector(testOptions(), include)
+	SetDefaults(IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	assert.Error(t, d.Find(context.Background()), "defaults set later should not apply")
+	assert.NoError(t, Find(testOptions(), include))
+
+	// Checks must not share the slices of the options they append to.
+	opts := d.checkOpts()
+	opts.filters = append(opts.filters, filter{name: "all", match: func(stack.Stack) bool { return true }})
+	assert.Len(t, d.checkOpts().filters, len(opts.filters)-1)
+}
+
+func TestDetectorMonitor(t *testing.T) {
+	defer VerifyNone(t)
+
+	d := NewDetector()
+	reports := d.Monitor(context.Background(), 50*time.Millisecond, 1)
+	d.Stop()
+	select {
+	case _, ok := <-reports:
+		for ok {
+			_, ok = <-reports
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Monitor did not stop with the detector")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _dumps shares goroutine dumps between concurrent leak checks.
+var _dumps = newDumpCache()
+
+// dumpCache lets concurrent leak checks, such as those of parallel
+// tests, share goroutine dumps rather than each dumping all goroutines.
+//
+// Dumps are taken in generations: callers that ask for a dump while
+// one is being taken wait for it to complete, and then share the next
+// one, so every caller gets a dump taken after it asked for one.
+type dumpCache struct {
+	mu sync.Mutex
+	// pending and running are the generations waiting to be dumped
+	// and being dumped, by MaxDumpBytes limit.
+	pending map[int]*dumpGeneration
+	running map[int]*dumpGeneration
+	// checking counts the leak checks that each goroutine is running,
+	// or waits to run, like that of DumpOnTimeout.
+	checking map[int]int
+}
+
+type dumpGeneration struct {
+	done      chan struct{}
+	stacks    []stack.Stack
+	truncated bool // whether goroutines didn't fit in the limit
+}
+
+func newDumpCache() *dumpCache {
+	return &dumpCache{
+		pending:  make(map[int]*dumpGeneration),
+		running:  make(map[int]*dumpGeneration),
+		checking: make(map[int]int),
+	}
+}
+
+// stacks returns the stacks of all goroutines, subject to limit,
+// from a dump taken after the call, and whether goroutines were
+// omitted because they didn't fit in the limit.
+// The returned slice is owned by the caller.
+func (c *dumpCache) stacks(limit int) ([]stack.Stack, bool) {
+	c.mu.Lock()
+	gen, ok := c.pending[limit]
+	if !ok {
+		gen = &dumpGeneration{done: make(chan struct{})}
+		c.pending[limit] = gen
+	}
+	for {
+		if c.pending[limit] != gen {
+			// Another caller took the dump of this generation.
+			c.mu.Unlock()
+			<-gen.done
+			return append([]stack.Stack(nil), gen.stacks...), gen.truncated
+		}
+		running, ok := c.running[limit]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		<-running.done
+		c.mu.Lock()
+	}
+
+	delete(c.pending, limit)
+	c.running[limit] = gen
+	c.mu.Unlock()
+
+	gen.stacks, gen.truncated = stack.AllLimited(limit)
+
+	c.mu.Lock()
+	delete(c.running, limit)
+	c.mu.Unlock()
+	close(gen.done)
+	return append([]stack.Stack(nil), gen.stacks...), gen.truncated
+}
+
+// startCheck records that the goroutine with the given ID is running
+// a leak check, until the returned function is called.
+func (c *dumpCache) startCheck(id int) (done func()) {
+	c.mu.Lock()
+	c.checking[id]++
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.checking[id]--; c.checking[id] == 0 {
+			delete(c.checking, id)
+		}
+	}
+}
+
+// checkingIDs returns the IDs of the goroutines running leak checks.
+func (c *dumpCache) checkingIDs() map[int]struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make(map[int]struct{}, len(c.checking))
+	for id := range c.checking {
+		ids[id] = struct{}{}
+	}
+	return ids
+}
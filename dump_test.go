@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpCacheStacks(t *testing.T) {
+	c := newDumpCache()
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	const callers = 8
+	var (
+		wg     sync.WaitGroup
+		dumps  = make([][]stack.Stack, callers)
+		starts = make(chan struct{})
+	)
+	for i := range dumps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-starts
+			dumps[i], _ = c.stacks(0)
+		}(i)
+	}
+	close(starts)
+	wg.Wait()
+	waitForStable(t)
+
+	for _, dump := range dumps {
+		assert.True(t, containsBlockedG(dump), "dump should have goroutines started before the call")
+	}
+
+	// Callers own the returned slices.
+	dumps[0][0] = stack.Stack{}
+	again, truncated := c.stacks(0)
+	assert.NotEqual(t, stack.Stack{}, again[0])
+	assert.False(t, truncated, "unlimited dumps are never truncated")
+
+	assert.Empty(t, c.pending)
+	assert.Empty(t, c.running)
+}
+
+func TestConcurrentChecks(t *testing.T) {
+	const checkers = 8
+	ignore := IgnoreCurrent()
+	var (
+		wg     sync.WaitGroup
+		errs   = make([]error, checkers)
+		starts = make(chan struct{})
+	)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-starts
+			errs[i] = Find(ignore, testOptions())
+		}(i)
+	}
+	close(starts)
+	wg.Wait()
+	waitForStable(t)
+
+	for _, err := range errs {
+		assert.NoError(t, err, "leak checks should not report each other")
+	}
+	require.Empty(t, _dumps.checkingIDs())
+}
+
+func containsBlockedG(stacks []stack.Stack) bool {
+	for _, s := range stacks {
+		if s.FirstFunction() == "github.com/projectdiscovery/goleak.(*blockedG).block" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+module github.com/projectdiscovery/goleak/goleakgrpc
+
+go 1.24.0
+
+require (
+	github.com/projectdiscovery/goleak v0.0.0
+	github.com/stretchr/testify v1.8.4
+	google.golang.org/grpc v1.79.3
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/logrusorgru/aurora/v4 v4.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.48.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/projectdiscovery/goleak => ../
@@ -0,0 +1,190 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package goleakgrpc checks gRPC handlers for leaked goroutines.
+//
+// New starts an in-process gRPC server and a client connected to it
+// over an in-memory connection. Close tears both down and fails the
+// test if the handlers leaked goroutines:
+//
+//	h := goleakgrpc.New(t, func(s *grpc.Server) {
+//		pb.RegisterGreeterServer(s, &greeter{})
+//	})
+//	defer h.Close()
+//
+//	client := pb.NewGreeterClient(h.Conn)
+//
+// grpc-go starts goroutines for its transports, resolvers and balancers
+// that may still be exiting when the harness is closed. They are
+// ignored, so only goroutines started by handlers are reported.
+package goleakgrpc
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"sync"
+
+	"github.com/projectdiscovery/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// _bufSize is the buffer size of the in-memory connections.
+const _bufSize = 1 << 20
+
+// _internalFunctions matches the functions of the goroutines that
+// grpc-go starts for itself: transport loops, callback serializers of
+// resolver and balancer wrappers, and connection management.
+// Handler goroutines don't have these functions on their stacks.
+var _internalFunctions = regexp.MustCompile(`^google\.golang\.org/grpc/internal/(transport|grpcsync)\.|` +
+	`^google\.golang\.org/grpc\.\(\*(addrConn|ccBalancerWrapper|ccResolverWrapper|pickerWrapper)\)\.|` +
+	`^google\.golang\.org/grpc/test/bufconn\.`)
+
+// TestingT is the subset of testing.TB used by the harness.
+type TestingT interface {
+	goleak.TestingT
+
+	Fatal(args ...interface{})
+}
+
+// Option configures a Harness.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (f optionFunc) apply(c *config) { f(c) }
+
+type config struct {
+	serverOptions []grpc.ServerOption
+	dialOptions   []grpc.DialOption
+	leakOptions   []goleak.Option
+}
+
+// ServerOptions adds options for the gRPC server, e.g. interceptors.
+func ServerOptions(options ...grpc.ServerOption) Option {
+	return optionFunc(func(c *config) {
+		c.serverOptions = append(c.serverOptions, options...)
+	})
+}
+
+// DialOptions adds options for the client connection.
+func DialOptions(options ...grpc.DialOption) Option {
+	return optionFunc(func(c *config) {
+		c.dialOptions = append(c.dialOptions, options...)
+	})
+}
+
+// LeakOptions adds options for the leak check on Close,
+// interpreted as for [goleak.Find].
+func LeakOptions(options ...goleak.Option) Option {
+	return optionFunc(func(c *config) {
+		c.leakOptions = append(c.leakOptions, options...)
+	})
+}
+
+// Harness is an in-process gRPC server with a client connected to it.
+type Harness struct {
+	// Server is the running server.
+	Server *grpc.Server
+	// Conn is a client connection to Server.
+	Conn *grpc.ClientConn
+
+	t         TestingT
+	lis       *bufconn.Listener
+	serveDone chan struct{}
+	options   []goleak.Option
+	once      sync.Once
+}
+
+type testHelper interface {
+	Helper()
+}
+
+// New starts a gRPC server with the services added by register,
+// and connects a client to it. Goroutines running before New
+// are not reported by Close.
+func New(t TestingT, register func(*grpc.Server), options ...Option) *Harness {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	var cfg config
+	for _, opt := range options {
+		opt.apply(&cfg)
+	}
+
+	h := &Harness{
+		t:         t,
+		lis:       bufconn.Listen(_bufSize),
+		serveDone: make(chan struct{}),
+	}
+	h.options = append(h.options, cfg.leakOptions...)
+	h.options = append(h.options,
+		goleak.IgnoreFunctionRegexp(_internalFunctions),
+		// Record the goroutines before the server starts its own.
+		goleak.IgnoreCurrent(),
+	)
+
+	h.Server = grpc.NewServer(cfg.serverOptions...)
+	register(h.Server)
+	go func() {
+		defer close(h.serveDone)
+		_ = h.Server.Serve(h.lis)
+	}()
+
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return h.lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, cfg.dialOptions...)
+	conn, err := grpc.NewClient("passthrough:///bufconn", dialOptions...)
+	if err != nil {
+		h.Server.Stop()
+		<-h.serveDone
+		t.Fatal(err)
+		return nil
+	}
+	h.Conn = conn
+	return h
+}
+
+// Close closes the client connection, stops the server and marks the
+// test as failed if goroutines started since New are still running,
+// retrying like [goleak.VerifyNone]. Stopping the server cancels the
+// contexts of running handlers, so handlers that honor them have
+// returned by the time goroutines are checked.
+// Closing a harness more than once only checks the first time.
+func (h *Harness) Close() {
+	if hh, ok := h.t.(testHelper); ok {
+		hh.Helper()
+	}
+
+	h.once.Do(func() {
+		_ = h.Conn.Close()
+		h.Server.Stop()
+		<-h.serveDone
+		goleak.VerifyNone(h.t, h.options...)
+	})
+}
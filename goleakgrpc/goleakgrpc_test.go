@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleakgrpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (ft *fakeT) Error(args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprint(args...))
+}
+
+func (ft *fakeT) Fatal(args ...interface{}) {
+	panic(fmt.Sprint(args...))
+}
+
+// leakyHealth starts a goroutine that blocks until done is closed
+// on every health check.
+type leakyHealth struct {
+	healthpb.UnimplementedHealthServer
+
+	done chan struct{}
+}
+
+func (s *leakyHealth) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	go func() { <-s.done }()
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func check(t *testing.T, h *Harness) {
+	resp, err := healthpb.NewHealthClient(h.Conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestHarness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ft := &fakeT{}
+	h := New(ft, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	})
+	check(t, h)
+	h.Close()
+	assert.Empty(t, ft.errors)
+}
+
+func TestHarnessLeak(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	srv := &leakyHealth{done: make(chan struct{})}
+	defer close(srv.done)
+
+	ft := &fakeT{}
+	h := New(ft, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, srv)
+	}, LeakOptions(goleak.FailFastStates("chan receive")))
+	check(t, h)
+	h.Close()
+	h.Close()
+
+	require.Len(t, ft.errors, 1, "leak should be reported once")
+	assert.Contains(t, ft.errors[0], "(*leakyHealth).Check.func1")
+	assert.NotContains(t, ft.errors[0], "google.golang.org/grpc/internal")
+}
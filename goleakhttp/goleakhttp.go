@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package goleakhttp checks net/http handlers for leaked goroutines.
+//
+// LeakCheckedServer starts an [httptest.Server] that fails the test
+// if the handler leaked goroutines once the server is closed:
+//
+//	srv := goleakhttp.LeakCheckedServer(t, handler)
+//	defer srv.Close()
+//
+// The goroutines of the server and of HTTP clients that talk to it are
+// ignored, so tests don't need to ignore them by hand.
+//
+// Outside of tests, CheckOnShutdown logs the goroutines that a service
+// leaks when its http.Server shuts down.
+package goleakhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// Functions of the goroutines that serve requests or keep connections
+// alive, which may still be exiting when the server is closed.
+var _serverFunctions = []string{
+	"net/http/httptest.(*Server).goServe.func1",
+	"net/http.(*Server).Serve",
+	"net/http.(*persistConn).readLoop",
+	"net/http.(*persistConn).writeLoop",
+}
+
+// Server is an httptest.Server that checks for leaked goroutines
+// when it is closed.
+type Server struct {
+	*httptest.Server
+
+	t       goleak.TestingT
+	options []goleak.Option
+	once    sync.Once
+}
+
+type testHelper interface {
+	Helper()
+}
+
+// LeakCheckedServer starts an httptest.Server with the given handler.
+// Close stops the server and marks t as failed if goroutines started
+// since the server was created are still running, retrying like
+// [goleak.VerifyNone]. Options are interpreted as for [goleak.Find].
+func LeakCheckedServer(t goleak.TestingT, handler http.Handler, options ...goleak.Option) *Server {
+	s := &Server{t: t}
+	s.options = append(s.options, options...)
+	for _, f := range _serverFunctions {
+		s.options = append(s.options, goleak.IgnoreAnyFunction(f))
+	}
+	// Record the goroutines before the server starts its own.
+	s.options = append(s.options, goleak.IgnoreCurrent())
+	s.Server = httptest.NewServer(handler)
+	return s
+}
+
+// Close shuts down the server like [httptest.Server.Close],
+// waiting for outstanding requests, and checks for leaked goroutines.
+// Closing a server more than once only checks the first time.
+func (s *Server) Close() {
+	if h, ok := s.t.(testHelper); ok {
+		h.Helper()
+	}
+
+	s.Server.Close()
+	s.once.Do(func() {
+		goleak.VerifyNone(s.t, s.options...)
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleakhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (ft *fakeT) Error(args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprint(args...))
+}
+
+func get(t *testing.T, client *http.Client, url string) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestLeakCheckedServer(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ft := &fakeT{}
+	srv := LeakCheckedServer(ft, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	}))
+	get(t, srv.Client(), srv.URL)
+	get(t, http.DefaultClient, srv.URL)
+	srv.Close()
+	assert.Empty(t, ft.errors)
+}
+
+func TestLeakCheckedServerLeak(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	ft := &fakeT{}
+	srv := LeakCheckedServer(ft, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		go func() { <-done }()
+	}), goleak.FailFastStates("chan receive"))
+	get(t, srv.Client(), srv.URL)
+	srv.Close()
+	srv.Close()
+
+	require.Len(t, ft.errors, 1, "leak should be reported once")
+	assert.Contains(t, ft.errors[0], "TestLeakCheckedServerLeak.func1.1")
+}
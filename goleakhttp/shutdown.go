@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleakhttp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// _shutdownFunction is the function of the goroutine that shuts the
+// server down, which waits for connections to close while the leak
+// analysis runs.
+const _shutdownFunction = "net/http.(*Server).Shutdown"
+
+// CheckOnShutdown registers a leak analysis that runs when srv is shut
+// down with [http.Server.Shutdown], so that services report the
+// goroutines they leak when they exit, e.g. in staging environments.
+// Call it before starting the server: goroutines that run when
+// CheckOnShutdown is called are ignored.
+//
+// Once the shutdown starts, the analysis waits up to timeout for the
+// goroutines started since then to exit, like [goleak.Await], and logs
+// them at warn level if some remain. A nil logger uses slog.Default().
+// Options are interpreted as for goleak.Await.
+//
+// The returned channel receives the result of the analysis, so that
+// the program can wait for it before exiting. The analysis only runs
+// on the first shutdown of srv. Combined with signal
+// handling, a service can check for leaks on SIGTERM:
+//
+//	leaks := goleakhttp.CheckOnShutdown(srv, 10*time.Second, logger)
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//	go srv.ListenAndServe()
+//	<-ctx.Done()
+//	srv.Shutdown(context.Background())
+//	<-leaks
+func CheckOnShutdown(srv *http.Server, timeout time.Duration, logger *slog.Logger, options ...goleak.Option) <-chan error {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	options = append(options,
+		goleak.IgnoreAnyFunction(_shutdownFunction),
+		// Record the goroutines before the server starts its own.
+		goleak.IgnoreCurrent(),
+	)
+
+	result := make(chan error, 1)
+	// Every call to Shutdown runs the hooks again, and only the first
+	// result fits in the channel.
+	var once sync.Once
+	srv.RegisterOnShutdown(func() {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			err := goleak.Await(ctx, options...)
+			if err != nil {
+				logger.Warn("goleakhttp: found leaked goroutines at shutdown", "error", err)
+			} else {
+				logger.Info("goleakhttp: no leaked goroutines at shutdown")
+			}
+			result <- err
+		})
+	})
+	return result
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleakhttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve starts srv and returns its URL and a client for it.
+func serve(t *testing.T, srv *http.Server) (string, *http.Client) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	t.Cleanup(func() { assert.ErrorIs(t, <-served, http.ErrServerClosed) })
+
+	transport := &http.Transport{}
+	t.Cleanup(transport.CloseIdleConnections)
+	return "http://" + ln.Addr().String(), &http.Client{Transport: transport}
+}
+
+func TestCheckOnShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})}
+	leaks := CheckOnShutdown(srv, 10*time.Second, logger)
+
+	url, client := serve(t, srv)
+	get(t, client, url)
+	client.CloseIdleConnections()
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	require.NoError(t, <-leaks)
+	assert.Contains(t, logs.String(), "no leaked goroutines at shutdown")
+
+	// Later shutdowns run the hook again, which must not block.
+	require.NoError(t, srv.Shutdown(context.Background()))
+	require.NoError(t, srv.Close())
+	assert.Equal(t, 1, strings.Count(logs.String(), "no leaked goroutines at shutdown"))
+}
+
+func TestCheckOnShutdownLeak(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		go func() { <-done }()
+	})}
+	leaks := CheckOnShutdown(srv, 100*time.Millisecond, logger)
+
+	url, client := serve(t, srv)
+	get(t, client, url)
+	client.CloseIdleConnections()
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	err := <-leaks
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "TestCheckOnShutdownLeak.func1.1")
+	assert.Contains(t, logs.String(), "found leaked goroutines at shutdown")
+}
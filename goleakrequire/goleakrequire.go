@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package goleakrequire integrates goleak with testify.
+//
+// NoLeaks is a require-style assertion that stops the test
+// if goroutines are leaked:
+//
+//	defer goleakrequire.NoLeaks(t)
+//
+// Suite replaces suite.Suite to check every test of a suite for leaks.
+package goleakrequire
+
+import (
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type tHelper interface {
+	Helper()
+}
+
+// NoLeaks asserts that no unexpected goroutines are running,
+// and stops the test with t.FailNow if there are.
+// Options are interpreted as for [goleak.Find].
+func NoLeaks(t require.TestingT, options ...goleak.Option) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+
+	if err := goleak.Find(options...); err != nil {
+		t.Errorf("%v", err)
+		t.FailNow()
+	}
+}
+
+// Suite is a drop-in replacement for suite.Suite
+// that checks every test of the suite for leaked goroutines.
+//
+//	type MySuite struct {
+//		goleakrequire.Suite
+//	}
+//
+// Goroutines that are running when a test starts are ignored,
+// so goroutines started by SetupSuite are not reported.
+// Suites that define their own SetupTest or TearDownTest
+// must call the methods of Suite from them.
+type Suite struct {
+	suite.Suite
+
+	// LeakOptions are passed to every leak check.
+	LeakOptions []goleak.Option
+
+	ignoreCurrent goleak.Option
+}
+
+// SetupTest records the goroutines running before the test.
+func (s *Suite) SetupTest() {
+	s.ignoreCurrent = goleak.IgnoreCurrent()
+}
+
+// TearDownTest fails the test if it leaked goroutines.
+func (s *Suite) TearDownTest() {
+	s.checkLeaks(s.T())
+}
+
+// checkLeaks reports the goroutines leaked by the test to t.
+func (s *Suite) checkLeaks(t require.TestingT) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+
+	options := append([]goleak.Option{}, s.LeakOptions...)
+	if s.ignoreCurrent != nil {
+		options = append(options, s.ignoreCurrent)
+	}
+	NoLeaks(t, options...)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleakrequire
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeT struct {
+	errors []string
+	failed bool
+}
+
+func (ft *fakeT) Errorf(format string, args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprintf(format, args...))
+}
+
+func (ft *fakeT) FailNow() { ft.failed = true }
+
+func TestNoLeaks(t *testing.T) {
+	ft := &fakeT{}
+	NoLeaks(ft)
+	assert.False(t, ft.failed)
+
+	done := make(chan struct{})
+	go func() { <-done }()
+	NoLeaks(ft, goleak.FailFastStates("chan receive"))
+	close(done)
+
+	assert.True(t, ft.failed, "leak should stop the test")
+	if assert.Len(t, ft.errors, 1) {
+		assert.Contains(t, ft.errors[0], "TestNoLeaks.func1")
+	}
+}
+
+type infraSuite struct {
+	Suite
+
+	infra chan struct{}
+}
+
+func (s *infraSuite) SetupSuite() {
+	// Goroutines started before each test are not leaks.
+	s.infra = make(chan struct{})
+	go func() { <-s.infra }()
+}
+
+func (s *infraSuite) TearDownSuite() {
+	close(s.infra)
+}
+
+func (s *infraSuite) TestNoLeak() {
+	done := make(chan struct{})
+	go func() { <-done }()
+	close(done)
+}
+
+// leakySuite reports leaks to ft rather than failing the test.
+type leakySuite struct {
+	Suite
+
+	ft   *fakeT
+	done chan struct{}
+}
+
+func (s *leakySuite) TearDownTest() {
+	s.checkLeaks(s.ft)
+}
+
+func (s *leakySuite) TestLeak() {
+	go leakySuiteWorker(s.done)
+}
+
+func leakySuiteWorker(done chan struct{}) {
+	<-done
+}
+
+func TestSuite(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("no leak", func(t *testing.T) {
+		suite.Run(t, new(infraSuite))
+	})
+
+	t.Run("leak", func(t *testing.T) {
+		s := &leakySuite{
+			Suite: Suite{LeakOptions: []goleak.Option{goleak.FailFastStates("chan receive")}},
+			ft:    &fakeT{},
+			done:  make(chan struct{}),
+		}
+		defer close(s.done)
+		suite.Run(t, s)
+
+		assert.True(t, s.ft.failed, "leak should stop the test")
+		if assert.Len(t, s.ft.errors, 1) {
+			assert.Contains(t, s.ft.errors[0], "goleakrequire.leakySuiteWorker")
+		}
+	})
+}
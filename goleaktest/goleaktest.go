@@ -0,0 +1,259 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package goleaktest helps to test code that handles leak reports, such
+// as OnLeak callbacks, exporters and formatters, with golden files.
+//
+// Leak reports change from run to run: goroutine IDs, addresses and
+// argument values differ, and so do the lines of the standard library
+// between Go versions. Normalize replaces them with placeholders, so
+// that reports can be compared with files checked into the repository:
+//
+//	func TestReport(t *testing.T) {
+//		stacks, err := stack.ParseDump(dump)
+//		require.NoError(t, err)
+//		goleaktest.Golden(t, "testdata/report.golden", goleaktest.Render(stacks))
+//	}
+//
+// Golden files are written instead of compared if the GOLEAK_UPDATE_GOLDEN
+// environment variable is set to 1:
+//
+//	GOLEAK_UPDATE_GOLDEN=1 go test ./...
+package goleaktest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _updateEnv names the environment variable that makes Golden
+// write golden files instead of comparing them.
+const _updateEnv = "GOLEAK_UPDATE_GOLDEN"
+
+// TestingT is the subset of testing.TB used by Golden.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+var (
+	// Goroutine IDs, e.g. "goroutine 7 [", "Goroutine 7 in state",
+	// "in goroutine 1" and "goroutines 7, 8".
+	_goroutineIDsRE = regexp.MustCompile(`([Gg]oroutines? )(\d+(?:, \d+)*)`)
+	_hexRE          = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	_minutesRE      = regexp.MustCompile(`\b\d+ minutes\b`)
+	// The line and the offset or registers that follow a file name.
+	_lineRE = regexp.MustCompile(`:\d+(?: .*)?$`)
+)
+
+// normalizer numbers goroutine IDs in the order they first appear,
+// so that the same IDs are replaced consistently within a report.
+type normalizer struct {
+	ids map[int]int
+}
+
+func newNormalizer() *normalizer {
+	return &normalizer{ids: make(map[int]int)}
+}
+
+// id returns the placeholder ID for the goroutine ID id.
+// Zero, which stands for unknown IDs, is kept.
+func (n *normalizer) id(id int) int {
+	if id == 0 {
+		return 0
+	}
+	if _, ok := n.ids[id]; !ok {
+		n.ids[id] = len(n.ids) + 1
+	}
+	return n.ids[id]
+}
+
+func (n *normalizer) text(s string) string {
+	s = _goroutineIDsRE.ReplaceAllStringFunc(s, func(m string) string {
+		sub := _goroutineIDsRE.FindStringSubmatch(m)
+		ids := strings.Split(sub[2], ", ")
+		for i, id := range ids {
+			v, _ := strconv.Atoi(id)
+			ids[i] = strconv.Itoa(n.id(v))
+		}
+		return sub[1] + strings.Join(ids, ", ")
+	})
+	s = _hexRE.ReplaceAllString(s, "0x?")
+	s = _minutesRE.ReplaceAllString(s, "N minutes")
+
+	// The lines of the standard library change between Go versions.
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "\t") || !isStdFunction(lines[i-1]) {
+			continue
+		}
+		file := _lineRE.ReplaceAllString(lines[i][1:], "")
+		if _, rest, ok := strings.Cut(file, "/src/"); ok {
+			file = "$GOROOT/src/" + rest
+		}
+		lines[i] = "\t" + file
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isStdFunction reports whether line is a function call of a stack
+// in a package of the standard library, whose import paths don't
+// start with a domain name.
+func isStdFunction(line string) bool {
+	line = strings.TrimPrefix(line, "created by ")
+	if strings.HasPrefix(line, "\t") {
+		return false
+	}
+	if first, _, ok := strings.Cut(line, "/"); ok {
+		return !strings.Contains(first, ".")
+	}
+	pkg, _, ok := strings.Cut(line, ".")
+	return ok && pkg != "main" && !strings.Contains(pkg, " ")
+}
+
+// Normalize replaces the parts of a leak report that vary between runs
+// with placeholders:
+//
+//   - goroutine IDs are numbered in the order they first appear
+//   - hexadecimal values, such as addresses, arguments and offsets,
+//     become "0x?"
+//   - wait durations become "N minutes"
+//   - the locations of frames of the standard library become
+//     "$GOROOT/src/" and the path of their file, without the line
+//
+// It accepts any text, e.g. the errors of [goleak.Find] and
+// [goleak.FindInDump], and the output of [goleak.Pretty].
+func Normalize(report string) string {
+	return newNormalizer().text(report)
+}
+
+// Render renders stacks as a normalized leak report, in the order that
+// goleak reports leaks in: by fingerprint, then by goroutine ID.
+func Render(stacks []stack.Stack) string {
+	stacks = sorted(stacks)
+	var sb strings.Builder
+	for i, s := range stacks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(strings.TrimSuffix(s.String(), "\n") + "\n")
+	}
+	return Normalize(sb.String())
+}
+
+// RenderReport renders r as indented JSON with normalized goroutine
+// IDs and stacks, in the order of its leaks.
+func RenderReport(r goleak.Report) (string, error) {
+	n := newNormalizer()
+	leaks := make([]goleak.LeakedGoroutine, len(r.Leaks))
+	for i, leak := range r.Leaks {
+		leak.ID = n.id(leak.ID)
+		leak.State = n.text(leak.State)
+		leak.CreatorID = n.id(leak.CreatorID)
+		leak.CreatedBy = n.text(leak.CreatedBy)
+		leak.Stack = n.text(leak.Stack)
+		leaks[i] = leak
+	}
+	r.Leaks = leaks
+
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// sorted returns a copy of stacks sorted by fingerprint, then by ID.
+func sorted(stacks []stack.Stack) []stack.Stack {
+	stacks = append([]stack.Stack(nil), stacks...)
+	sort.SliceStable(stacks, func(i, j int) bool {
+		a, b := stacks[i], stacks[j]
+		if fa, fb := a.Fingerprint(), b.Fingerprint(); fa != fb {
+			return fa < fb
+		}
+		return a.ID() < b.ID()
+	})
+	return stacks
+}
+
+// Golden compares got with the contents of the golden file at path and
+// fails t, showing the lines that differ, if they aren't equal. If the
+// GOLEAK_UPDATE_GOLDEN environment variable is 1, it writes got to the
+// file instead, creating its directory if needed.
+func Golden(t TestingT, path, got string) {
+	t.Helper()
+
+	if os.Getenv(_updateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Errorf("update golden file: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Errorf("update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("golden file %v does not exist; run with %v=1 to create it", path, _updateEnv)
+		return
+	}
+	if err != nil {
+		t.Errorf("read golden file: %v", err)
+		return
+	}
+	if string(want) != got {
+		t.Errorf("report differs from golden file %v; run with %v=1 to update it:\n%s",
+			path, _updateEnv, diff(string(want), got))
+	}
+}
+
+// diff lists the lines of want and got that differ, by line number.
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var sb strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		inWant, inGot := i < len(wantLines), i < len(gotLines)
+		if inWant && inGot && wantLines[i] == gotLines[i] {
+			continue
+		}
+		if inWant {
+			fmt.Fprintf(&sb, "-%d: %s\n", i+1, wantLines[i])
+		}
+		if inGot {
+			fmt.Fprintf(&sb, "+%d: %s\n", i+1, gotLines[i])
+		}
+	}
+	return sb.String()
+}
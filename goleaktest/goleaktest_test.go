@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleaktest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _dump = `goroutine 42 [chan receive, 5 minutes]:
+runtime.gopark(0xc000010000?, 0x0?, 0x0?, 0x0?, 0x0?)
+	/usr/local/go/src/runtime/proc.go:435 +0xce
+example.com/app.worker(0xc000020060)
+	/home/user/app/worker.go:10 +0x25
+created by example.com/app.Start in goroutine 1
+	/home/user/app/start.go:5 +0x85
+
+goroutine 17 [IO wait]:
+net/http.(*conn).serve(0xc000130000, {0x6b3f20, 0xc000012345})
+	/usr/local/go/src/net/http/server.go:2000 +0x5c5
+created by net/http.(*Server).Serve in goroutine 42
+	/usr/local/go/src/net/http/server.go:3000 +0x485
+`
+
+func parse(t *testing.T, dump string) []stack.Stack {
+	stacks, err := stack.ParseDump([]byte(dump))
+	require.NoError(t, err)
+	return stacks
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{"goroutine 42 [select]:", "goroutine 1 [select]:"},
+		{"Goroutine 42 in state chan receive, 12 minutes", "Goroutine 1 in state chan receive, N minutes"},
+		{"goroutines 9, 7 blocked; goroutine 9 references it", "goroutines 1, 2 blocked; goroutine 1 references it"},
+		{"main.worker(0xc000020060, {0x1, 0x2})\n\t/app/main.go:10 +0x25", "main.worker(0x?, {0x?, 0x?})\n\t/app/main.go:10 +0x?"},
+		{"runtime.gopark(...)\n\t/opt/go/src/runtime/proc.go:435 +0xce", "runtime.gopark(...)\n\t$GOROOT/src/runtime/proc.go"},
+		{"created by net/http.(*Server).Serve in goroutine 3\n\tC:/Go/src/net/http/server.go:3000", "created by net/http.(*Server).Serve in goroutine 1\n\t$GOROOT/src/net/http/server.go"},
+		{"example.com/app.run()\n\t/src/app/run.go:3", "example.com/app.run()\n\t/src/app/run.go:3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Normalize(tt.give), "Normalize(%q)", tt.give)
+	}
+}
+
+func TestRender(t *testing.T) {
+	stacks := parse(t, _dump)
+	got := Render(stacks)
+	Golden(t, "testdata/render.golden", got)
+
+	reversed := []stack.Stack{stacks[1], stacks[0]}
+	assert.Equal(t, got, Render(reversed), "order of stacks should not matter")
+}
+
+func TestRenderReport(t *testing.T) {
+	got, err := RenderReport(goleak.NewReport(parse(t, _dump)))
+	require.NoError(t, err)
+	Golden(t, "testdata/report.golden", got)
+}
+
+func TestGoldenFindInDump(t *testing.T) {
+	err := goleak.FindInDump([]byte(_dump))
+	require.Error(t, err)
+	Golden(t, "testdata/find.golden", Normalize(err.Error()))
+}
+
+type fakeT struct{ errors []string }
+
+func (*fakeT) Helper() {}
+
+func (ft *fakeT) Errorf(format string, args ...any) {
+	ft.errors = append(ft.errors, fmt.Sprintf(format, args...))
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "report.golden")
+
+	ft := &fakeT{}
+	Golden(ft, path, "a\nb\n")
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "does not exist; run with GOLEAK_UPDATE_GOLDEN=1 to create it")
+
+	t.Setenv(_updateEnv, "1")
+	ft = &fakeT{}
+	Golden(ft, path, "a\nb\n")
+	assert.Empty(t, ft.errors)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(b))
+
+	t.Setenv(_updateEnv, "")
+	Golden(ft, path, "a\nb\n")
+	assert.Empty(t, ft.errors, "matching golden file")
+
+	Golden(ft, path, "a\nc\nd\n")
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "-2: b\n+2: c\n-3: \n+3: d\n+4: \n")
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// groupKind describes a kind of group of goroutines, whose members are
+// started by a Go method and waited on by a Wait method.
+type groupKind struct {
+	name string   // e.g. "errgroup.Group"
+	gos  []string // functions that start members
+	wait string   // function that waits for the members
+}
+
+// Kinds of groups that DiagnoseGroups recognizes.
+var _groupKinds = []groupKind{
+	{
+		name: "errgroup.Group",
+		gos:  []string{"golang.org/x/sync/errgroup.(*Group).Go", "golang.org/x/sync/errgroup.(*Group).TryGo"},
+		wait: "golang.org/x/sync/errgroup.(*Group).Wait",
+	},
+	{
+		// WaitGroup.Go was added in Go 1.25. Members started with
+		// go statements can't be told apart from other goroutines.
+		name: "sync.WaitGroup",
+		gos:  []string{"sync.(*WaitGroup).Go"},
+		wait: "sync.(*WaitGroup).Wait",
+	},
+}
+
+// DiagnoseGroups annotates leaks found by Find, VerifyNone and the like
+// with hints about the errgroup.Group and sync.WaitGroup members among
+// them. Leaked members are paired with the goroutine that started them:
+//
+//   - if it's blocked in Wait, the group was waited on but a member
+//     never returned, and the waiter is reported with the members;
+//   - otherwise, the group was never waited on.
+//
+// Members are goroutines started with errgroup.Group.Go or TryGo, or
+// with sync.WaitGroup.Go, and are paired with the goroutine that
+// started them by its ID, which is only reported since Go 1.21.
+func DiagnoseGroups() Option {
+	return optionFunc(func(opts *opts) {
+		opts.diagnoseGroups = true
+	})
+}
+
+// groupNotes returns hints about the group members among the leaked
+// stacks, if DiagnoseGroups was given.
+func (o *opts) groupNotes(stacks []stack.Stack) string {
+	if !o.diagnoseGroups {
+		return ""
+	}
+	return groupHints(stacks)
+}
+
+// groupMembers are the leaked members of groups of a kind that were
+// started by the same goroutine.
+type groupMembers struct {
+	kind    *groupKind
+	creator int
+	members []stack.Stack
+}
+
+// groupHints pairs the leaked members of groups with the leaked
+// goroutines that started them.
+func groupHints(stacks []stack.Stack) string {
+	byID := make(map[int]stack.Stack, len(stacks))
+	for _, s := range stacks {
+		byID[s.ID()] = s
+	}
+
+	type groupKey struct {
+		kind    *groupKind
+		creator int
+	}
+	groups := make(map[groupKey]*groupMembers)
+	var keys []groupKey
+	for _, s := range stacks {
+		kind := memberKind(s)
+		if kind == nil || s.CreatorID() == 0 {
+			continue
+		}
+		key := groupKey{kind, s.CreatorID()}
+		g, ok := groups[key]
+		if !ok {
+			g = &groupMembers{kind: kind, creator: s.CreatorID()}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.members = append(g.members, s)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].creator < keys[j].creator })
+
+	var sb strings.Builder
+	sb.WriteString("\ngroup hints:\n")
+	for _, key := range keys {
+		g := groups[key]
+		ids := make([]int, len(g.members))
+		for i, s := range g.members {
+			ids[i] = s.ID()
+		}
+		members := goroutineList(ids)
+
+		creator, leaked := byID[g.creator]
+		if !leaked {
+			fmt.Fprintf(&sb, "%v%v still running in the %v started by goroutine %d, which isn't leaked; "+
+				"if it has exited, the group was never waited on.\n",
+				members, isOrAre(len(ids)), g.kind.name, g.creator)
+			continue
+		}
+		caller, ok := callerOf(creator, g.kind.wait)
+		if !ok {
+			fmt.Fprintf(&sb, "%v%v still running in the %v started by goroutine %d, which isn't blocked in Wait, "+
+				"so the group was never waited on:\n", members, isOrAre(len(ids)), g.kind.name, g.creator)
+			sb.WriteString(creator.String())
+			continue
+		}
+		fmt.Fprintf(&sb, "goroutine %d waits on the %v in %v at %v:%d, and %v that it started never returned:\n",
+			g.creator, g.kind.name, caller.Function(), caller.File(), caller.Line(), members)
+		sb.WriteString(creator.String())
+		for _, s := range g.members {
+			sb.WriteString(s.String())
+		}
+	}
+	return sb.String()
+}
+
+// memberKind returns the kind of group that s is a member of,
+// or nil if it isn't a member of a group.
+func memberKind(s stack.Stack) *groupKind {
+	createdBy := s.CreatedBy()
+	for i := range _groupKinds {
+		for _, fn := range _groupKinds[i].gos {
+			if createdBy == fn {
+				return &_groupKinds[i]
+			}
+		}
+	}
+	return nil
+}
+
+// callerOf returns the frame of s that called the function fn, or the
+// frame of fn if it has no caller, if s has fn on its stack.
+func callerOf(s stack.Stack, fn string) (stack.Entry, bool) {
+	entries := s.Entries()
+	for i, entry := range entries {
+		if entry.Function() != fn {
+			continue
+		}
+		if i+1 < len(entries) && !entries[i+1].IsSource {
+			return entries[i+1], true
+		}
+		return entry, true
+	}
+	return stack.Entry{}, false
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnoseGroups(t *testing.T) {
+	waiter := []string{
+		"goroutine 14 [sync.WaitGroup.Wait]:",
+		"sync.runtime_SemacquireWaitGroup(0xc0000140a8?)",
+		"	/usr/local/go/src/runtime/sema.go:110 +0x25",
+		"sync.(*WaitGroup).Wait(0xc0000140a0)",
+		"	/usr/local/go/src/sync/waitgroup.go:118 +0x48",
+		"golang.org/x/sync/errgroup.(*Group).Wait(0xc0000140a0)",
+		"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:56 +0x25",
+		"main.fetchAll()",
+		"	/app/main.go:31 +0x85",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:12 +0x1d",
+		"",
+	}
+	member := func(id, creator string) []string {
+		return []string{
+			"goroutine " + id + " [chan receive]:",
+			"main.fetch()",
+			"	/app/main.go:50 +0x25",
+			"golang.org/x/sync/errgroup.(*Group).Go.func1()",
+			"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:78 +0x56",
+			"created by golang.org/x/sync/errgroup.(*Group).Go in goroutine " + creator,
+			"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:75 +0x96",
+			"",
+		}
+	}
+	server := []string{
+		"goroutine 20 [select]:",
+		"main.serve()",
+		"	/app/server.go:20 +0x25",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:14 +0x1d",
+		"",
+	}
+	waitGroupMember := []string{
+		"goroutine 21 [chan send]:",
+		"main.produce()",
+		"	/app/server.go:40 +0x25",
+		"sync.(*WaitGroup).Go.func1()",
+		"	/usr/local/go/src/sync/waitgroup.go:239 +0x4a",
+		"created by sync.(*WaitGroup).Go in goroutine 20",
+		"	/usr/local/go/src/sync/waitgroup.go:237 +0x73",
+		"",
+	}
+	dump := func(goroutines ...[]string) []byte {
+		var lines []string
+		for _, g := range goroutines {
+			lines = append(lines, g...)
+		}
+		return []byte(strings.Join(lines, "\n"))
+	}
+	hints := func(t *testing.T, err error) string {
+		require.Error(t, err)
+		_, hints, ok := strings.Cut(err.Error(), "\ngroup hints:\n")
+		require.True(t, ok, "no hints in:\n%v", err)
+		return hints
+	}
+
+	t.Run("member never returned", func(t *testing.T) {
+		err := FindInDump(dump(waiter, member("15", "14"), member("16", "14")), DiagnoseGroups())
+		assert.True(t, strings.HasPrefix(hints(t, err),
+			"goroutine 14 waits on the errgroup.Group in main.fetchAll at /app/main.go:31, "+
+				"and goroutines 15, 16 that it started never returned:\n"+
+				"Goroutine 14 in state sync.WaitGroup.Wait, with sync.runtime_SemacquireWaitGroup on top of the stack:\n"),
+			"unexpected hints:\n%v", err)
+	})
+
+	t.Run("never waited on", func(t *testing.T) {
+		err := FindInDump(dump(server, waitGroupMember), DiagnoseGroups())
+		assert.True(t, strings.HasPrefix(hints(t, err),
+			"goroutine 21 is still running in the sync.WaitGroup started by goroutine 20, "+
+				"which isn't blocked in Wait, so the group was never waited on:\n"+
+				"Goroutine 20 in state select, with main.serve on top of the stack:\n"),
+			"unexpected hints:\n%v", err)
+	})
+
+	t.Run("creator not leaked", func(t *testing.T) {
+		err := FindInDump(dump(member("31", "30")), DiagnoseGroups())
+		assert.Equal(t, "goroutine 31 is still running in the errgroup.Group started by goroutine 30, "+
+			"which isn't leaked; if it has exited, the group was never waited on.\n", hints(t, err))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		err := FindInDump(dump(waiter, member("15", "14")))
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "group hints")
+	})
+}
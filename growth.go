@@ -0,0 +1,331 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Number of samples taken within every window by DetectGrowth.
+const _growthSamplesPerWindow = 5
+
+// GrowthReport is sent by DetectGrowth when the number of goroutines
+// sharing a fingerprint grew steadily over a window.
+type GrowthReport struct {
+	// Time at which the window ended.
+	Time time.Time
+	// Window over which the growth was observed.
+	Window time.Duration
+	// Growth lists the fingerprints that grew, largest growth first.
+	Growth []FingerprintGrowth
+}
+
+// FingerprintGrowth describes the growth of goroutines
+// sharing a single fingerprint. See [stack.Stack.Fingerprint].
+type FingerprintGrowth struct {
+	Fingerprint string
+	// Partition is the value of the pprof label given to
+	// [PartitionByLabel] that the goroutines have, if any.
+	Partition string
+	// From is the number of goroutines at the start of the window,
+	// To is the number at the end of it.
+	From, To int
+	// Example is one of the goroutines with this fingerprint.
+	Example stack.Stack
+}
+
+// growthSample holds the number of goroutines per fingerprint
+// at a single point in time.
+type growthSample struct {
+	total    int // all goroutines, including filtered ones
+	counts   map[string]int
+	examples map[string]stack.Stack
+	// partitions holds the counts of each value of the label given to
+	// PartitionByLabel, if any.
+	partitions map[string]growthSample
+}
+
+// DetectGrowth watches the goroutines of the running process
+// and reports fingerprints whose goroutine count increased by at least
+// threshold over window, without decreasing in between.
+//
+// Unlike Find, this does not expect the number of goroutines to be zero,
+// which makes it suitable for long-running servers.
+// Goroutines excluded by the given options are not counted.
+// With [PartitionByLabel], growth is detected for the goroutines of
+// each value of a pprof label apart.
+//
+// A threshold below 1 is treated as 1. It panics if window is too
+// short to take a sample every fifth of it.
+//
+// The returned channel is closed once ctx is done.
+func DetectGrowth(ctx context.Context, window time.Duration, threshold int, options ...Option) <-chan GrowthReport {
+	checkGrowthWindow("DetectGrowth", window)
+	return detectGrowth(ctx, window, threshold, buildOpts(options...))
+}
+
+// checkGrowthWindow validates the window given to option, before the
+// ticker of detectGrowth would panic on it in the background.
+func checkGrowthWindow(option string, window time.Duration) {
+	if window/_growthSamplesPerWindow <= 0 {
+		invalidOption(option, "window %v is too short to take %d samples", window, _growthSamplesPerWindow)
+	}
+}
+
+func detectGrowth(ctx context.Context, window time.Duration, threshold int, opts *opts) <-chan GrowthReport {
+	if threshold < 1 {
+		threshold = 1
+	}
+	var vars *expvar.Map
+	if opts.expvarName != "" {
+		vars = publishedMap(opts.expvarName)
+	}
+
+	reports := make(chan GrowthReport)
+	go func() {
+		defer close(reports)
+
+		self := stack.Current().ID()
+		ticker := time.NewTicker(window / _growthSamplesPerWindow)
+		defer ticker.Stop()
+
+		budget := opts.overheadBudget()
+		start := time.Now()
+		sample := takeGrowthSample(self, opts)
+		budget.spend(start, time.Since(start))
+		sample.publish(vars)
+		samples := []growthSample{sample}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// Over the overhead budget, the previous sample stands in.
+			if start := time.Now(); budget.allow(start) {
+				sample = takeGrowthSample(self, opts)
+				budget.spend(start, time.Since(start))
+			}
+			sample.publish(vars)
+			samples = append(samples, sample)
+			if len(samples) <= _growthSamplesPerWindow {
+				continue
+			}
+
+			growth := sustainedGrowth(samples, threshold)
+			if opts.partitionLabel != "" {
+				growth = partitionedGrowth(samples, threshold)
+			}
+			// The last sample starts the next window.
+			samples = samples[len(samples)-1:]
+
+			if opts.snapshotStore != nil {
+				if err := opts.snapshotStore.Save(samples[0].snapshot(time.Now())); err != nil && opts.logger != nil {
+					opts.logger.Error("goleak: failed to save snapshot", "error", err)
+				}
+			}
+			if len(growth) == 0 {
+				continue
+			}
+
+			report := GrowthReport{Time: time.Now(), Window: window, Growth: growth}
+			if opts.logger != nil {
+				for _, g := range growth {
+					attrs := []any{
+						"fingerprint", g.Fingerprint,
+						"function", g.Example.FirstFunction(),
+						"from", g.From, "to", g.To, "window", window,
+					}
+					if opts.partitionLabel != "" {
+						attrs = append(attrs, opts.partitionLabel, g.Partition)
+					}
+					opts.logger.Warn("goleak: goroutine growth detected", attrs...)
+				}
+			}
+			select {
+			case reports <- report:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return reports
+}
+
+func takeGrowthSample(self int, opts *opts) growthSample {
+	all := opts.stacks()
+	sample := growthSample{
+		total:    len(all),
+		counts:   make(map[string]int),
+		examples: make(map[string]stack.Stack),
+	}
+	stacks := filterStacks(all, self, opts)
+	for _, s := range stacks {
+		sample.add(s)
+	}
+	if opts.partitionLabel != "" {
+		sample.partitions = make(map[string]growthSample)
+		for i, value := range labelValues(stacks, opts.partitionLabel) {
+			part, ok := sample.partitions[value]
+			if !ok {
+				part = growthSample{counts: make(map[string]int), examples: make(map[string]stack.Stack)}
+				sample.partitions[value] = part
+			}
+			part.add(stacks[i])
+		}
+	}
+	return sample
+}
+
+// add counts s in the sample.
+func (s *growthSample) add(st stack.Stack) {
+	fp := st.Fingerprint()
+	s.counts[fp]++
+	if _, ok := s.examples[fp]; !ok {
+		s.examples[fp] = st
+	}
+}
+
+func (s growthSample) snapshot(t time.Time) Snapshot {
+	snap := Snapshot{
+		Time:      t,
+		Counts:    s.counts,
+		Functions: make(map[string]string, len(s.examples)),
+	}
+	for fp, example := range s.examples {
+		snap.Functions[fp] = example.FirstFunction()
+	}
+	return snap
+}
+
+// publish sets the counts of the sample on vars.
+// It does nothing if vars is nil.
+func (s growthSample) publish(vars *expvar.Map) {
+	if vars == nil {
+		return
+	}
+
+	var suspected int
+	leaks := new(expvar.Map).Init()
+	for fp, count := range s.counts {
+		suspected += count
+		leaks.Add(fp, int64(count))
+	}
+
+	total := new(expvar.Int)
+	total.Set(int64(s.total))
+	filtered := new(expvar.Int)
+	filtered.Set(int64(s.total - suspected))
+
+	vars.Set("total", total)
+	vars.Set("filtered", filtered)
+	vars.Set("suspected", leaks)
+}
+
+// _publishMu keeps detectors that share a PublishExpvar name from
+// publishing it twice.
+var _publishMu sync.Mutex
+
+// publishedMap returns the expvar.Map published under name,
+// publishing a new one if it doesn't exist yet.
+func publishedMap(name string) *expvar.Map {
+	_publishMu.Lock()
+	defer _publishMu.Unlock()
+	if m, ok := expvar.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	return expvar.NewMap(name)
+}
+
+// sustainedGrowth returns the fingerprints whose count never decreased
+// across the given samples and grew by at least threshold overall.
+func sustainedGrowth(samples []growthSample, threshold int) []FingerprintGrowth {
+	first, last := samples[0], samples[len(samples)-1]
+
+	var growth []FingerprintGrowth
+	for fp, to := range last.counts {
+		from := first.counts[fp]
+		if to-from < threshold {
+			continue
+		}
+
+		sustained := true
+		for i := 1; i < len(samples); i++ {
+			if samples[i].counts[fp] < samples[i-1].counts[fp] {
+				sustained = false
+				break
+			}
+		}
+		if !sustained {
+			continue
+		}
+
+		growth = append(growth, FingerprintGrowth{
+			Fingerprint: fp,
+			From:        from,
+			To:          to,
+			Example:     last.examples[fp],
+		})
+	}
+
+	sortGrowth(growth)
+	return growth
+}
+
+// partitionedGrowth is like sustainedGrowth, for the goroutines of each
+// partition of the samples apart.
+func partitionedGrowth(samples []growthSample, threshold int) []FingerprintGrowth {
+	var growth []FingerprintGrowth
+	for value := range samples[len(samples)-1].partitions {
+		partition := make([]growthSample, len(samples))
+		for i, sample := range samples {
+			partition[i] = sample.partitions[value]
+		}
+		for _, g := range sustainedGrowth(partition, threshold) {
+			g.Partition = value
+			growth = append(growth, g)
+		}
+	}
+	sortGrowth(growth)
+	return growth
+}
+
+// sortGrowth sorts growth by the number of goroutines added, most first,
+// then by fingerprint and partition.
+func sortGrowth(growth []FingerprintGrowth) {
+	sort.Slice(growth, func(i, j int) bool {
+		gi, gj := growth[i].To-growth[i].From, growth[j].To-growth[j].From
+		if gi != gj {
+			return gi > gj
+		}
+		if growth[i].Fingerprint != growth[j].Fingerprint {
+			return growth[i].Fingerprint < growth[j].Fingerprint
+		}
+		return growth[i].Partition < growth[j].Partition
+	})
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"expvar"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitOn(ch chan struct{}) {
+	<-ch
+}
+
+func idleOn(ch chan struct{}) {
+	<-ch
+}
+
+func TestDetectGrowth(t *testing.T) {
+	defer VerifyNone(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := DetectGrowth(ctx, 50*time.Millisecond, 3)
+
+	done := make(chan struct{})
+	stopSpawning := make(chan struct{})
+	spawned := make(chan struct{})
+	go func() {
+		defer close(spawned)
+		for {
+			select {
+			case <-stopSpawning:
+				return
+			case <-time.After(2 * time.Millisecond):
+				go waitOn(done)
+			}
+		}
+	}()
+
+	var report GrowthReport
+	select {
+	case report = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for growth report")
+	}
+	close(stopSpawning)
+	<-spawned
+
+	require.NotEmpty(t, report.Growth)
+	growth := report.Growth[0]
+	assert.Equal(t, 50*time.Millisecond, report.Window)
+	assert.GreaterOrEqual(t, growth.To-growth.From, 3)
+	assert.Equal(t, "github.com/projectdiscovery/goleak.waitOn", growth.Example.FirstFunction())
+
+	cancel()
+	for range reports {
+		// Drain until DetectGrowth stops.
+	}
+	close(done)
+}
+
+func TestDetectGrowthWindow(t *testing.T) {
+	defer VerifyNone(t)
+
+	for _, window := range []time.Duration{0, 4, -time.Second} {
+		assert.Panics(t, func() { DetectGrowth(context.Background(), window, 1) }, "window %v", window)
+	}
+	assert.PanicsWithError(t, "goleak: DetectGrowth: window 4ns is too short to take 5 samples", func() {
+		DetectGrowth(context.Background(), 4, 1)
+	})
+
+	d := NewDetector()
+	defer d.Stop()
+	assert.PanicsWithError(t, "goleak: Monitor: window 0s is too short to take 5 samples", func() {
+		d.Monitor(context.Background(), 0, 1)
+	})
+}
+
+func TestDetectGrowthExpvar(t *testing.T) {
+	defer VerifyNone(t)
+
+	waitForStable(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := DetectGrowth(ctx, 10*time.Millisecond, 1,
+		IgnoreCurrent(), PublishExpvar("goleak_test_growth"))
+	defer func() {
+		cancel()
+		for range reports {
+		}
+	}()
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	// Poll in this goroutine, which is ignored;
+	// require.Eventually would start goroutines that aren't.
+	var vars *expvar.Map
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		vars, _ = expvar.Get("goleak_test_growth").(*expvar.Map)
+		if vars != nil && vars.Get("suspected") != nil && vars.Get("suspected").String() != "{}" {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "no suspected goroutines were published")
+	}
+
+	total := vars.Get("total").(*expvar.Int).Value()
+	filtered := vars.Get("filtered").(*expvar.Int).Value()
+	suspected := vars.Get("suspected").(*expvar.Map)
+
+	var suspectedCount int64
+	suspected.Do(func(kv expvar.KeyValue) {
+		suspectedCount += kv.Value.(*expvar.Int).Value()
+	})
+	assert.Equal(t, int64(1), suspectedCount, "only the blocked goroutine should be suspected")
+	assert.Equal(t, total, filtered+suspectedCount)
+}
+
+func TestSustainedGrowth(t *testing.T) {
+	sample := func(counts map[string]int) growthSample {
+		return growthSample{counts: counts, examples: map[string]stack.Stack{}}
+	}
+
+	samples := []growthSample{
+		sample(map[string]int{"steady": 1, "spiky": 1, "small": 1}),
+		sample(map[string]int{"steady": 3, "spiky": 5, "small": 1}),
+		sample(map[string]int{"steady": 5, "spiky": 2, "small": 2}),
+		sample(map[string]int{"steady": 5, "spiky": 6, "small": 2, "new": 4}),
+	}
+
+	growth := sustainedGrowth(samples, 3)
+	require.Len(t, growth, 2)
+	assert.Equal(t, "new", growth[0].Fingerprint)
+	assert.Equal(t, 0, growth[0].From)
+	assert.Equal(t, 4, growth[0].To)
+	assert.Equal(t, "steady", growth[1].Fingerprint)
+	assert.Equal(t, 1, growth[1].From)
+	assert.Equal(t, 5, growth[1].To)
+}
+
+func TestDetectGrowthPartitions(t *testing.T) {
+	defer VerifyNone(t)
+
+	done := make(chan struct{})
+	defer close(done)
+	// A few goroutines of job "b" that don't grow. Goroutines are matched
+	// with their labels by the frames of their stacks, so they must be
+	// blocked elsewhere than those of job "a" to be told apart.
+	pprof.Do(context.Background(), pprof.Labels("job", "b"), func(context.Context) {
+		for i := 0; i < 3; i++ {
+			go idleOn(done)
+		}
+	})
+	// Goroutines are only matched with their labels once they're blocked.
+	require.Eventually(t, func() bool {
+		idle := FilterStacks(stack.All(), IncludeTopFunction("github.com/projectdiscovery/goleak.idleOn"))
+		for _, s := range idle {
+			if !isBlocked(s) {
+				return false
+			}
+		}
+		return len(idle) == 3
+	}, 5*time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := DetectGrowth(ctx, 50*time.Millisecond, 3, PartitionByLabel("job"))
+
+	stopSpawning := make(chan struct{})
+	spawned := make(chan struct{})
+	pprof.Do(context.Background(), pprof.Labels("job", "a"), func(context.Context) {
+		go func() {
+			defer close(spawned)
+			for {
+				select {
+				case <-stopSpawning:
+					return
+				case <-time.After(2 * time.Millisecond):
+					go waitOn(done)
+				}
+			}
+		}()
+	})
+
+	var report GrowthReport
+	select {
+	case report = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for growth report")
+	}
+	close(stopSpawning)
+	<-spawned
+
+	require.NotEmpty(t, report.Growth)
+	for _, g := range report.Growth {
+		assert.Equal(t, "a", g.Partition, "only job a should grow")
+	}
+	assert.Equal(t, "github.com/projectdiscovery/goleak.waitOn", report.Growth[0].Example.FirstFunction())
+
+	cancel()
+	for range reports {
+		// Drain until DetectGrowth stops.
+	}
+}
+
+func TestPartitionedGrowth(t *testing.T) {
+	sample := func(partitions map[string]map[string]int) growthSample {
+		s := growthSample{partitions: make(map[string]growthSample)}
+		for value, counts := range partitions {
+			s.partitions[value] = growthSample{counts: counts, examples: map[string]stack.Stack{}}
+		}
+		return s
+	}
+
+	samples := []growthSample{
+		sample(map[string]map[string]int{"a": {"worker": 1}, "b": {"worker": 4}}),
+		sample(map[string]map[string]int{"a": {"worker": 3}, "b": {"worker": 2}}),
+		sample(map[string]map[string]int{"a": {"worker": 5}, "b": {"worker": 5}, "c": {"worker": 3}}),
+	}
+
+	growth := partitionedGrowth(samples, 3)
+	assert.Equal(t, []FingerprintGrowth{
+		{Fingerprint: "worker", Partition: "a", From: 1, To: 5},
+		{Fingerprint: "worker", Partition: "c", From: 0, To: 3},
+	}, growth)
+}
+
+func TestPartitionByLabel(t *testing.T) {
+	assert.Equal(t, "job", buildOpts(PartitionByLabel("job")).partitionLabel)
+	assert.Panics(t, func() { PartitionByLabel("") })
+}
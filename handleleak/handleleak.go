@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package handleleak detects OS file descriptors and, on Windows,
+// HANDLEs that are leaked by tests, such as files, sockets and pipes
+// that are never closed, including those opened by cgo code.
+//
+// Register the tracker once, e.g. in TestMain, and check tests with
+// [goleak.VerifyNoneAll]:
+//
+//	func TestMain(m *testing.M) {
+//		handleleak.Register()
+//		os.Exit(m.Run())
+//	}
+//
+//	func TestFoo(t *testing.T) {
+//		goleak.VerifyNoneAll(t)
+//		// ...
+//	}
+//
+// Descriptors are read from /proc/self/fd on Linux and Android, and
+// from /dev/fd on other Unix systems, so leaks are reported with what
+// they refer to where the system allows it. Windows has no such list,
+// so only the growth of the number of HANDLEs of the process is
+// reported. There are no descriptors to check on js and wasip1.
+//
+// Descriptors are process-wide, so tests that check them should not
+// run in parallel with tests that open descriptors.
+package handleleak
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// Name is the name that Register registers the tracker with.
+const Name = "OS handles"
+
+// Register registers the tracker of OS handles with
+// [goleak.RegisterResourceTracker], so that [goleak.VerifyNoneAll]
+// checks them.
+func Register() {
+	goleak.RegisterResourceTracker(Name, Tracker())
+}
+
+// Tracker returns a [goleak.ResourceTracker] for OS handles.
+func Tracker() goleak.ResourceTracker {
+	return tracker{}
+}
+
+// snapshot is the set of open handles. Where handles can be listed,
+// fds maps each descriptor to what it refers to, if known.
+// Otherwise, fds is nil and only their count is known.
+type snapshot struct {
+	fds   map[int]string
+	count int
+}
+
+// fd is a leaked descriptor.
+type fd struct {
+	num    int
+	target string
+}
+
+// growth is the growth of the number of handles,
+// where they can't be listed.
+type growth struct {
+	before, after int
+}
+
+type tracker struct{}
+
+func (tracker) Snapshot() any {
+	return takeSnapshot()
+}
+
+func (tracker) Diff(before, after any) []any {
+	b, a := before.(snapshot), after.(snapshot)
+	if b.fds == nil || a.fds == nil {
+		if a.count > b.count {
+			return []any{growth{before: b.count, after: a.count}}
+		}
+		return nil
+	}
+
+	var leaked []fd
+	for num, target := range a.fds {
+		// A descriptor that was closed and reused for something else
+		// is a leak too.
+		if old, ok := b.fds[num]; !ok || old != target {
+			leaked = append(leaked, fd{num: num, target: target})
+		}
+	}
+	sort.Slice(leaked, func(i, j int) bool { return leaked[i].num < leaked[j].num })
+	result := make([]any, len(leaked))
+	for i, l := range leaked {
+		result[i] = l
+	}
+	return result
+}
+
+func (tracker) Describe(leaked any) string {
+	switch l := leaked.(type) {
+	case fd:
+		if l.target == "" {
+			return fmt.Sprintf("fd %d", l.num)
+		}
+		return fmt.Sprintf("fd %d (%v)", l.num, l.target)
+	case growth:
+		return fmt.Sprintf("%d handles: %d at the start of the test, %d at the end",
+			l.after-l.before, l.before, l.after)
+	}
+	return fmt.Sprint(leaked)
+}
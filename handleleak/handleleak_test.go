@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package handleleak
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "android" {
+		t.Skip("descriptors are only resolved to files on Linux")
+	}
+
+	tr := Tracker()
+	before := tr.Snapshot()
+	assert.Empty(t, tr.Diff(before, tr.Snapshot()), "snapshots should not see their own descriptors")
+
+	path := filepath.Join(t.TempDir(), "leaked")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+
+	leaked := tr.Diff(before, tr.Snapshot())
+	require.Len(t, leaked, 1)
+	desc := tr.Describe(leaked[0])
+	assert.True(t, strings.HasPrefix(desc, "fd "), "unexpected description %q", desc)
+	assert.Contains(t, desc, "("+path+")")
+
+	require.NoError(t, f.Close())
+	assert.Empty(t, tr.Diff(before, tr.Snapshot()))
+}
+
+func TestDiffCounts(t *testing.T) {
+	tr := Tracker()
+	leaked := tr.Diff(snapshot{count: 10}, snapshot{count: 12})
+	require.Len(t, leaked, 1)
+	assert.Equal(t, "2 handles: 10 at the start of the test, 12 at the end", tr.Describe(leaked[0]))
+
+	assert.Empty(t, tr.Diff(snapshot{count: 12}, snapshot{count: 10}))
+}
+
+func TestDiffReused(t *testing.T) {
+	tr := Tracker()
+	before := snapshot{fds: map[int]string{3: "/tmp/a", 4: "pipe:[1]"}}
+	after := snapshot{fds: map[int]string{3: "/tmp/b", 4: "pipe:[1]", 5: "socket:[2]"}}
+
+	var descs []string
+	for _, l := range tr.Diff(before, after) {
+		descs = append(descs, tr.Describe(l))
+	}
+	assert.Equal(t, []string{"fd 3 (/tmp/b)", "fd 5 (socket:[2])"}, descs)
+}
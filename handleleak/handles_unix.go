@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build unix
+
+package handleleak
+
+import (
+	"os"
+	"strconv"
+)
+
+// takeSnapshot lists the open descriptors of the process.
+func takeSnapshot() snapshot {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		fds := make(map[int]string, len(entries))
+		for _, e := range entries {
+			num, err := strconv.Atoi(e.Name())
+			if err != nil {
+				continue
+			}
+			// The descriptor ReadDir used to read the directory
+			// is closed by now, so it fails to resolve.
+			target, err := os.Readlink(dir + "/" + e.Name())
+			if err != nil && dir == "/proc/self/fd" {
+				continue
+			}
+			fds[num] = target
+		}
+		return snapshot{fds: fds, count: len(fds)}
+	}
+	return snapshot{}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Handler returns an http.Handler that inspects the goroutines of the
+// running process on every request and responds with the goroutines
+// not excluded by the given options.
+//
+// The report is plain text by default, or JSON encoded as a [Report]
+// if the request accepts "application/json".
+// Unlike Find, the handler takes a single snapshot without retrying.
+// Goroutines are listed in the same order as by Find.
+// Goroutines quarantined with [Quarantine] are left out of the report,
+// which lists the counts of [QuarantineStats] instead.
+//
+// The handler may be mounted next to net/http/pprof:
+//
+//	mux.Handle("/debug/goleak", goleak.Handler(
+//		goleak.IgnoreAnyFunction("net/http.(*Server).Serve"),
+//	))
+func Handler(options ...Option) http.Handler {
+	opts := buildOpts(options...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stacks, quarantined := opts.splitQuarantined(filterStacks(opts.stacks(), stack.Current().ID(), opts))
+		sortLeaks(stacks)
+
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			report := opts.report(stacks, leakCheck{})
+			if opts.quarantines() {
+				report.Quarantined = QuarantineStats()
+			}
+			if err := json.NewEncoder(w).Encode(report); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if len(quarantined) > 0 {
+			fmt.Fprintf(w, "%d quarantined goroutines are not shown\n", len(quarantined))
+		}
+		if len(stacks) == 0 {
+			fmt.Fprintln(w, "no unexpected goroutines")
+			return
+		}
+		fmt.Fprintf(w, "found %d unexpected goroutines:\n\n", len(stacks))
+		for _, s := range stacks {
+			fmt.Fprintf(w, "%v\n", s)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	h := Handler()
+
+	t.Run("text", func(t *testing.T) {
+		waitForStable(t)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/goleak", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "found 1 unexpected goroutines")
+		assert.Contains(t, rec.Body.String(), "goleak.(*blockedG).block")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debug/goleak", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		waitForStable(t)
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		require.Len(t, report.Leaks, 1)
+
+		leak := report.Leaks[0]
+		assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", leak.FirstFunction)
+		assert.Equal(t, "chan receive", leak.State)
+		assert.NotEmpty(t, leak.Fingerprint)
+		assert.Contains(t, leak.CreatedBy, "created by github.com/projectdiscovery/goleak.startBlockedG")
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		waitForStable(t)
+		rec := httptest.NewRecorder()
+		Handler(IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/goleak", nil))
+
+		assert.Equal(t, "no unexpected goroutines\n", rec.Body.String())
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// heapStats are the heap statistics compared by VerifyNoHeapGrowth.
+type heapStats struct {
+	inuse   uint64 // bytes
+	objects uint64
+}
+
+// _readHeap is replaced in tests.
+var _readHeap = readHeap
+
+// readHeap collects garbage and reads the heap statistics.
+func readHeap() heapStats {
+	// The first cycle may leave objects with finalizers behind,
+	// which the second one collects.
+	runtime.GC()
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return heapStats{inuse: ms.HeapInuse, objects: ms.HeapObjects}
+}
+
+// grewBeyond reports whether either statistic grew from before by more
+// than the given fraction.
+func (after heapStats) grewBeyond(before heapStats, tolerance float64) bool {
+	return float64(after.inuse) > float64(before.inuse)*(1+tolerance) ||
+		float64(after.objects) > float64(before.objects)*(1+tolerance)
+}
+
+// VerifyNoHeapGrowth marks the given TestingF as failed if the heap
+// grew during the test by more than tolerance, a fraction of its size
+// when VerifyNoHeapGrowth was called, e.g. 0.1 for 10%.
+// Call it at the start of the test:
+//
+//	func TestFoo(t *testing.T) {
+//		goleak.VerifyNoHeapGrowth(t, 0.1)
+//		// ...
+//	}
+//
+// Garbage is collected before both measurements, and both the bytes
+// and the number of objects in use are compared. Like [VerifyNone],
+// the check is retried, to let leftover goroutines finish and release
+// their memory.
+//
+// Leaked goroutines commonly pin memory, so if the heap grew,
+// goroutines started during the test that are still running are
+// reported along with it. Options are interpreted as for [VerifyNone]
+// in that check.
+//
+// It panics if tolerance is negative or NaN.
+func VerifyNoHeapGrowth(t TestingF, tolerance float64, options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+	if !(tolerance >= 0) {
+		invalidOption("VerifyNoHeapGrowth", "tolerance %v must be a non-negative fraction", tolerance)
+	}
+
+	options = append(options, ignoreExisting())
+	opts := buildOpts(options...)
+	opts.capRetries(t)
+	before := _readHeap()
+	t.Cleanup(func() {
+		var after heapStats
+		for i := 0; ; i++ {
+			after = _readHeap()
+			if !after.grewBeyond(before, tolerance) || !opts.retry(i) {
+				break
+			}
+		}
+		if !after.grewBeyond(before, tolerance) {
+			return
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "heap grew by more than %v%%: %d to %d bytes in use, %d to %d objects",
+			tolerance*100, before.inuse, after.inuse, before.objects, after.objects)
+		if stacks := filterStacks(opts.stacks(), stack.Current().ID(), opts); len(stacks) > 0 {
+			fmt.Fprintf(&sb, "\nleaked goroutines may be holding on to memory:\n%s", stacks)
+		}
+		opts.fail(t, errors.New(sb.String()))
+	})
+}
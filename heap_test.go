@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeap(t *testing.T) {
+	stats := readHeap()
+	assert.Positive(t, stats.inuse)
+	assert.Positive(t, stats.objects)
+}
+
+func TestVerifyNoHeapGrowthTolerance(t *testing.T) {
+	assert.PanicsWithError(t, "goleak: VerifyNoHeapGrowth: tolerance -0.1 must be a non-negative fraction", func() {
+		VerifyNoHeapGrowth(&fakeF{}, -0.1)
+	})
+	assert.PanicsWithError(t, "goleak: VerifyNoHeapGrowth: tolerance NaN must be a non-negative fraction", func() {
+		VerifyNoHeapGrowth(&fakeF{}, math.NaN())
+	})
+}
+
+func TestVerifyNoHeapGrowth(t *testing.T) {
+	defer func(orig func() heapStats) { _readHeap = orig }(_readHeap)
+
+	// run runs a test that starts with the given heap
+	// and ends with the other.
+	run := func(tolerance float64, before, after heapStats, during func()) *fakeF {
+		_readHeap = func() heapStats { return before }
+		ff := &fakeF{}
+		VerifyNoHeapGrowth(ff, tolerance, testOptions())
+		if during != nil {
+			during()
+		}
+
+		_readHeap = func() heapStats { return after }
+		for _, f := range ff.cleanups {
+			f()
+		}
+		return ff
+	}
+
+	t.Run("no growth", func(t *testing.T) {
+		assert.Empty(t, run(0, heapStats{1000, 10}, heapStats{1000, 10}, nil).errors)
+	})
+
+	t.Run("within tolerance", func(t *testing.T) {
+		assert.Empty(t, run(0.1, heapStats{1000, 10}, heapStats{1100, 11}, nil).errors)
+	})
+
+	t.Run("bytes grew", func(t *testing.T) {
+		ff := run(0.1, heapStats{1000, 10}, heapStats{1200, 10}, nil)
+		require.Len(t, ff.errors, 1)
+		assert.Equal(t, "heap grew by more than 10%: 1000 to 1200 bytes in use, 10 to 10 objects", ff.errors[0])
+	})
+
+	t.Run("objects grew", func(t *testing.T) {
+		ff := run(0.1, heapStats{1000, 10}, heapStats{1000, 20}, nil)
+		assert.Len(t, ff.errors, 1)
+	})
+
+	t.Run("with leaked goroutines", func(t *testing.T) {
+		var bg *blockedG
+		ff := run(0, heapStats{1000, 10}, heapStats{2000, 20}, func() { bg = startBlockedG() })
+		bg.unblock()
+		require.Len(t, ff.errors, 1)
+		assert.Contains(t, ff.errors[0], "leaked goroutines may be holding on to memory")
+		assert.Contains(t, ff.errors[0], "blockedG")
+	})
+}
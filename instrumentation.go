@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Prefixes of the functions of the packages that the toolchain links
+// into binaries built with -race, -cover or -fuzz. They are matched as
+// prefixes so that they keep matching as the packages change between
+// Go versions.
+var _instrumentationPrefixes = []string{
+	// -race: the race runtime and its Go support code.
+	"runtime/race.",
+	"internal/race.",
+	// -cover: before Go 1.20, testing wrote profiles itself; since then,
+	// coverage counters are written by runtime/coverage and
+	// internal/coverage, e.g. when a test binary flushes them.
+	"testing.coverReport",
+	"testing.(*M).writeProfiles",
+	"runtime/coverage.",
+	"internal/coverage/",
+	"internal/coverage.",
+	// -fuzz: the fuzzing engine and its instrumentation.
+	"internal/fuzz.",
+	"testing/internal/testdeps.(*TestDeps).CoordinateFuzzing",
+	"testing/internal/testdeps.(*TestDeps).RunFuzzWorker",
+}
+
+// IgnoreInstrumentationStacks ignores goroutines that the instrumentation
+// of -race, -cover and -fuzz runs, such as those writing coverage
+// counters or coordinating fuzzing workers. It includes those that
+// [IgnoreFuzzStacks] ignores. It is one of the [DefaultFilters]; remove
+// it with [DisableDefaultFilter] to report them:
+//
+//	goleak.VerifyNone(t, goleak.DisableDefaultFilter(goleak.IgnoreInstrumentationStacks()))
+func IgnoreInstrumentationStacks() Option {
+	return addFilter("IgnoreInstrumentationStacks()", isInstrumentationStack)
+}
+
+func isInstrumentationStack(s stack.Stack) bool {
+	full := s.Full()
+	// Check the raw trace first so that other stacks aren't parsed in full.
+	if !strings.Contains(full, "race") && !strings.Contains(full, "cover") && !strings.Contains(full, "fuzz") &&
+		!strings.Contains(full, "Fuzz") {
+		return false
+	}
+	if isFuzzStack(s) {
+		return true
+	}
+	isInstrumentation := func(name string) bool {
+		for _, prefix := range _instrumentationPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return isInstrumentation(s.CreatedBy()) || s.HasFunctionFunc(isInstrumentation)
+}
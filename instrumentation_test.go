@@ -0,0 +1,82 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreInstrumentationStacks(t *testing.T) {
+	dumps := map[string]string{
+		"coverage": `goroutine 21 [chan receive]:
+internal/coverage/cfile.(*emitState).emitCounterDataFile(0xc000150000, {0xc00001a0c0, 0x20}, {0x5f1e00, 0xc000012078})
+	/usr/local/go/src/internal/coverage/cfile/emit.go:560 +0x25
+internal/coverage/cfile.emitCounterDataToDirectory({0xc000016120, 0x10})
+	/usr/local/go/src/internal/coverage/cfile/emit.go:301 +0x1d5
+created by main.main in goroutine 1
+	/app/main.go:12 +0x1e
+`,
+		"race": `goroutine 9 [select]:
+internal/race.Acquire(0xc000014120)
+	/usr/local/go/src/internal/race/race.go:21 +0x1d
+sync.(*Mutex).Lock(...)
+	/usr/local/go/src/sync/mutex.go:90
+created by runtime/race.init.0 in goroutine 1
+	/usr/local/go/src/runtime/race/race.go:30 +0x1e
+`,
+		"fuzz": `goroutine 12 [select]:
+internal/fuzz.(*worker).coordinate(0xc0001a2000, 0xc000196000)
+	/usr/local/go/src/internal/fuzz/worker.go:149 +0x25
+created by internal/fuzz.CoordinateFuzzing in goroutine 1
+	/usr/local/go/src/internal/fuzz/fuzz.go:141 +0x5e5
+`,
+	}
+
+	for name, dump := range dumps {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, FindInDump([]byte(dump)))
+			assert.Error(t, FindInDump([]byte(dump),
+				DisableDefaultFilter(IgnoreInstrumentationStacks(), IgnoreFuzzStacks())))
+		})
+	}
+
+	t.Run("other goroutines", func(t *testing.T) {
+		dump := `goroutine 7 [chan receive]:
+main.traceRaces()
+	/app/coverage.go:20 +0x25
+created by main.main in goroutine 1
+	/app/main.go:10 +0x1d
+`
+		assert.Error(t, FindInDump([]byte(dump)))
+	})
+}
+
+func TestDisableDefaultFilter(t *testing.T) {
+	opts := buildOpts(DisableDefaultFilter(IgnoreStdLibStacks(), IgnoreTopFunction("main.main")))
+	require.Len(t, opts.defaultFilters, len(builtinFilters())-1)
+	for _, f := range opts.defaultFilters {
+		assert.NotEqual(t, "IgnoreStdLibStacks()", f.name)
+	}
+	assert.Len(t, buildOpts().defaultFilters, len(builtinFilters()), "defaults should not be modified")
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// KnownLeak describes a goroutine that a version of a dependency is
+// known to leak.
+type KnownLeak struct {
+	// Module is the path of the module that leaks the goroutine.
+	Module string `json:"module"`
+	// Function is a function in the stack of the leaked goroutine.
+	Function string `json:"function"`
+	// FixedIn is the first version of Module without the leak,
+	// or empty if all versions leak it.
+	FixedIn string `json:"fixed_in,omitempty"`
+	// Description explains the leak.
+	Description string `json:"description"`
+	// Issue links to the upstream issue, if any.
+	Issue string `json:"issue,omitempty"`
+}
+
+//go:embed knownleaks.json
+var _knownLeaksJSON []byte
+
+var _knownLeaks = sync.OnceValue(func() []KnownLeak {
+	var leaks []KnownLeak
+	if err := json.Unmarshal(_knownLeaksJSON, &leaks); err != nil {
+		panic(fmt.Sprintf("goleak: bad known leaks database: %v", err))
+	}
+	return leaks
+})
+
+// WarnKnownDependencyLeaks annotates leaks found by Find, VerifyNone and
+// the like with what is known about them, for goroutines that versions
+// of dependencies are known to leak. The annotation says in which
+// version the leak was fixed, if it was, and links to the upstream issue.
+//
+// Leaks are matched against a database embedded in goleak,
+// using the version of the dependency that the stack was built with.
+// See [LookupKnownLeak].
+func WarnKnownDependencyLeaks() Option {
+	return optionFunc(func(opts *opts) {
+		opts.warnKnownLeaks = true
+	})
+}
+
+// LookupKnownLeak reports whether s is a goroutine that the dependency
+// it comes from is known to leak, and if so describes the leak.
+//
+// The version of the dependency is taken from the module cache paths
+// in the stack, or else from the build info of the running binary.
+// A leak fixed in a later version matches only if the version is known
+// and older than the fix.
+func LookupKnownLeak(s stack.Stack) (KnownLeak, bool) {
+	for _, leak := range _knownLeaks() {
+		if !s.HasFunction(leak.Function) {
+			continue
+		}
+		if leak.FixedIn == "" {
+			return leak, true
+		}
+		version := dependencyVersion(s, leak.Module)
+		if version != "" && compareVersions(version, leak.FixedIn) < 0 {
+			return leak, true
+		}
+	}
+	return KnownLeak{}, false
+}
+
+// dependencyVersion returns the version of the module used by s,
+// or an empty string if it's unknown.
+func dependencyVersion(s stack.Stack, modulePath string) string {
+	cached := "/pkg/mod/" + escapeModulePath(modulePath) + "@"
+	var version string
+	s.HasFileFunc(func(file string) bool {
+		_, rest, ok := strings.Cut(filepath.ToSlash(file), cached)
+		if ok {
+			version, _, _ = strings.Cut(rest, "/")
+		}
+		return ok
+	})
+	if version != "" {
+		return version
+	}
+
+	info := _buildInfo()
+	if info == nil {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return ""
+}
+
+// knownLeakNotes describes the stacks that are known leaks,
+// if WarnKnownDependencyLeaks was given.
+func (o *opts) knownLeakNotes(stacks []stack.Stack) string {
+	if !o.warnKnownLeaks {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, s := range stacks {
+		leak, ok := LookupKnownLeak(s)
+		if !ok {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\nknown dependency leaks:\n")
+		}
+		fmt.Fprintf(&sb, "goroutine %d: %v: %v", s.ID(), leak.Module, leak.Description)
+		if leak.FixedIn != "" {
+			fmt.Fprintf(&sb, "; fixed in %v, upgrade with: go get %v@%v", leak.FixedIn, leak.Module, leak.FixedIn)
+		}
+		if leak.Issue != "" {
+			fmt.Fprintf(&sb, " (see %v)", leak.Issue)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// compareVersions compares two semantic versions like "v1.2.3",
+// returning -1, 0 or +1. Pre-release versions sort before the release
+// and are compared with each other as strings; build metadata is ignored. Pseudo-versions compare by the version
+// they are based on.
+func compareVersions(a, b string) int {
+	coreA, preA := splitVersion(a)
+	coreB, preB := splitVersion(b)
+	for i := range coreA {
+		if coreA[i] != coreB[i] {
+			if coreA[i] < coreB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion splits a semantic version into its numeric core
+// and its pre-release suffix.
+func splitVersion(v string) (core [3]int, pre string) {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ = strings.Cut(v, "-")
+	for i, part := range strings.SplitN(v, ".", 3) {
+		core[i], _ = strconv.Atoi(part)
+	}
+	return core, pre
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func redisReaperDump(version string) []byte {
+	return []byte(strings.Join([]string{
+		"goroutine 12 [select]:",
+		"github.com/go-redis/redis/v8/internal/pool.(*ConnPool).reaper(0xc0001a2000, 0xdf8475800)",
+		"	/root/go/pkg/mod/github.com/go-redis/redis/v8@" + version + "/internal/pool/pool.go:485 +0xb1",
+		"created by github.com/go-redis/redis/v8/internal/pool.NewConnPool in goroutine 1",
+		"	/root/go/pkg/mod/github.com/go-redis/redis/v8@" + version + "/internal/pool/pool.go:111 +0x2b5",
+		"",
+	}, "\n"))
+}
+
+func TestKnownLeaksDatabase(t *testing.T) {
+	leaks := _knownLeaks()
+	require.NotEmpty(t, leaks)
+	for _, leak := range leaks {
+		assert.NotEmpty(t, leak.Description, "%v", leak.Function)
+		assert.True(t, strings.HasPrefix(leak.Function, leak.Module),
+			"function %v is not in module %v", leak.Function, leak.Module)
+	}
+}
+
+func TestLookupKnownLeak(t *testing.T) {
+	parse := func(dump []byte) stack.Stack {
+		stacks, err := stack.ParseDump(dump)
+		require.NoError(t, err)
+		require.Len(t, stacks, 1)
+		return stacks[0]
+	}
+
+	leak, ok := LookupKnownLeak(parse(redisReaperDump("v8.10.0")))
+	require.True(t, ok)
+	assert.Equal(t, "github.com/go-redis/redis/v8", leak.Module)
+	assert.Equal(t, "v8.11.0", leak.FixedIn)
+
+	_, ok = LookupKnownLeak(parse(redisReaperDump("v8.11.0")))
+	assert.False(t, ok, "fixed version should not match")
+
+	// Leaks that were never fixed match without a version.
+	_, ok = LookupKnownLeak(parse([]byte(strings.Join([]string{
+		"goroutine 5 [select]:",
+		"go.opencensus.io/stats/view.(*worker).start(0xc000124000)",
+		"	/src/go.opencensus.io/stats/view/worker.go:292 +0x9f",
+		"created by go.opencensus.io/stats/view.init.0 in goroutine 1",
+		"	/src/go.opencensus.io/stats/view/worker.go:34 +0x8d",
+		"",
+	}, "\n"))))
+	assert.True(t, ok)
+
+	_, ok = LookupKnownLeak(stack.Current())
+	assert.False(t, ok)
+}
+
+func TestWarnKnownDependencyLeaks(t *testing.T) {
+	dump := redisReaperDump("v8.10.0")
+
+	err := FindInDump(dump)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "known dependency leaks")
+
+	err = FindInDump(dump, WarnKnownDependencyLeaks())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "known dependency leaks:\ngoroutine 12: github.com/go-redis/redis/v8: ")
+	assert.Contains(t, err.Error(), "go get github.com/go-redis/redis/v8@v8.11.0")
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+		{"v1.2.0-rc.1", "v1.2.0", -1},
+		{"v1.2.0", "v1.2.0-rc.1", 1},
+		{"v0.0.0-20240101000000-abcdefabcdef", "v0.1.0", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareVersions(tt.a, tt.b), "compare %v and %v", tt.a, tt.b)
+	}
+}
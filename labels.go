@@ -0,0 +1,281 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _testLabel is the pprof label that Check tags the goroutines
+// of a test with.
+const _testLabel = "goleak.test"
+
+// Goroutines are matched with the records of the goroutine profile
+// by the locations of this many frames from the top of their stacks,
+// since the profile may have fewer frames than goroutine dumps.
+const _labelMatchDepth = 16
+
+// TestingTB is the subset of testing.TB that Check uses.
+type TestingTB interface {
+	TestingF
+
+	Name() string
+}
+
+// Check verifies that the test leaks no goroutines, even if other tests
+// run in parallel with it. Call it at the start of the test:
+//
+//	func TestFoo(t *testing.T) {
+//		t.Parallel()
+//		goleak.Check(t)
+//		// ...
+//	}
+//
+// Check sets the pprof label "goleak.test" to the name of the test on the
+// calling goroutine. Goroutines inherit the labels of the goroutine that
+// starts them, so all goroutines started by the test from then on carry
+// the label. When the test completes, leaks are looked for like with
+// [VerifyNone] among the goroutines labeled with the name of the test;
+// goroutines of other tests are ignored.
+//
+// Check replaces any labels of the test goroutine, and goroutines whose
+// labels are replaced with pprof.Do or pprof.SetGoroutineLabels are
+// not attributed to the test.
+func Check(t TestingTB, options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	name := t.Name()
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(), pprof.Labels(_testLabel, name)))
+	options = append(options, optionFunc(func(opts *opts) {
+		opts.label = fmt.Sprintf("%q:%q", _testLabel, name)
+	}))
+	t.Cleanup(func() {
+		pprof.SetGoroutineLabels(context.Background())
+		VerifyNone(t, options...)
+	})
+}
+
+// PartitionByLabel makes [DetectGrowth] count the goroutines of each
+// value of the pprof label with the given key apart, and report their
+// growth per value in [FingerprintGrowth.Partition]. For services that
+// run many jobs or tenants side by side, this tells which of them leaks
+// when they run the same code:
+//
+//	pprof.Do(ctx, pprof.Labels("job", job.ID), func(ctx context.Context) {
+//		job.Run(ctx)
+//	})
+//
+//	reports := goleak.DetectGrowth(ctx, time.Minute, 10, goleak.PartitionByLabel("job"))
+//
+// Goroutines inherit the labels of the goroutine that starts them.
+// Goroutines without the label are counted together, with an empty
+// partition.
+func PartitionByLabel(key string) Option {
+	if key == "" {
+		invalidOption("PartitionByLabel", "empty label key")
+	}
+	return optionFunc(func(opts *opts) {
+		opts.partitionLabel = key
+	})
+}
+
+// labeled returns the stacks of goroutines that have the given label,
+// formatted like in goroutine profiles, e.g. `"key":"value"`.
+//
+// Goroutine dumps don't include labels, so goroutines are matched with the
+// records of the goroutine profile, which group goroutines by their labels
+// and stacks. If goroutines with the same stack have different labels,
+// as many of them as have the label are kept.
+func labeled(stacks []stack.Stack, label string) []stack.Stack {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil
+	}
+	counts := labeledCounts(buf.Bytes(), label)
+
+	filtered := stacks[:0]
+	for _, s := range stacks {
+		key := stackKey(s)
+		if counts[key] > 0 {
+			counts[key]--
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// labelValues returns the value of the pprof label with the given key
+// of each of the stacks, or "" for goroutines without it. Goroutines
+// are matched with the records of the goroutine profile like by labeled.
+// If goroutines with the same stack have different values, they're
+// assigned in the order of the values.
+func labelValues(stacks []stack.Stack, key string) []string {
+	values := make([]string, len(stacks))
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return values
+	}
+
+	type valueCount struct {
+		value string
+		count int
+	}
+	byStack := make(map[string][]valueCount)
+	for _, rec := range profileRecords(buf.Bytes()) {
+		byStack[rec.key] = append(byStack[rec.key], valueCount{labelValue(rec.labels, key), rec.count})
+	}
+	for _, counts := range byStack {
+		sort.SliceStable(counts, func(i, j int) bool { return counts[i].value < counts[j].value })
+	}
+	for i, s := range stacks {
+		key := stackKey(s)
+		counts := byStack[key]
+		if len(counts) == 0 {
+			continue
+		}
+		values[i] = counts[0].value
+		if counts[0].count--; counts[0].count == 0 {
+			byStack[key] = counts[1:]
+		}
+	}
+	return values
+}
+
+// labelValue returns the value of the label with the given key among
+// labels formatted like in goroutine profiles, or "" if there's none.
+func labelValue(labels, key string) string {
+	if labels == "" {
+		return ""
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(labels), &m); err != nil {
+		return ""
+	}
+	return m[key]
+}
+
+// profileRecord is a record of a goroutine profile:
+// the number of goroutines with the same labels and stack.
+type profileRecord struct {
+	count int
+	// labels are formatted like in the profile,
+	// e.g. `{"goleak.test":"TestFoo", "other":"x"}`.
+	labels string
+	// key is the stackKey of the stack.
+	key string
+}
+
+// labeledCounts parses a goroutine profile in the debug=1 format,
+// and counts the goroutines with the given label by their stackKey.
+func labeledCounts(profile []byte, label string) map[string]int {
+	counts := make(map[string]int)
+	for _, rec := range profileRecords(profile) {
+		if strings.Contains(rec.labels, "{"+label+",") ||
+			strings.Contains(rec.labels, " "+label+",") ||
+			strings.Contains(rec.labels, "{"+label+"}") ||
+			strings.Contains(rec.labels, " "+label+"}") {
+			counts[rec.key] += rec.count
+		}
+	}
+	return counts
+}
+
+// profileRecords parses the records of a goroutine profile in the
+// debug=1 format, which look like:
+//
+//	2 @ 0x449ad1 0x48d09d 0x813a61 0x495761
+//	# labels: {"goleak.test":"TestFoo"}
+//	#	0x813a60	example.com/foo.worker+0x20	/home/user/foo/worker.go:16
+func profileRecords(profile []byte) []profileRecord {
+	var (
+		records   []profileRecord
+		rec       profileRecord
+		locations []string
+	)
+	flush := func() {
+		if rec.count > 0 {
+			rec.key = strings.Join(locations, "\n")
+			records = append(records, rec)
+		}
+		rec, locations = profileRecord{}, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "# labels: "):
+			rec.labels = strings.TrimPrefix(line, "# labels: ")
+		case strings.HasPrefix(line, "#\t"):
+			if len(locations) == _labelMatchDepth {
+				continue
+			}
+			// Fields are separated by one or more tabs:
+			// "#", the PC, the function and its location.
+			fields := strings.FieldsFunc(line, func(r rune) bool { return r == '\t' })
+			if len(fields) == 4 && !strings.HasPrefix(fields[2], "runtime.") {
+				locations = append(locations, fields[3])
+			}
+		default:
+			if n, _, ok := strings.Cut(line, " @ "); ok {
+				flush()
+				fmt.Sscan(n, &rec.count)
+			}
+		}
+	}
+	flush()
+	return records
+}
+
+// stackKey identifies a stack by the locations of its frames
+// up to _labelMatchDepth, to match it with goroutine profiles.
+// Function names are not used since they're formatted differently,
+// e.g. for generic functions, and frames of the runtime are skipped
+// since only goroutine dumps of some goroutines have them.
+func stackKey(s stack.Stack) string {
+	var locations []string
+	for _, entry := range s.Entries() {
+		if entry.IsSource || strings.HasPrefix(entry.FunctionCall, "runtime.") {
+			continue
+		}
+		if len(locations) == _labelMatchDepth {
+			break
+		}
+		loc := strings.TrimPrefix(entry.Location, "\t")
+		loc, _, _ = strings.Cut(loc, " +0x")
+		locations = append(locations, loc)
+	}
+	return strings.Join(locations, "\n")
+}
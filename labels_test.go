@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ensure that TestingTB is a subset of testing.TB.
+var _ = TestingTB((testing.TB)(nil))
+
+type fakeTB struct {
+	fakeF
+
+	name string
+}
+
+func (ft *fakeTB) Name() string { return ft.name }
+
+func TestCheck(t *testing.T) {
+	t.Run("ignores goroutines of other tests", func(t *testing.T) {
+		stop := make(chan struct{})
+		defer close(stop)
+		started := make(chan struct{})
+		go func() {
+			close(started)
+			<-stop
+		}()
+		<-started
+
+		ft := &fakeTB{name: t.Name()}
+		Check(ft, testOptions())
+
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		require.Len(t, ft.cleanups, 1)
+		ft.cleanups[0]()
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "blockedG")
+		assert.NotContains(t, ft.errors[0], "TestCheck")
+	})
+
+	t.Run("no leaks", func(t *testing.T) {
+		other := startBlockedG()
+		defer other.unblock()
+
+		ft := &fakeTB{name: t.Name()}
+		Check(ft, testOptions())
+
+		bg := startBlockedG()
+		bg.unblock()
+
+		require.Len(t, ft.cleanups, 1)
+		ft.cleanups[0]()
+		assert.Empty(t, ft.errors)
+	})
+}
+
+func TestLabeledCounts(t *testing.T) {
+	profile := []byte(`goroutine profile: total 4
+2 @ 0x449ad1 0x813a61 0x495761
+# labels: {"goleak.test":"TestFoo", "other":"x"}
+#	0x449ad0	runtime.gopark+0x10			/usr/lib/go/src/runtime/proc.go:435
+#	0x813a60	example.com/foo.worker+0x20		/home/user/foo/worker.go:16
+
+1 @ 0x449ad1 0x813a61 0x495761
+# labels: {"goleak.test":"TestFooBar"}
+#	0x813a60	example.com/foo.worker+0x20		/home/user/foo/worker.go:16
+
+1 @ 0x813b61 0x495761
+#	0x813b60	example.com/foo.main+0x20		/home/user/foo/main.go:8
+`)
+
+	counts := labeledCounts(profile, `"goleak.test":"TestFoo"`)
+	assert.Equal(t, map[string]int{"/home/user/foo/worker.go:16": 2}, counts)
+}
+
+func TestLabelValues(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	pprof.Do(context.Background(), pprof.Labels("job", "scan-1"), func(context.Context) {
+		go waitOn(done)
+	})
+	go waitOn(done)
+
+	var stacks []stack.Stack
+	require.Eventually(t, func() bool {
+		stacks = FilterStacks(stack.All(), IncludeTopFunction("github.com/projectdiscovery/goleak.waitOn"))
+		return len(stacks) == 2
+	}, time.Second, time.Millisecond)
+	values := labelValues(stacks, "job")
+	assert.ElementsMatch(t, []string{"scan-1", ""}, values)
+
+	assert.Equal(t, "x", labelValue(`{"goleak.test":"TestFoo", "job":"x"}`, "job"))
+	assert.Equal(t, "", labelValue(`{"goleak.test":"TestFoo"}`, "job"))
+	assert.Equal(t, "", labelValue("", "job"))
+}
@@ -23,7 +23,12 @@ package goleak
 import (
 	"errors"
 	"fmt"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/projectdiscovery/goleak/stack"
 )
@@ -33,71 +38,291 @@ type TestingT interface {
 	Error(...interface{})
 }
 
+// Dumps with at least this many goroutines are filtered in parallel.
+const _parallelFilterThreshold = 1024
+
 // filterStacks will filter any stacks excluded by the given opts.
 // filterStacks modifies the passed in stacks slice.
+//
+// Large dumps are filtered by up to GOMAXPROCS goroutines,
+// so filters must be safe for concurrent use.
 func filterStacks(stacks []stack.Stack, skipID int, opts *opts) []stack.Stack {
-	filtered := stacks[:0]
-	for _, stack := range stacks {
-		// Always skip the running goroutine.
-		if stack.ID() == skipID {
-			continue
+	return filterStacksCounting(stacks, skipID, opts, nil)
+}
+
+// filterStacksCounting is like filterStacks, and if counts is not nil,
+// adds the number of stacks excluded by each option to it.
+func filterStacksCounting(stacks []stack.Stack, skipID int, opts *opts, counts map[string]int) []stack.Stack {
+	checking := _dumps.checkingIDs()
+	// excludedBy returns why s is excluded, or "" if it isn't.
+	excludedBy := func(s stack.Stack) string {
+		// Always skip the running goroutine, goroutines running
+		// other leak checks, and the workers of earlier calls that
+		// may still be exiting.
+		if s.ID() == skipID || isFilterWorker(s) || isChecking(checking, s.ID()) {
+			return _excludedAsChecker
 		}
 		// Run any default or user-specified filters.
-		if opts.filter(stack) {
-			continue
+		return opts.excludedBy(s)
+	}
+	exclude := func(reason string) bool {
+		if reason == "" {
+			return false
 		}
-		filtered = append(filtered, stack)
+		if counts != nil && reason != _excludedAsChecker {
+			counts[reason]++
+		}
+		return true
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if len(stacks) < _parallelFilterThreshold || workers < 2 {
+		filtered := stacks[:0]
+		for _, stack := range stacks {
+			if !exclude(excludedBy(stack)) {
+				filtered = append(filtered, stack)
+			}
+		}
+		return opts.allow(filtered, counts)
 	}
-	return filtered
+
+	reasons := make([]string, len(stacks))
+	chunk := (len(stacks) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(stacks); lo += chunk {
+		hi := lo + chunk
+		if hi > len(stacks) {
+			hi = len(stacks)
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				reasons[i] = excludedBy(stacks[i])
+			}
+		}(lo, hi)
+	}
+	wg.Wait()
+
+	filtered := stacks[:0]
+	for i, stack := range stacks {
+		if !exclude(reasons[i]) {
+			filtered = append(filtered, stack)
+		}
+	}
+	return opts.allow(filtered, counts)
 }
 
-// Find looks for extra goroutines, and returns a descriptive error if
-// any are found.
-func Find(options ...Option) error {
-	cur := stack.Current().ID()
+// sortLeaks sorts leaked stacks by fingerprint, then by goroutine ID,
+// so that the order of reports doesn't depend on the order of the dump.
+func sortLeaks(stacks []stack.Stack) {
+	fps := make(map[int]string, len(stacks))
+	for _, s := range stacks {
+		fps[s.ID()] = s.Fingerprint()
+	}
+	sort.SliceStable(stacks, func(i, j int) bool {
+		a, b := stacks[i], stacks[j]
+		if fa, fb := fps[a.ID()], fps[b.ID()]; fa != fb {
+			return fa < fb
+		}
+		return a.ID() < b.ID()
+	})
+}
 
-	opts := buildOpts(options...)
-	if opts.cleanup != nil {
-		return errors.New("Cleanup can only be passed to VerifyNone or VerifyTestMain")
+// _excludedAsChecker is the reason for excluding the goroutines
+// that run the leak check, which are not counted in Stats.
+const _excludedAsChecker = "leak check"
+
+// isChecking reports whether id is in checking.
+func isChecking(checking map[int]struct{}, id int) bool {
+	_, ok := checking[id]
+	return ok
+}
+
+// isFilterWorker reports whether s is a goroutine started by filterStacks.
+func isFilterWorker(s stack.Stack) bool {
+	const filterStacks = "github.com/projectdiscovery/goleak.filterStacks"
+	return strings.Contains(s.Full(), filterStacks) &&
+		strings.HasPrefix(s.CreatedBy(), filterStacks)
+}
+
+// leakCheck is what a leak check learned besides the leaks, for notes
+// and reports. It's kept apart from the options, which may be shared by
+// concurrent checks. The zero value is for checks without attempts.
+type leakCheck struct {
+	// trends are the counts of the attempts of the check.
+	trends *retryTrends
+	// dumpTruncated is whether the last dump hit MaxDumpBytes.
+	dumpTruncated bool
+}
+
+// findLeaks repeatedly captures and filters all goroutines until none
+// remain or the retries are exhausted. It returns the goroutines that
+// remained after the last attempt, if any.
+func findLeaks(cur int, opts *opts) (leaks []stack.Stack, check leakCheck) {
+	defer _dumps.startCheck(cur)()
+
+	if opts.timeline != nil {
+		opts.timeline.Stop()
 	}
-	var stacks []stack.Stack
-	retry := true
+
+	var stats *Stats
+	if opts.onStats != nil {
+		stats = &Stats{}
+		start := time.Now()
+		defer func() {
+			stats.Leaked = len(leaks)
+			stats.Duration = time.Since(start)
+			opts.onStats(*stats)
+		}()
+	}
+
+	var (
+		stacks []stack.Stack
+		stable stability
+		counts map[string]int
+	)
+	check.trends = &retryTrends{}
+	// Filters are credited with the goroutines of the last attempt.
+	defer func() { opts.recordFilterUsage(counts) }()
+
+	retry := opts.interrupted() == nil
 	for i := 0; retry; i++ {
-		stacks = filterStacks(stack.All(), cur, opts)
+		var all []stack.Stack
+		all, check.dumpTruncated = opts.dump()
+		counts = make(map[string]int)
+		if stats != nil {
+			*stats = Stats{Scanned: len(all), Filtered: counts, Retries: i}
+		}
+		stacks = filterStacksCounting(all, cur, opts, counts)
+		check.trends.observe(stacks)
 
 		if len(stacks) == 0 {
-			return nil
+			return nil, check
+		}
+		if opts.failFast(stacks) {
+			if opts.logger != nil {
+				opts.logger.Debug("goleak: leaked goroutines can never exit, not retrying",
+					"remaining", len(stacks))
+			}
+			break
+		}
+		if opts.stable(&stable, i, stacks) {
+			if opts.logger != nil {
+				opts.logger.Debug("goleak: leaked goroutines didn't change, not retrying",
+					"attempt", i+1, "remaining", len(stacks))
+			}
+			break
+		}
+		if i < opts.maxRetries {
+			if opts.logger != nil {
+				opts.logger.Debug("goleak: found unexpected goroutines, retrying",
+					"attempt", i+1, "remaining", len(stacks))
+			}
+			if opts.onRetry != nil {
+				opts.onRetry(i+1, stacks)
+			}
 		}
 		retry = opts.retry(i)
 	}
 
-	return fmt.Errorf("found unexpected goroutines:\n%s", stacks)
+	if opts.interrupted() != nil {
+		return nil, check
+	}
+	if stacks = opts.quarantineLeaks(stacks); len(stacks) == 0 {
+		return nil, check
+	}
+	sortLeaks(stacks)
+	if opts.logger != nil {
+		opts.logger.Warn("goleak: found leaked goroutines", "count", len(stacks))
+	}
+	if opts.onLeak != nil {
+		opts.onLeak(stacks)
+	}
+	if opts.onLeakReport != nil {
+		opts.onLeakReport(opts.report(stacks, check))
+	}
+	opts.alert(stacks)
+	return stacks, check
+}
+
+// Find looks for extra goroutines, and returns a descriptive error if
+// any are found. Leaks are reported sorted by fingerprint, then by ID,
+// so that reports of the same leaks compare equal across runs.
+func Find(options ...Option) error {
+	return findPlain(stack.Current().ID(), buildOpts(options...))
+}
+
+func findPlain(cur int, opts *opts) error {
+	if opts.cleanup != nil {
+		return errors.New("Cleanup can only be passed to VerifyNone or VerifyTestMain")
+	}
+	stacks, check := findLeaks(cur, opts)
+	if err := opts.interrupted(); err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		return opts.truncatedDumpError(check)
+	}
+	if opts.timeline != nil {
+		return fmt.Errorf("found unexpected goroutines:\n%s\n%s%s", opts.display(stacks), opts.timeline, opts.notes(stacks, check))
+	}
+	return fmt.Errorf("found unexpected goroutines:\n%s%s", opts.display(stacks), opts.notes(stacks, check))
+}
+
+// notes returns hints about the leaked stacks found by check
+// to append to the error.
+func (o *opts) notes(stacks []stack.Stack, check leakCheck) string {
+	return deadlockHints(stacks) + channelHints(stacks) + o.knownLeakNotes(stacks) + o.snapshotNotes(stacks) +
+		trendNotes(stacks, check.trends) + o.groupNotes(stacks) + o.poolNotes() + o.teardownNotes() +
+		o.truncatedDumpNote(check)
+}
+
+// truncatedDumpError returns an error if the last dump of a leak check
+// that found no leaks was cut off by MaxDumpBytes, since goroutines
+// that didn't fit weren't checked.
+func (o *opts) truncatedDumpError(check leakCheck) error {
+	if !check.dumpTruncated {
+		return nil
+	}
+	return fmt.Errorf("goroutine dump exceeded MaxDumpBytes(%d), "+
+		"so goroutines that didn't fit in it weren't checked", o.maxDumpBytes)
+}
+
+// truncatedDumpNote notes that a leak check that found leaks
+// may have missed others because its dump was cut off.
+func (o *opts) truncatedDumpNote(check leakCheck) string {
+	if !check.dumpTruncated {
+		return ""
+	}
+	return fmt.Sprintf("\ngoroutine dump exceeded MaxDumpBytes(%d); goroutines that didn't fit in it weren't checked\n",
+		o.maxDumpBytes)
 }
 
 // FindAndPrettyPrint looks for extra goroutines, and returns a descriptive error if
 // any are found. It will also print a pretty graph of the goroutines.
 func FindAndPrettyPrint(options ...Option) error {
-	cur := stack.Current().ID()
+	return findPretty(stack.Current().ID(), buildOpts(options...))
+}
 
-	opts := buildOpts(options...)
+func findPretty(cur int, opts *opts) error {
 	if opts.cleanup != nil {
 		return errors.New("Cleanup can only be passed to VerifyNone or VerifyTestMain")
 	}
-	var stacks []stack.Stack
-	retry := true
-	for i := 0; retry; i++ {
-		stacks = filterStacks(stack.All(), cur, opts)
-
-		if len(stacks) == 0 {
-			return nil
-		}
-		retry = opts.retry(i)
+	stacks, check := findLeaks(cur, opts)
+	if err := opts.interrupted(); err != nil {
+		return err
 	}
-
 	if len(stacks) == 0 {
-		return nil
+		return opts.truncatedDumpError(check)
 	}
 
+	return errors.New(prettyPrint(stacks, opts, check))
+}
+
+// prettyPrint renders the given leaked stacks found by check with a
+// dependency graph and colors for FindAndPrettyPrint.
+func prettyPrint(stacks []stack.Stack, opts *opts, check leakCheck) string {
 	var sb strings.Builder
 	// sb.WriteString(" [-] found unexpected goroutines:\n")
 
@@ -105,10 +330,18 @@ func FindAndPrettyPrint(options ...Option) error {
 	dependency := map[int]int{}
 	defs := map[int]stack.Entry{}
 
-	for _, s := range stacks {
+	files := make(sourceFiles)
+	for i, s := range opts.display(stacks) {
 		dependency[s.ID()] = s.SourceGoroutineID()
 		defs[s.ID()] = s.SourceEntry()
-		sb.WriteString(s.PrettyPrint(opts.filters...))
+		sb.WriteString(s.PrettyPrint(opts.filterFuncs()...))
+		if opts.sourceLines > 0 {
+			// Use the full stack, which may have frames that were trimmed.
+			if src := opts.sourceContext(stacks[i], files); src != "" {
+				sb.WriteString(src + "\n")
+			}
+		}
+		writePermalinks(&sb, opts.permalinks(stacks[i]))
 	}
 
 	g := &strings.Builder{}
@@ -122,7 +355,76 @@ func FindAndPrettyPrint(options ...Option) error {
 	g.WriteString("\n-> " + stack.Colors.BrightMagenta("Goroutines").String() + ":\n\n")
 	g.WriteString(sb.String())
 
-	return fmt.Errorf(g.String())
+	if opts.timeline != nil {
+		g.WriteString("-> " + stack.Colors.BrightMagenta("Timeline").String() + ":\n\n")
+		g.WriteString(opts.timeline.String())
+	}
+	g.WriteString(opts.notes(stacks, check))
+
+	return g.String()
+}
+
+// FindInDump looks for unexpected goroutines in a goroutine dump,
+// and returns a descriptive error if any are found.
+// The dump must be in the format produced by runtime.Stack with all set,
+// which is also used by debug/pprof/goroutine?debug=2.
+//
+// Since a dump cannot change, no retries are made.
+// The error is pretty-printed if the Pretty option is given.
+func FindInDump(dump []byte, options ...Option) error {
+	stacks, err := stack.ParseDump(dump)
+	if err != nil {
+		return fmt.Errorf("parse goroutine dump: %w", err)
+	}
+	return findInStacks(stacks, buildOpts(options...))
+}
+
+// FilterStacks returns the stacks that the filters of a leak check
+// with the given options don't exclude, i.e. the goroutines that
+// [Find] would report. It applies the filters to stacks that were
+// already captured, e.g. with [stack.All] or [stack.ParseDump],
+// without taking a new dump, for tools that replay or analyze them.
+//
+// Like [FindInDump], it doesn't retry, and unlike [Find], it doesn't
+// exclude the calling goroutine. Options that don't filter goroutines,
+// such as [Pretty] or [Cleanup], have no effect; so does [Quarantine],
+// whose goroutines are left in the result.
+// The given slice is not modified.
+func FilterStacks(stacks []stack.Stack, options ...Option) []stack.Stack {
+	return filterStacks(slices.Clone(stacks), 0, buildOpts(options...))
+}
+
+// findInStacks implements FindInDump for parsed stacks.
+func findInStacks(stacks []stack.Stack, opts *opts) error {
+	if opts.cleanup != nil {
+		return errors.New("Cleanup can only be passed to VerifyNone or VerifyTestMain")
+	}
+	counts := make(map[string]int)
+	if opts.onStats != nil {
+		start, scanned := time.Now(), len(stacks)
+		defer func() {
+			opts.onStats(Stats{
+				Scanned:  scanned,
+				Filtered: counts,
+				Leaked:   len(stacks),
+				Duration: time.Since(start),
+			})
+		}()
+	}
+	stacks = filterStacksCounting(stacks, 0, opts, counts)
+	opts.recordFilterUsage(counts)
+	if stacks = opts.quarantineLeaks(stacks); len(stacks) == 0 {
+		return nil
+	}
+	sortLeaks(stacks)
+	if opts.onLeak != nil {
+		opts.onLeak(stacks)
+	}
+	opts.alert(stacks)
+	if opts.pretty {
+		return errors.New(prettyPrint(stacks, opts, leakCheck{}))
+	}
+	return fmt.Errorf("found unexpected goroutines:\n%s%s", opts.display(stacks), opts.notes(stacks, leakCheck{}))
 }
 
 type testHelper interface {
@@ -140,23 +442,129 @@ type testHelper interface {
 // goroutines from other tests running in parallel could fail this check.
 // If you need to run tests in parallel, use [VerifyTestMain] instead,
 // which will verify that no leaking goroutines exist after ALL tests finish.
+//
+// If t has a deadline, like testing.T with go test -timeout,
+// VerifyNone stops retrying shortly before it so that leaks are
+// reported rather than the test timing out.
 func VerifyNone(t TestingT, options ...Option) {
-	opts := buildOpts(options...)
+	if h, ok := t.(testHelper); ok {
+		// Mark this function as a test helper, if available.
+		h.Helper()
+	}
+
+	verifyNone(t, buildOpts(options...))
+}
+
+// verifyNone implements VerifyNone and VerifyOnly.
+func verifyNone(t TestingT, opts *opts) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	opts.capRetries(t)
 	var cleanup func(int)
 	cleanup, opts.cleanup = opts.cleanup, nil
 
+	if err := find(opts); err != nil {
+		opts.fail(t, err)
+	}
+
+	if cleanup != nil {
+		cleanup(0)
+	}
+}
+
+// FindOnly looks for extra goroutines among those matching the Include
+// options, such as [IncludeAllContainingPkg], and returns a descriptive
+// error if any are found. All other goroutines are ignored, which lets
+// library authors check that their packages leak nothing regardless
+// of what the host program runs:
+//
+//	err := goleak.FindOnly(goleak.IncludeAllContainingPkg("example.com/mylib"))
+//
+// Options set with [SetDefaults] or selected with GOLEAK_PROFILE
+// are not applied, but the [DefaultFilters] are.
+// At least one Include option must be given.
+func FindOnly(options ...Option) error {
+	opts := buildOnlyOpts(options...)
+	if len(opts.includes) == 0 {
+		return errors.New("FindOnly requires at least one Include option")
+	}
+	return find(opts)
+}
+
+// VerifyOnly marks the given TestingT as failed if any extra goroutines
+// are found by [FindOnly].
+func VerifyOnly(t TestingT, options ...Option) {
 	if h, ok := t.(testHelper); ok {
-		// Mark this function as a test helper, if available.
 		h.Helper()
 	}
 
+	opts := buildOnlyOpts(options...)
+	if len(opts.includes) == 0 {
+		t.Error(errors.New("VerifyOnly requires at least one Include option"))
+		return
+	}
+	verifyNone(t, opts)
+}
+
+// VerifyGone marks the given TestingT as failed if any goroutine that has
+// the function f anywhere in its stack is still running, regardless of
+// other goroutines. It checks that a specific worker has shut down,
+// without requiring that the test leaks nothing else:
+//
+//	srv.Close()
+//	goleak.VerifyGone(t, "example.com/server.(*Server).worker")
+//
+// The function name should be fully qualified. Like [VerifyOnly],
+// VerifyGone retries to let the goroutines exit, and doesn't apply
+// options set with [SetDefaults] or selected with GOLEAK_PROFILE.
+func VerifyGone(t TestingT, f string, options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	include, err := CheckedOption(func() Option { return IncludeAnyFunction(f) })
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	verifyNone(t, buildOnlyOpts(append(options, include)...))
+}
+
+// find looks for extra goroutines with Find or FindAndPrettyPrint,
+// depending on whether the Pretty option was given.
+func find(opts *opts) error {
+	cur := stack.Current().ID()
 	if opts.pretty {
-		if err := FindAndPrettyPrint(opts); err != nil {
-			t.Error(err)
-		}
-	} else {
-		if err := Find(opts); err != nil {
-			t.Error(err)
+		return findPretty(cur, opts)
+	}
+	return findPlain(cur, opts)
+}
+
+// Verify looks for extra goroutines like [Find] and calls the function
+// given with [FailWith] if any are found. Without FailWith, Verify panics.
+//
+// Unlike [VerifyNone], Verify does not need a TestingT,
+// so it can be used outside of tests, e.g. at the end of main
+// in example binaries or long-running checks:
+//
+//	defer goleak.Verify(goleak.FailWith(func(err error) {
+//		log.Printf("leaked goroutines: %v", err)
+//	}))
+func Verify(options ...Option) {
+	opts := buildOpts(options...)
+	var cleanup func(int)
+	cleanup, opts.cleanup = opts.cleanup, nil
+
+	if err := find(opts); err != nil {
+		switch {
+		case opts.reportOnly:
+			opts.reportLeaks(nil, err)
+		case opts.failWith != nil:
+			opts.failWith(err)
+		default:
+			panic(err)
 		}
 	}
 
@@ -164,3 +572,98 @@ func VerifyNone(t TestingT, options ...Option) {
 		cleanup(0)
 	}
 }
+
+// TestingB is the minimal subset of testing.B that we use.
+type TestingB interface {
+	TestingT
+
+	StartTimer()
+	StopTimer()
+	Cleanup(func())
+}
+
+// Benchmarks run many times, so their leak checks wait at most this long
+// between retries to keep the total time spent checking small.
+const _benchmarkMaxSleep = time.Millisecond
+
+// VerifyNoneB marks the given benchmark as failed if it leaves extra
+// goroutines behind. Call it at the start of the benchmark:
+//
+//	func BenchmarkFoo(b *testing.B) {
+//		goleak.VerifyNoneB(b)
+//		for i := 0; i < b.N; i++ {
+//			// ...
+//		}
+//	}
+//
+// Goroutines that are running when VerifyNoneB is called are ignored.
+// The check runs once the benchmark function returns, with the timer
+// stopped, and retries with much shorter sleeps than [VerifyNone]
+// so that it does not slow benchmarks down.
+func VerifyNoneB(b TestingB, options ...Option) {
+	if h, ok := b.(testHelper); ok {
+		h.Helper()
+	}
+
+	b.StopTimer()
+	defer b.StartTimer()
+
+	options = append([]Option{maxSleep(_benchmarkMaxSleep)}, options...)
+	options = append(options, ignoreExisting())
+	b.Cleanup(func() {
+		b.StopTimer()
+		VerifyNone(b, options...)
+	})
+}
+
+// TestingF is the minimal subset of testing.F that we use.
+// testing.T and testing.B satisfy it too.
+type TestingF interface {
+	TestingT
+
+	Cleanup(func())
+}
+
+// VerifyNoneF marks the given fuzz test as failed if it leaves extra
+// goroutines behind once it completes. Call it at the start of the
+// fuzz test:
+//
+//	func FuzzFoo(f *testing.F) {
+//		goleak.VerifyNoneF(f)
+//		f.Fuzz(func(t *testing.T, b []byte) {
+//			defer goleak.VerifyNone(t)
+//			// ...
+//		})
+//	}
+//
+// Goroutines that are running when VerifyNoneF is called are ignored,
+// as are goroutines of the fuzzing engine, which lets [VerifyNone]
+// be used inside fuzz targets too.
+func VerifyNoneF(f TestingF, options ...Option) {
+	if h, ok := f.(testHelper); ok {
+		h.Helper()
+	}
+
+	options = append(options, ignoreExisting())
+	f.Cleanup(func() {
+		VerifyNone(f, options...)
+	})
+}
+
+// VerifyWithin runs fn and marks the given TestingT as failed if fn left
+// new goroutines behind, retrying like [VerifyNone] until they exit.
+// Goroutines that were running before fn was called are ignored,
+// which pinpoints leaks to a single operation of a larger test:
+//
+//	goleak.VerifyWithin(t, func() {
+//		client.Close()
+//	})
+func VerifyWithin(t TestingT, fn func(), options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	options = append(options, ignoreExisting())
+	fn()
+	VerifyNone(t, options...)
+}
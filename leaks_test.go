@@ -22,10 +22,12 @@ package goleak
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/projectdiscovery/goleak/stack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,6 +76,59 @@ func TestFindRetry(t *testing.T) {
 	require.NoError(t, Find(), "Find should retry while background goroutine ends")
 }
 
+func TestFindHooks(t *testing.T) {
+	t.Run("OnRetry and OnLeak are called for leaks", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		var (
+			attempts []int
+			leaked   []stack.Stack
+		)
+		err := Find(
+			testOptions(),
+			StableAfter(0),
+			OnRetry(func(attempt int, remaining []stack.Stack) {
+				assert.NotEmpty(t, remaining, "retry should only happen with remaining goroutines")
+				attempts = append(attempts, attempt)
+			}),
+			OnLeak(func(leaks []stack.Stack) {
+				leaked = leaks
+			}),
+		)
+		require.Error(t, err)
+
+		require.Len(t, attempts, _defaultRetries)
+		for i, attempt := range attempts {
+			assert.Equal(t, i+1, attempt, "attempts should be numbered sequentially")
+		}
+		require.Len(t, leaked, 1)
+		assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", leaked[0].FirstFunction())
+	})
+
+	t.Run("OnLeak is not called without leaks", func(t *testing.T) {
+		err := Find(
+			OnLeak(func([]stack.Stack) { assert.Fail(t, "OnLeak should not be called") }),
+		)
+		require.NoError(t, err)
+	})
+}
+
+func TestFindFailFast(t *testing.T) {
+	waitForStable(t)
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	var retries int
+	err := Find(
+		FailFastStates("chan receive"),
+		OnRetry(func(int, []stack.Stack) { retries++ }),
+	)
+	require.Error(t, err, "Should find leaks with leaked goroutine")
+	assert.ErrorContains(t, err, "blockedG")
+	assert.Zero(t, retries, "Should not retry for fail-fast states")
+}
+
 type fakeT struct {
 	errors []string
 }
@@ -103,6 +158,19 @@ func TestVerifyNone(t *testing.T) {
 		}))
 		require.True(t, cleanupCalled, "expect cleanup registered callback to be called")
 	})
+
+	t.Run("all cleanup callbacks should be called in order", func(t *testing.T) {
+		ft := &fakeT{}
+		var calls []string
+		record := func(name string) Option {
+			return Cleanup(func(c int) {
+				assert.Equal(t, 0, c)
+				calls = append(calls, name)
+			})
+		}
+		VerifyNone(ft, record("first"), record("second"), record("third"))
+		assert.Equal(t, []string{"first", "second", "third"}, calls)
+	})
 }
 
 func TestIgnoreCurrent(t *testing.T) {
@@ -201,3 +269,389 @@ func TestVerifyParallel(t *testing.T) {
 		VerifyNone(t)
 	})
 }
+
+func TestFindSharedOptions(t *testing.T) {
+	bg := startBlockedG()
+	defer func() {
+		bg.unblock()
+		// Wait for it to exit, so later tests don't see it.
+		require.NoError(t, Find())
+	}()
+
+	// Checks that share options, like those of a Handler,
+	// keep what they learn while retrying to themselves.
+	opts := buildOpts(testOptions())
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = findPlain(stack.Current().ID(), opts)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "retry trends:")
+	}
+}
+
+func TestFindInDump(t *testing.T) {
+	dump := []byte(strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.main()",
+		"	/app/main.go:10 +0x1",
+		"",
+		"goroutine 7 [chan receive]:",
+		"main.worker()",
+		"	/app/main.go:42 +0x25",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:20 +0x85",
+		"",
+	}, "\n"))
+
+	err := FindInDump(dump, IgnoreTopFunction("main.main"))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "main.worker")
+
+	err = FindInDump(dump, IgnoreTopFunction("main.main"), Pretty())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "Dependency Graph")
+
+	assert.NoError(t, FindInDump(dump, IgnoreTopFunction("main.main"), IgnoreTopFunction("main.worker")))
+
+	assert.ErrorContains(t, FindInDump([]byte("goroutine x [running]:\n")), "parse goroutine dump")
+}
+
+func TestFilterStacks(t *testing.T) {
+	stacks, err := stack.ParseDump([]byte(strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.main()",
+		"	/app/main.go:10 +0x1",
+		"",
+		"goroutine 7 [chan receive]:",
+		"main.worker()",
+		"	/app/main.go:42 +0x25",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:20 +0x85",
+		"",
+		"goroutine 8 [IO wait]:",
+		"internal/poll.runtime_pollWait(0x7f0000000000, 0x72)",
+		"	/usr/local/go/src/runtime/netpoll.go:345 +0x85",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:21 +0x85",
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+	require.Len(t, stacks, 3)
+
+	filtered := FilterStacks(stacks, IgnoreTopFunction("main.main"))
+	require.Len(t, filtered, 2)
+	assert.Equal(t, 7, filtered[0].ID())
+	assert.Equal(t, 8, filtered[1].ID())
+
+	filtered = FilterStacks(stacks, IgnoreTopFunction("main.main"), IgnoreAnyFunction("internal/poll.runtime_pollWait"))
+	require.Len(t, filtered, 1)
+	assert.Equal(t, 7, filtered[0].ID())
+
+	assert.Empty(t, FilterStacks(stacks, IncludeTopFunction("main.other")))
+	assert.Equal(t, []int{1, 7, 8}, []int{stacks[0].ID(), stacks[1].ID(), stacks[2].ID()},
+		"the given stacks should not be modified")
+
+	t.Run("current goroutines", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		filtered := FilterStacks(stack.All(), IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+		require.Len(t, filtered, 1)
+		assert.Equal(t, "chan receive", filtered[0].State())
+	})
+}
+
+// Ensure that TestingB is a subset of testing.B.
+var _ = TestingB((*testing.B)(nil))
+
+type fakeB struct {
+	fakeT
+
+	timerOn  bool
+	cleanups []func()
+}
+
+func (fb *fakeB) StartTimer()      { fb.timerOn = true }
+func (fb *fakeB) StopTimer()       { fb.timerOn = false }
+func (fb *fakeB) Cleanup(f func()) { fb.cleanups = append(fb.cleanups, f) }
+
+func (fb *fakeB) runCleanups() {
+	for _, f := range fb.cleanups {
+		f()
+	}
+}
+
+func TestVerifyNoneB(t *testing.T) {
+	t.Run("ignores existing goroutines", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		fb := &fakeB{timerOn: true}
+		VerifyNoneB(fb)
+		assert.True(t, fb.timerOn, "timer should be restarted")
+		require.Len(t, fb.cleanups, 1)
+
+		fb.runCleanups()
+		assert.Empty(t, fb.errors)
+		assert.False(t, fb.timerOn, "timer should be stopped while checking")
+	})
+
+	t.Run("finds leaks quickly", func(t *testing.T) {
+		fb := &fakeB{timerOn: true}
+		VerifyNoneB(fb)
+
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		start := time.Now()
+		fb.runCleanups()
+		assert.Less(t, time.Since(start), 500*time.Millisecond, "check should not use the default retry delays")
+		require.Len(t, fb.errors, 1)
+		assert.Contains(t, fb.errors[0], "blockedG")
+	})
+}
+
+// Ensure that TestingF is a subset of testing.F.
+var _ = TestingF((*testing.F)(nil))
+
+type fakeF struct {
+	fakeT
+
+	cleanups []func()
+}
+
+func (ff *fakeF) Cleanup(f func()) { ff.cleanups = append(ff.cleanups, f) }
+
+func TestVerifyNoneF(t *testing.T) {
+	ff := &fakeF{}
+	VerifyNoneF(ff, testOptions())
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	require.Len(t, ff.cleanups, 1)
+	ff.cleanups[0]()
+	require.Len(t, ff.errors, 1)
+	assert.Contains(t, ff.errors[0], "blockedG")
+}
+
+func FuzzVerifyNone(f *testing.F) {
+	VerifyNoneF(f)
+
+	f.Add(3)
+	f.Fuzz(func(t *testing.T, n int) {
+		defer VerifyNone(t)
+
+		var wg sync.WaitGroup
+		for i := 0; i < n%10; i++ {
+			wg.Add(1)
+			go wg.Done()
+		}
+		wg.Wait()
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("no leaks", func(t *testing.T) {
+		assert.NotPanics(t, func() { Verify() })
+	})
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	t.Run("panics by default", func(t *testing.T) {
+		assert.Panics(t, func() { Verify(testOptions()) })
+	})
+
+	t.Run("FailWith", func(t *testing.T) {
+		var got error
+		Verify(testOptions(), FailWith(func(err error) { got = err }))
+		require.Error(t, got)
+		assert.ErrorContains(t, got, "blockedG")
+	})
+
+	t.Run("VerifyNone with FailWith", func(t *testing.T) {
+		ft := &fakeT{}
+		var got error
+		VerifyNone(ft, testOptions(), FailWith(func(err error) { got = err }))
+		assert.Empty(t, ft.errors, "TestingT should not be used with FailWith")
+		assert.ErrorContains(t, got, "blockedG")
+	})
+}
+
+func TestVerifyWithin(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	t.Run("existing goroutines are ignored", func(t *testing.T) {
+		ft := &fakeT{}
+		VerifyWithin(ft, func() {}, testOptions())
+		assert.Empty(t, ft.errors)
+	})
+
+	t.Run("goroutines that exit are not leaks", func(t *testing.T) {
+		ft := &fakeT{}
+		VerifyWithin(ft, func() {
+			done := make(chan struct{})
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				close(done)
+			}()
+		})
+		assert.Empty(t, ft.errors)
+	})
+
+	t.Run("new goroutines are leaks", func(t *testing.T) {
+		var inner *blockedG
+		ft := &fakeT{}
+		VerifyWithin(ft, func() {
+			inner = startBlockedG()
+		}, testOptions())
+		defer inner.unblock()
+
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "blockedG")
+	})
+}
+
+func TestFindOnly(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	require.NoError(t, FindOnly(IncludeAllContainingPkg("example.com/other")),
+		"goroutines outside the included packages should be ignored")
+
+	err := FindOnly(testOptions(), IncludeAllContainingPkg("github.com/projectdiscovery/goleak"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "blockedG")
+
+	require.NoError(t, FindOnly(
+		IncludeAllContainingPkg("github.com/projectdiscovery/goleak"),
+		IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"),
+	), "included goroutines should still be subject to ignores")
+
+	assert.ErrorContains(t, FindOnly(), "requires at least one Include option")
+}
+
+func TestVerifyOnly(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	ft := &fakeT{}
+	VerifyOnly(ft, IncludeAllContainingPkg("example.com/other"))
+	assert.Empty(t, ft.errors)
+
+	VerifyOnly(ft, testOptions(), IncludeAllContainingPkg("github.com/projectdiscovery/goleak"))
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "blockedG")
+
+	ft = &fakeT{}
+	VerifyOnly(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "requires at least one Include option")
+}
+
+func TestVerifyGone(t *testing.T) {
+	const run = "github.com/projectdiscovery/goleak.(*blockedG).run"
+
+	bg := startBlockedG()
+	ft := &fakeT{}
+	VerifyGone(ft, run, testOptions())
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "blockedG")
+
+	// Goroutines without the function are ignored.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		<-stop
+	}()
+	bg.unblock()
+
+	ft = &fakeT{}
+	VerifyGone(ft, run)
+	assert.Empty(t, ft.errors)
+
+	ft = &fakeT{}
+	VerifyGone(ft, "")
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "empty function name")
+}
+
+func TestFilterStacksParallel(t *testing.T) {
+	const n = 2 * _parallelFilterThreshold
+	var started sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < n; i++ {
+		started.Add(1)
+		go func() {
+			started.Done()
+			<-done
+		}()
+	}
+	started.Wait()
+	defer close(done)
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	all := stack.All()
+	require.Greater(t, len(all), _parallelFilterThreshold)
+	wantOrder := make([]int, 0, len(all))
+	for _, s := range all {
+		if s.FirstFunction() == "github.com/projectdiscovery/goleak.(*blockedG).block" {
+			wantOrder = append(wantOrder, s.ID())
+		}
+	}
+
+	opts := buildOpts(IgnoreAnyFunction("github.com/projectdiscovery/goleak.TestFilterStacksParallel.func1"))
+	filtered := filterStacks(all, stack.Current().ID(), opts)
+	var gotOrder []int
+	for _, s := range filtered {
+		if s.FirstFunction() == "github.com/projectdiscovery/goleak.(*blockedG).block" {
+			gotOrder = append(gotOrder, s.ID())
+		}
+	}
+	assert.Equal(t, wantOrder, gotOrder)
+	assert.Less(t, len(filtered), _parallelFilterThreshold, "ignored goroutines should be filtered")
+}
+
+func TestLeakOrder(t *testing.T) {
+	goroutine := func(id int, fn string) string {
+		return fmt.Sprintf("goroutine %d [chan receive]:\n%v()\n\t/app/main.go:10 +0x25\n"+
+			"created by main.main in goroutine 1\n\t/app/main.go:5 +0x25\n", id, fn)
+	}
+	dump := func(order ...string) []byte {
+		return []byte(strings.Join(order, "\n"))
+	}
+	a := []string{
+		goroutine(9, "main.worker"),
+		goroutine(3, "main.poller"),
+		goroutine(7, "main.worker"),
+	}
+
+	var leaked []stack.Stack
+	err := FindInDump(dump(a...), OnLeak(func(stacks []stack.Stack) { leaked = stacks }))
+	require.Error(t, err)
+	require.Len(t, leaked, 3)
+	for i := 1; i < len(leaked); i++ {
+		prev, cur := leaked[i-1], leaked[i]
+		if prev.Fingerprint() == cur.Fingerprint() {
+			assert.Less(t, prev.ID(), cur.ID(), "same fingerprint should be sorted by ID")
+		} else {
+			assert.Less(t, prev.Fingerprint(), cur.Fingerprint(), "leaks should be sorted by fingerprint")
+		}
+	}
+
+	reordered := FindInDump(dump(a[2], a[0], a[1]))
+	require.Error(t, reordered)
+	assert.Equal(t, err.Error(), reordered.Error(), "report should not depend on the order of the dump")
+}
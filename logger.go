@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// WithLogger makes leak checks and [DetectGrowth] log their progress
+// through the given logger: retries and their remaining goroutines
+// at debug level, and leaks or growth at warn level.
+//
+// By default, nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return optionFunc(func(opts *opts) {
+		opts.logger = l
+	})
+}
+
+// PrintfLogger adapts a printf-style function into a *slog.Logger
+// for use with [WithLogger]. Every record is formatted as a single line
+// of key=value pairs and passed to printf with a "%s" format.
+//
+// This allows logging through loggers without slog support,
+// e.g. github.com/projectdiscovery/gologger:
+//
+//	goleak.WithLogger(goleak.PrintfLogger(func(format string, args ...any) {
+//		gologger.Info().Msgf(format, args...)
+//	}))
+func PrintfLogger(printf func(format string, args ...any)) *slog.Logger {
+	return slog.New(slog.NewTextHandler(printfWriter(printf), &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// The host logger adds its own timestamps.
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// printfWriter is an io.Writer that passes every write to printf.
+type printfWriter func(format string, args ...any)
+
+func (f printfWriter) Write(p []byte) (int, error) {
+	f("%s", strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
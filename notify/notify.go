@@ -0,0 +1,304 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package notify posts goleak alerts to webhooks, such as generic
+// HTTP endpoints or Slack incoming webhooks, for services that check for
+// leaks while they run.
+//
+// A Notifier turns the alerts of [goleak.Alert] and the growth reports
+// of [goleak.DetectGrowth] into messages, renders them with a template
+// and posts them to its URL:
+//
+//	n := notify.NewSlack(os.Getenv("SLACK_WEBHOOK_URL"))
+//	alert := goleak.Alert(n.Alert, goleak.MinInterval(time.Hour))
+//	go n.Watch(ctx, goleak.DetectGrowth(ctx, time.Minute, 10))
+//	for range time.Tick(time.Minute) {
+//		_ = goleak.Find(alert, goleak.IgnoreStartupGoroutines(time.Minute))
+//	}
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// Message is a leak or a growth of goroutines to notify about.
+// It's the data that templates are executed with.
+type Message struct {
+	// Kind is "leak" for alerts and "growth" for growth reports.
+	Kind string `json:"kind"`
+	// Fingerprint identifies the goroutines;
+	// see [github.com/projectdiscovery/goleak/stack.Stack.Fingerprint].
+	Fingerprint string `json:"fingerprint"`
+	// Function is the function on top of the stacks of the goroutines.
+	Function string `json:"function"`
+	// Count is the number of goroutines with the fingerprint.
+	Count int `json:"count"`
+	// Growth is the number of goroutines added over Window,
+	// and Rate the number added per minute, for growth reports.
+	Growth int           `json:"growth,omitempty"`
+	Window time.Duration `json:"window,omitempty"`
+	Rate   float64       `json:"rate_per_minute,omitempty"`
+	// Partition is the value of the pprof label that growth reports are
+	// partitioned by with [goleak.PartitionByLabel], if any.
+	Partition string `json:"partition,omitempty"`
+	// FirstSeen and Suppressed are those of the alert, for leaks.
+	FirstSeen  time.Time `json:"first_seen,omitempty"`
+	Suppressed int       `json:"suppressed,omitempty"`
+	// Stack is the stack trace of one of the goroutines.
+	Stack string `json:"stack"`
+}
+
+// Summary describes the message in a line, e.g.
+// "goroutine leak: 3 goroutines in main.worker".
+func (m Message) Summary() string {
+	if m.Kind == "growth" {
+		summary := fmt.Sprintf("goroutine growth: %v grew by %d to %d goroutines over %v (%.1f/min)",
+			m.Function, m.Growth, m.Count, m.Window, m.Rate)
+		if m.Partition != "" {
+			summary += fmt.Sprintf(" in partition %q", m.Partition)
+		}
+		return summary
+	}
+	return fmt.Sprintf("goroutine leak: %d goroutines in %v", m.Count, m.Function)
+}
+
+// _funcs are the functions available to templates besides the builtins.
+var _funcs = TemplateFuncs()
+
+var (
+	// _webhookTemplate posts messages as JSON objects.
+	_webhookTemplate = template.Must(template.New("webhook").Funcs(_funcs).Parse(
+		`{{json .}}`))
+
+	// _slackTemplate posts messages in the format of Slack incoming
+	// webhooks, which Mattermost and others accept too.
+	_slackTemplate = template.Must(template.New("slack").Funcs(_funcs).Parse(
+		"{\"text\": {{json (printf \"%s\\n```\\n%s```\" .Summary .Stack)}}}"))
+)
+
+// Option configures a Notifier.
+type Option interface {
+	apply(*Notifier)
+}
+
+type optionFunc func(*Notifier)
+
+func (f optionFunc) apply(n *Notifier) { f(n) }
+
+// WithTemplate renders the bodies of requests with the given template,
+// which is executed with a [Message]. Its bodies are sent as JSON unless
+// WithHeader sets another Content-Type. Templates may use the json
+// function to encode values, e.g. to post to an endpoint expecting
+// a "content" field:
+//
+//	tmpl := template.Must(template.New("").Funcs(notify.TemplateFuncs()).Parse(
+//		`{"content": {{json .Summary}}}`))
+func WithTemplate(tmpl *template.Template) Option {
+	return optionFunc(func(n *Notifier) {
+		n.tmpl = tmpl
+	})
+}
+
+// TemplateFuncs returns the functions that the built-in templates use,
+// for templates given to WithTemplate.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{"json": jsonString}
+}
+
+// jsonString encodes v as JSON.
+func jsonString(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// WithHeader sets a header of the requests, e.g. for authentication.
+func WithHeader(key, value string) Option {
+	return optionFunc(func(n *Notifier) {
+		n.header.Set(key, value)
+	})
+}
+
+// WithHTTPClient sends requests with the given client instead of
+// a client with a timeout of 10 seconds.
+func WithHTTPClient(client *http.Client) Option {
+	return optionFunc(func(n *Notifier) {
+		n.client = client
+	})
+}
+
+// OnError calls f with the errors of Alert and Watch, which have no
+// caller to return them to, instead of writing them to standard error.
+func OnError(f func(error)) Option {
+	return optionFunc(func(n *Notifier) {
+		n.onError = f
+	})
+}
+
+// Notifier posts messages to a webhook.
+type Notifier struct {
+	url     string
+	tmpl    *template.Template
+	header  http.Header
+	client  *http.Client
+	onError func(error)
+}
+
+// NewWebhook returns a Notifier that posts messages to url
+// as JSON encoded [Message] objects, unless WithTemplate is given.
+func NewWebhook(url string, options ...Option) *Notifier {
+	return newNotifier(url, _webhookTemplate, options)
+}
+
+// NewSlack returns a Notifier that posts messages to a Slack incoming
+// webhook at url, as a summary and the stack of an example goroutine.
+func NewSlack(url string, options ...Option) *Notifier {
+	return newNotifier(url, _slackTemplate, options)
+}
+
+func newNotifier(url string, tmpl *template.Template, options []Option) *Notifier {
+	n := &Notifier{
+		url:    url,
+		tmpl:   tmpl,
+		header: http.Header{"Content-Type": {"application/json"}},
+		client: &http.Client{Timeout: 10 * time.Second},
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "goleak/notify: %v\n", err)
+		},
+	}
+	for _, opt := range options {
+		opt.apply(n)
+	}
+	return n
+}
+
+// Notify renders m with the template of the Notifier and posts it.
+// Responses with a status other than 2xx are errors.
+func (n *Notifier) Notify(ctx context.Context, m Message) error {
+	var body bytes.Buffer
+	if err := n.tmpl.Execute(&body, m); err != nil {
+		return fmt.Errorf("render notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, &body)
+	if err != nil {
+		return fmt.Errorf("post notification: %w", withoutURL(err))
+	}
+	req.Header = n.header.Clone()
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post notification: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("post notification: %v: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) // allow reusing the connection
+	return nil
+}
+
+// withoutURL unwraps the *url.Error that net/http returns, since URLs
+// of webhooks hold secrets, e.g. the token of Slack incoming webhooks,
+// which errors shouldn't leak into logs.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// Alert posts an alert. It's meant to be passed to [goleak.Alert],
+// which takes care of deduplicating and rate limiting alerts:
+//
+//	goleak.Alert(n.Alert, goleak.MinInterval(time.Hour))
+//
+// Since the check waits for Alert to return, posting is bounded by the
+// timeout of the HTTP client.
+func (n *Notifier) Alert(a goleak.LeakAlert) {
+	n.report(n.Notify(context.Background(), Message{
+		Kind:        "leak",
+		Fingerprint: a.Fingerprint,
+		Function:    a.Function,
+		Count:       a.Count,
+		FirstSeen:   a.FirstSeen,
+		Suppressed:  a.Suppressed,
+		Stack:       a.Example.String(),
+	}))
+}
+
+// Watch posts a message per growing fingerprint of every report received
+// from reports until the channel is closed or ctx is done.
+func (n *Notifier) Watch(ctx context.Context, reports <-chan goleak.GrowthReport) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			for _, m := range growthMessages(report) {
+				n.report(n.Notify(ctx, m))
+			}
+		}
+	}
+}
+
+// growthMessages returns the messages for a growth report.
+func growthMessages(report goleak.GrowthReport) []Message {
+	msgs := make([]Message, 0, len(report.Growth))
+	for _, g := range report.Growth {
+		m := Message{
+			Kind:        "growth",
+			Fingerprint: g.Fingerprint,
+			Function:    g.Example.FirstFunction(),
+			Count:       g.To,
+			Growth:      g.To - g.From,
+			Window:      report.Window,
+			Partition:   g.Partition,
+			Stack:       g.Example.String(),
+		}
+		if report.Window > 0 {
+			m.Rate = float64(m.Growth) / report.Window.Minutes()
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func (n *Notifier) report(err error) {
+	if err != nil && n.onError != nil {
+		n.onError(err)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _dump = `goroutine 7 [chan receive]:
+main.worker()
+	/app/main.go:10 +0x25
+created by main.main in goroutine 1
+	/app/main.go:5 +0x25
+
+goroutine 8 [chan receive]:
+main.worker()
+	/app/main.go:10 +0x25
+created by main.main in goroutine 1
+	/app/main.go:5 +0x25
+`
+
+// recorder is a webhook endpoint that records the requests it receives.
+type recorder struct {
+	*httptest.Server
+	status  int
+	bodies  []string
+	headers []http.Header
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		r.bodies = append(r.bodies, string(body))
+		r.headers = append(r.headers, req.Header)
+		if r.status != http.StatusOK {
+			http.Error(w, "bad request", r.status)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestWebhookAlert(t *testing.T) {
+	rec := newRecorder(t)
+	n := NewWebhook(rec.URL, WithHeader("Authorization", "Bearer token"))
+
+	alert := goleak.Alert(n.Alert)
+	require.Error(t, goleak.FindInDump([]byte(_dump), alert))
+	require.Error(t, goleak.FindInDump([]byte(_dump), alert))
+	require.Len(t, rec.bodies, 1, "goleak.Alert should deduplicate alerts")
+
+	var m Message
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &m))
+	assert.Equal(t, "leak", m.Kind)
+	assert.Equal(t, "main.worker", m.Function)
+	assert.Equal(t, 2, m.Count)
+	assert.NotEmpty(t, m.Fingerprint)
+	assert.Contains(t, m.Stack, "Goroutine 7 in state chan receive")
+	assert.Equal(t, "application/json", rec.headers[0].Get("Content-Type"))
+	assert.Equal(t, "Bearer token", rec.headers[0].Get("Authorization"))
+}
+
+func TestSlack(t *testing.T) {
+	rec := newRecorder(t)
+	require.NoError(t, NewSlack(rec.URL).Notify(context.Background(), Message{
+		Kind:     "leak",
+		Function: "main.worker",
+		Count:    2,
+		Stack:    "goroutine 7 [chan receive]:\n",
+	}))
+
+	var body struct{ Text string }
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &body))
+	assert.Equal(t, "goroutine leak: 2 goroutines in main.worker\n```\ngoroutine 7 [chan receive]:\n```", body.Text)
+}
+
+func TestWithTemplate(t *testing.T) {
+	rec := newRecorder(t)
+	tmpl := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(`{"content": {{json .Summary}}}`))
+	n := NewWebhook(rec.URL, WithTemplate(tmpl))
+	require.NoError(t, n.Notify(context.Background(), Message{Function: "main.worker", Count: 1}))
+	assert.Equal(t, `{"content": "goroutine leak: 1 goroutines in main.worker"}`, rec.bodies[0])
+}
+
+func TestWatch(t *testing.T) {
+	rec := newRecorder(t)
+	stacks, err := stack.ParseDump([]byte(_dump))
+	require.NoError(t, err)
+
+	reports := make(chan goleak.GrowthReport, 1)
+	reports <- goleak.GrowthReport{
+		Time:   time.Now(),
+		Window: 2 * time.Minute,
+		Growth: []goleak.FingerprintGrowth{
+			{Fingerprint: stacks[0].Fingerprint(), From: 2, To: 12, Example: stacks[0]},
+		},
+	}
+	close(reports)
+	NewWebhook(rec.URL).Watch(context.Background(), reports)
+
+	require.Len(t, rec.bodies, 1)
+	var m Message
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &m))
+	assert.Equal(t, "growth", m.Kind)
+	assert.Equal(t, 12, m.Count)
+	assert.Equal(t, 10, m.Growth)
+	assert.Equal(t, 5.0, m.Rate)
+	assert.Equal(t, "goroutine growth: main.worker grew by 10 to 12 goroutines over 2m0s (5.0/min)", m.Summary())
+	m.Partition = "scan-1"
+	assert.Equal(t, `goroutine growth: main.worker grew by 10 to 12 goroutines over 2m0s (5.0/min) in partition "scan-1"`, m.Summary())
+}
+
+func TestErrors(t *testing.T) {
+	rec := newRecorder(t)
+	rec.status = http.StatusBadRequest
+
+	err := NewWebhook(rec.URL).Notify(context.Background(), Message{})
+	require.Error(t, err)
+	assert.Equal(t, "post notification: 400 Bad Request: bad request", err.Error())
+
+	var errs []error
+	n := NewWebhook(rec.URL, OnError(func(err error) { errs = append(errs, err) }))
+	n.Alert(goleak.LeakAlert{Function: "main.worker"})
+	require.Len(t, errs, 1)
+
+	// Webhook URLs hold secrets, which must not end up in logs.
+	secret := "http://127.0.0.1:1/services/T000/B000/secret-token"
+	err = NewWebhook(secret).Notify(context.Background(), Message{})
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "post notification: "), "unexpected error: %v", err)
+	assert.NotContains(t, err.Error(), "secret-token")
+	err = NewWebhook("http://example.com/secret-token\x7f").Notify(context.Background(), Message{})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+
+	tmpl := template.Must(template.New("").Parse(`{{.Missing}}`))
+	err = NewWebhook(rec.URL, WithTemplate(tmpl)).Notify(context.Background(), Message{})
+	assert.ErrorContains(t, err, "render notification")
+	assert.Len(t, rec.bodies, 2, "nothing should be posted if rendering fails")
+	assert.False(t, strings.HasPrefix(err.Error(), "post"))
+}
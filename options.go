@@ -21,13 +21,26 @@
 package goleak
 
 import (
+	"context"
+	"expvar"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/projectdiscovery/goleak/stack"
 )
 
 // Option lets users specify custom verifications.
+//
+// Option constructors panic with an [*OptionError] if their arguments
+// are invalid, e.g. an empty function name; see [CheckedOption].
 type Option interface {
 	apply(*opts)
 }
@@ -37,21 +50,101 @@ type Option interface {
 // a short while to let any running goroutines complete.
 const _defaultRetries = 20
 
+// _defaultStableAfter is the number of attempts after which retrying
+// stops if the remaining goroutines don't change; see StableAfter.
+const _defaultStableAfter = 3
+
+// Goroutines blocked in these states can never be unblocked,
+// so there is no point in retrying once one of them is found.
+var _defaultFailFastStates = []string{
+	"chan receive (nil chan)",
+	"chan send (nil chan)",
+	"select (no cases)",
+}
+
 type opts struct {
-	filters    []func(stack.Stack) bool
+	filters    []filter
 	maxRetries int
 	maxSleep   time.Duration
+	deadline   time.Time // when to stop retrying; zero for no limit
 	cleanup    func(int)
 	pretty     bool
-}
+	onRetry    func(int, []stack.Stack)
+	onLeak     func([]stack.Stack)
+	onStats    func(Stats)
+	alerts     []*alerter
+
+	// onLeakReport is called with the report of the leaks found by a
+	// leak check, with what it learned while retrying, for the
+	// summaries and child reports of VerifyTestMain.
+	onLeakReport func(Report)
+
+	// defaultFilters are the built-in filters, kept apart from filters
+	// so that DisableDefaultFilters can remove them.
+	defaultFilters []filter
+	includes       []func(stack.Stack) bool
+	failFastStates []string
+	timeline       *Timeline
+	expvarName     string
+	logger         *slog.Logger
+	remote         remoteOpts
+	snapshotStore  SnapshotStore
+	failWith       func(error)
+	maxDumpBytes   int
+	warnKnownLeaks bool
+	reportOnly     bool
+
+	allowedThreadGrowth int
+	quarantine          map[string]struct{}
+	quarantineMatch     []func(stack.Stack) bool
+	allowances          []allowance
+	rulesWatchers       []*RulesWatcher
+	maxOverheadPercent  float64
+	stableAfter         int
+	failOnUnusedFilters bool
+
+	// ctx interrupts the retries of the checks of a Detector; may be nil.
+	ctx context.Context
+
+	// diagnoseCleanupOrder adds teardown hints to leak errors,
+	// and diagnoseGroups hints about members of groups.
+	diagnoseCleanupOrder bool
+	diagnoseGroups       bool
 
-// implement apply so that opts struct itself can be used as
-// an Option.
-func (o *opts) apply(opts *opts) {
-	opts.filters = o.filters
-	opts.maxRetries = o.maxRetries
-	opts.maxSleep = o.maxSleep
-	opts.cleanup = o.cleanup
+	// ageTrackers take snapshots for IgnoreYoungerThan in goroutines
+	// that are excluded like the checking goroutine.
+	ageTrackers []*AgeTracker
+
+	// poolDetectors recognize the pool workers that IgnorePoolWorkers
+	// ignores, which ReportIdlePoolWorkers lists in leak errors.
+	poolDetectors         []poolDetector
+	reportIdlePoolWorkers bool
+
+	// timeoutDumpPath is where VerifyTestMain writes the leaks of test
+	// binaries that are about to time out or be killed.
+	timeoutDumpPath string
+
+	// maxFrames and hideRuntimeFrames trim the stacks in reports,
+	// sourceLines adds source code around some frames,
+	// and permalinkTemplate links frames to their source.
+	maxFrames         int
+	hideRuntimeFrames bool
+	sourceLines       int
+	permalinkTemplate string
+
+	// diffFrom is the snapshot that leaks are diffed against.
+	diffFrom *Snapshot
+
+	// leakExitCode is the exit code of VerifyTestMain on leaks.
+	leakExitCode           int
+	overrideFailedExitCode bool
+
+	// label restricts checks to goroutines with a pprof label,
+	// formatted like in goroutine profiles, e.g. `"key":"value"`.
+	label string
+	// partitionLabel is the key of the pprof label that DetectGrowth
+	// partitions goroutines by.
+	partitionLabel string
 }
 
 // optionFunc lets us easily write options without a custom type.
@@ -59,13 +152,41 @@ type optionFunc func(*opts)
 
 func (f optionFunc) apply(opts *opts) { f(opts) }
 
+// Options is a bundle of options that is itself an Option,
+// e.g. to share the options of a test suite:
+//
+//	var suiteOptions = goleak.Options{
+//		goleak.IgnoreTopFunction("example.com/db.(*Pool).reaper"),
+//		goleak.Cleanup(func(int) { db.Close() }),
+//	}
+//
+// Options are applied in order, which merges them with the options
+// before and after them: the filters and includes of all of them apply,
+// the functions of [Cleanup] options all run in order, and settings
+// that take a single value, like [MaxDumpBytes], are set by the last
+// option that sets them. Bundles may be nested.
+type Options []Option
+
+func (o Options) apply(opts *opts) {
+	for _, option := range o {
+		option.apply(opts)
+	}
+}
+
+// Combine returns an Option that applies the given options in order,
+// merging them like [Options].
+func Combine(options ...Option) Option {
+	return append(Options(nil), options...)
+}
+
 // IgnoreTopFunction ignores any goroutines where the specified function
 // is at the top of the stack. The function name should be fully qualified,
 // e.g., github.com/projectdiscovery/goleak.IgnoreTopFunction
 func IgnoreTopFunction(f string) Option {
-	return addFilter(func(s stack.Stack) bool {
+	opt := addFilter(fmt.Sprintf("IgnoreTopFunction(%q)", f), func(s stack.Stack) bool {
 		return s.FirstFunction() == f
 	})
+	return checkLegacy(opt, func() { checkFunctionName("IgnoreTopFunction", f) })
 }
 
 // Pretty sets the output of the leak check to be more human-readable.
@@ -86,43 +207,273 @@ func Pretty() Option {
 //
 //	github.com/projectdiscovery/goleak.(*MyType).MyMethod
 func IgnoreAnyFunction(f string) Option {
-	return addFilter(func(s stack.Stack) bool {
+	opt := addFilter(fmt.Sprintf("IgnoreAnyFunction(%q)", f), func(s stack.Stack) bool {
 		return s.HasFunction(f)
 	})
+	return checkLegacy(opt, func() { checkFunctionName("IgnoreAnyFunction", f) })
 }
 
+// IgnoreAnyEntry ignores goroutines where the call of any function
+// in the stack, including its arguments, contains the given text.
+//
+// Deprecated: Use [IgnoreStackContaining],
+// which also matches file paths and is better specified.
 func IgnoreAnyEntry(e string) Option {
-	return addFilter(func(s stack.Stack) bool {
+	opt := addFilter(fmt.Sprintf("IgnoreAnyEntry(%q)", e), func(s stack.Stack) bool {
 		return s.MatchAnyEntry(e)
 	})
+	return checkLegacy(opt, func() {
+		if e == "" {
+			invalidOption("IgnoreAnyEntry", "empty text would match every goroutine")
+		}
+	})
+}
+
+// IgnoreStackContaining ignores goroutines where any line of the stack
+// trace contains substr. This can match what function-name filters
+// can't, such as the file paths of vendored code:
+//
+//	goleak.IgnoreStackContaining("/vendor/github.com/miekg/dns/")
+//
+// The lines matched are those of [stack.Stack.Full]:
+// each function call with its arguments, e.g.
+//
+//	example.com/foo.(*Server).serve(0xc000120000)
+//
+// each location that follows it, starting with a tab, e.g.
+//
+//	<tab>/home/user/foo/server.go:42 +0x1d
+//
+// and the "created by" line and its location.
+// The goroutine header and any ancestor traces are not matched.
+// substr is matched within a single line,
+// so IgnoreStackContaining panics if it contains a newline.
+func IgnoreStackContaining(substr string) Option {
+	switch {
+	case substr == "":
+		invalidOption("IgnoreStackContaining", "empty text would match every goroutine")
+	case strings.Contains(substr, "\n"):
+		invalidOption("IgnoreStackContaining", "text %q spans lines", substr)
+	}
+	return addFilter(fmt.Sprintf("IgnoreStackContaining(%q)", substr), func(s stack.Stack) bool {
+		return strings.Contains(s.Full(), substr)
+	})
+}
+
+// IgnoreFrameWhere ignores goroutines where f returns true for any
+// frame of the stack, not counting the "created by" entry. It's meant
+// for targeted ignores that function names can't express, e.g. of the
+// workers of a library that are told apart only by a sentinel argument:
+//
+//	goleak.IgnoreFrameWhere(func(e stack.Entry) bool {
+//		args := e.Args()
+//		return e.Function() == "example.com/pool.worker" &&
+//			len(args) > 1 && args[1].Value == 0x2a
+//	})
+//
+// See [stack.Entry.Args] for how arguments are printed.
+func IgnoreFrameWhere(f func(stack.Entry) bool) Option {
+	if f == nil {
+		invalidOption("IgnoreFrameWhere", "nil function")
+	}
+	return addFilter("IgnoreFrameWhere()", func(s stack.Stack) bool {
+		for _, entry := range s.Entries() {
+			if !entry.IsSource && f(entry) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// IgnoreStackMatching ignores goroutines where any line of the stack
+// trace matches re. The lines are the same as for
+// [IgnoreStackContaining]; they are matched one at a time and without
+// their trailing newline, so ^ and $ anchor to the start and end of a line.
+// Remember that locations start with a tab.
+func IgnoreStackMatching(re *regexp.Regexp) Option {
+	if re == nil {
+		invalidOption("IgnoreStackMatching", "nil regexp")
+	}
+	return addFilter(fmt.Sprintf("IgnoreStackMatching(%q)", re), func(s stack.Stack) bool {
+		for rest := s.Full(); rest != ""; {
+			var line string
+			line, rest, _ = strings.Cut(rest, "\n")
+			if re.MatchString(line) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// IgnoreFunctionRegexp ignores goroutines where any function
+// in the stack matches the given regular expression.
+//
+// The accuracy depends on the regular expression, so it should be as
+// specific as possible; anchor it with ^ and $ to match whole names.
+// Prefer [IgnoreAnyContainingPkg] or [IgnoreAnyContainingStruct]
+// when they fit.
+func IgnoreFunctionRegexp(re *regexp.Regexp) Option {
+	if re == nil {
+		invalidOption("IgnoreFunctionRegexp", "nil regexp")
+	}
+	return addFilter(fmt.Sprintf("IgnoreFunctionRegexp(%q)", re), func(s stack.Stack) bool {
+		return s.HasFunctionFunc(re.MatchString)
+	})
+}
+
+// IgnoreTopFunctionRegexp ignores goroutines where the function
+// at the top of the stack matches the given regular expression.
+func IgnoreTopFunctionRegexp(re *regexp.Regexp) Option {
+	if re == nil {
+		invalidOption("IgnoreTopFunctionRegexp", "nil regexp")
+	}
+	return addFilter(fmt.Sprintf("IgnoreTopFunctionRegexp(%q)", re), func(s stack.Stack) bool {
+		return re.MatchString(s.FirstFunction())
+	})
+}
+
+// containingRegexp matches the names of functions in the given
+// package or struct. It is compiled once per option, rather than for
+// every stack that the option is applied to.
+func containingRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\Q` + name + `.\E.+`)
+}
+
+// IgnoreFunctionGlob ignores goroutines where any function in the stack
+// matches the given glob pattern, using the syntax of [path.Match].
+// This is useful when many packages have similarly-shaped goroutines:
+//
+//	goleak.IgnoreFunctionGlob(`github.com/myorg/*/worker.(\*Pool).run`)
+//
+// As with path.Match, '*' does not match '/', so it only spans
+// a single element of the import path. Since '*' also appears in the names
+// of methods with pointer receivers, escape it with a backslash to match it
+// literally. IgnoreFunctionGlob panics if the pattern is malformed.
+func IgnoreFunctionGlob(pattern string) Option {
+	if pattern == "" {
+		invalidOption("IgnoreFunctionGlob", "empty pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		invalidOption("IgnoreFunctionGlob", "bad glob pattern %q: %v", pattern, err)
+	}
+	return addFilter(fmt.Sprintf("IgnoreFunctionGlob(%q)", pattern), func(s stack.Stack) bool {
+		return s.HasFunctionFunc(func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		})
+	})
+}
+
+// IgnoreFile ignores goroutines where the source file of any frame
+// in the stack matches the given glob pattern, using the syntax of
+// [path.Match]. Files are matched by their full path as it appears
+// in the stack trace, with forward slashes on all platforms.
+//
+// Since '*' does not match '/', a pattern that doesn't start with '/'
+// may also match at any directory boundary, like in .gitignore files:
+//
+//	goleak.IgnoreFile("vendor/github.com/miekg/dns/*.go")
+//
+// IgnoreFile panics if the pattern is malformed.
+func IgnoreFile(pattern string) Option {
+	if pattern == "" {
+		invalidOption("IgnoreFile", "empty pattern")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		invalidOption("IgnoreFile", "bad glob pattern %q: %v", pattern, err)
+	}
+	return addFilter(fmt.Sprintf("IgnoreFile(%q)", pattern), func(s stack.Stack) bool {
+		return s.HasFileFunc(func(file string) bool {
+			return matchFile(pattern, filepath.ToSlash(file))
+		})
+	})
 }
 
-// ignoreAnyFunctionMatching ignores goroutines where any function
-// matches the specified regular expression.
+// matchFile reports whether file or, for relative patterns,
+// any of its trailing sequences of path elements matches pattern.
+func matchFile(pattern, file string) bool {
+	for {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if strings.HasPrefix(pattern, "/") {
+			return false
+		}
+		i := strings.IndexByte(file, '/')
+		if i < 0 {
+			return false
+		}
+		file = file[i+1:]
+	}
+}
+
+// IgnoreModule ignores goroutines where any frame in the stack is in
+// the source of the Go module with the given path,
+// e.g. "github.com/miekg/dns". Unlike [IgnoreAnyContainingPkg],
+// this covers all packages of the module but not those of nested
+// modules, such as "github.com/miekg/dns/v2".
 //
-// The accuracy depends on the regular expression. That is why given regex
-// should be as specific as possible.
-// One should use IgnoreAnyContainingPackage or IgnoreAnyContainingStruct
-// instead of this function if possible.
-func ignoreAnyFunctionMatching(regex string) Option {
-	return addFilter(func(s stack.Stack) bool {
-		return s.MatchAnyFunction(regex)
+// Modules are recognized by the file paths they are built from:
+// the module cache, e.g. $GOPATH/pkg/mod/github.com/miekg/dns@v1.1.58/,
+// or a vendor directory, e.g. vendor/github.com/miekg/dns/.
+// In a vendor directory, nested modules can't be told apart from
+// packages of the module, so they are ignored too.
+// Modules built from elsewhere, such as with a replace directive
+// pointing at a local directory, are not recognized; use [IgnoreFile].
+func IgnoreModule(modulePath string) Option {
+	checkName("IgnoreModule", "module", modulePath)
+	cached := "/pkg/mod/" + escapeModulePath(modulePath) + "@"
+	vendored := "/vendor/" + modulePath + "/"
+	return addFilter(fmt.Sprintf("IgnoreModule(%q)", modulePath), func(s stack.Stack) bool {
+		if !strings.Contains(s.Full(), cached) && !strings.Contains(s.Full(), vendored) {
+			// Avoid parsing the frames of stacks that can't match.
+			return false
+		}
+		return s.HasFileFunc(func(file string) bool {
+			file = filepath.ToSlash(file)
+			return strings.Contains(file, cached) || strings.Contains(file, vendored)
+		})
 	})
 }
 
+// escapeModulePath escapes a module path the way the module cache does
+// on disk, replacing each upper-case letter with '!' followed by the
+// letter in lower case.
+func escapeModulePath(modulePath string) string {
+	var sb strings.Builder
+	for _, r := range modulePath {
+		if 'A' <= r && r <= 'Z' {
+			sb.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 // IgnoreAnyContainingPkg creates an option that filters out goroutines
 // if any function in their stack trace includes the specified package name.
 // The package name must be fully qualified, such as "github.com/projectdiscovery/goleak".
 // Note: The package name does not require escaping in this context.
 func IgnoreAnyContainingPkg(pkg string) Option {
-	return ignoreAnyFunctionMatching(`\Q` + pkg + `.\E.+`)
+	re := containingRegexp(pkg)
+	opt := addFilter(fmt.Sprintf("IgnoreAnyContainingPkg(%q)", pkg), func(s stack.Stack) bool {
+		return s.HasFunctionFunc(re.MatchString)
+	})
+	return checkLegacy(opt, func() { checkName("IgnoreAnyContainingPkg", "package", pkg) })
 }
 
 // IgnoreAnyContainingStruct provides an option to filter out goroutines based on the presence of a specified struct
 // in any function within their stack trace. The struct name must be fully qualified, such as "github.com/projectdiscovery/goleak.(*MyType)".
 // Note: The struct name should be used as is without any need for escaping special characters.
 func IgnoreAnyContainingStruct(str string) Option {
-	return ignoreAnyFunctionMatching(`\Q` + str + `.\E.+`)
+	re := containingRegexp(str)
+	opt := addFilter(fmt.Sprintf("IgnoreAnyContainingStruct(%q)", str), func(s stack.Stack) bool {
+		return s.HasFunctionFunc(re.MatchString)
+	})
+	return checkLegacy(opt, func() { checkName("IgnoreAnyContainingStruct", "struct", str) })
 }
 
 // IncludeAllContainingPkg filters goroutines to only include those where any function
@@ -134,12 +485,60 @@ func IgnoreAnyContainingStruct(str string) Option {
 // Example use case:
 // This function can be used to focus on goroutines that are relevant to the user's
 // own packages, excluding those from third-party packages.
+//
+// If several Include options are given, goroutines matching any of them
+// are included. Included goroutines are still subject to Ignore options.
+// See [FindOnly] and [VerifyOnly].
 func IncludeAllContainingPkg(pkg string) Option {
-	return addFilter(func(s stack.Stack) bool {
-		// Construct a regex pattern that matches the fully qualified package name
-		// and checks if any function in the stack trace includes this package.
-		pattern := `\Q` + pkg + `.\E.+`
-		return s.MatchAnyFunction(pattern)
+	re := containingRegexp(pkg)
+	opt := addInclude(func(s stack.Stack) bool {
+		return s.HasFunctionFunc(re.MatchString)
+	})
+	return checkLegacy(opt, func() { checkName("IncludeAllContainingPkg", "package", pkg) })
+}
+
+// IncludeTopFunction only includes goroutines where the specified function
+// is at the top of the stack, like [IncludeAllContainingPkg].
+// The function name should be fully qualified,
+// e.g., github.com/projectdiscovery/goleak.IncludeTopFunction
+func IncludeTopFunction(f string) Option {
+	checkFunctionName("IncludeTopFunction", f)
+	return addInclude(func(s stack.Stack) bool {
+		return s.FirstFunction() == f
+	})
+}
+
+// IncludeCreatedBy only includes goroutines that were created by the
+// specified function, like [IncludeAllContainingPkg].
+// The function name should be fully qualified,
+// e.g., github.com/projectdiscovery/goleak.(*Pool).Start
+func IncludeCreatedBy(f string) Option {
+	checkFunctionName("IncludeCreatedBy", f)
+	return addInclude(func(s stack.Stack) bool {
+		return s.CreatedBy() == f
+	})
+}
+
+// IncludeAnyFunction only includes goroutines where the specified function
+// is anywhere in the stack, like [IncludeAllContainingPkg].
+// The function name should be fully qualified,
+// e.g., github.com/projectdiscovery/goleak.(*Pool).worker
+func IncludeAnyFunction(f string) Option {
+	checkFunctionName("IncludeAnyFunction", f)
+	return addInclude(func(s stack.Stack) bool {
+		return s.HasFunction(f)
+	})
+}
+
+// IncludeAnyContainingStruct only includes goroutines where any function
+// in the stack belongs to the specified struct, like [IncludeAllContainingPkg].
+// The struct name must be fully qualified,
+// such as "github.com/projectdiscovery/goleak.(*MyType)".
+func IncludeAnyContainingStruct(str string) Option {
+	checkName("IncludeAnyContainingStruct", "struct", str)
+	re := containingRegexp(str)
+	return addInclude(func(s stack.Stack) bool {
+		return s.HasFunctionFunc(re.MatchString)
 	})
 }
 
@@ -149,21 +548,294 @@ func IncludeAllContainingPkg(pkg string) Option {
 // will be set to the exit code of TestMain.
 // When passed to [VerifyNone], the exit code will be set to 0.
 // This cannot be passed to [Find].
+//
+// Cleanup may be given more than once, e.g. by bundles of options that
+// each need teardown; the functions are then run in the order they
+// were given. Note that with [VerifyTestMain], the cleanup functions
+// replace the call to os.Exit, so one of them should exit.
 func Cleanup(cleanupFunc func(exitCode int)) Option {
 	return optionFunc(func(opts *opts) {
-		opts.cleanup = cleanupFunc
+		prev := opts.cleanup
+		if prev == nil {
+			opts.cleanup = cleanupFunc
+			return
+		}
+		opts.cleanup = func(exitCode int) {
+			prev(exitCode)
+			cleanupFunc(exitCode)
+		}
+	})
+}
+
+// FailWith sets the function that is called with the error describing
+// leaked goroutines found by [Verify], [VerifyNone] or [VerifyTestMain].
+// It replaces their default handling of leaks, letting callers decide
+// whether leaks should panic, be logged, or be handled otherwise.
+func FailWith(f func(error)) Option {
+	return optionFunc(func(opts *opts) {
+		opts.failWith = f
+	})
+}
+
+// OnRetry registers a function that is called every time a leak check
+// finds unexpected goroutines and is about to retry.
+// attempt is the 1-based number of the retry about to be made,
+// and remaining holds the goroutines that were found by the previous attempt.
+//
+// This is useful for logging progress or collecting metrics
+// while the leak check waits for goroutines to exit.
+func OnRetry(f func(attempt int, remaining []stack.Stack)) Option {
+	return optionFunc(func(opts *opts) {
+		opts.onRetry = f
+	})
+}
+
+// OnLeak registers a function that is called with the leaked goroutines
+// once a leak check has exhausted its retries, sorted like in [Find].
+// It is not called if no leaks are found.
+func OnLeak(f func(leaks []stack.Stack)) Option {
+	return optionFunc(func(opts *opts) {
+		opts.onLeak = f
+	})
+}
+
+// FailFastStates replaces the goroutine states for which a leak check
+// gives up without retrying.
+// If any unexpected goroutine is in a state that starts with one of the
+// given states, the check fails immediately instead of sleeping
+// in the hope that the goroutine exits.
+//
+// By default, only states that can never resolve are included:
+// operations on nil channels and selects without cases.
+// States like "semacquire" may be added if the code under test never
+// waits on locks during shutdown.
+// Calling FailFastStates with no states disables failing fast.
+func FailFastStates(states ...string) Option {
+	for _, state := range states {
+		if state == "" {
+			invalidOption("FailFastStates", "empty state")
+		}
+	}
+	return optionFunc(func(opts *opts) {
+		opts.failFastStates = states
+	})
+}
+
+// PublishExpvar makes [DetectGrowth] publish the goroutine counts of every
+// sample it takes as an expvar.Map with the given name. The map holds:
+//
+//   - total: the number of goroutines
+//   - filtered: the number of goroutines excluded by options
+//   - suspected: the number of remaining goroutines per fingerprint
+//
+// An existing expvar.Map with the same name is reused.
+// It panics if the name is empty or used by another kind of variable.
+func PublishExpvar(name string) Option {
+	if name == "" {
+		invalidOption("PublishExpvar", "empty name")
+	}
+	if v := expvar.Get(name); v != nil {
+		if _, ok := v.(*expvar.Map); !ok {
+			invalidOption("PublishExpvar", "name %q is already published as a %T", name, v)
+		}
+	}
+	return optionFunc(func(opts *opts) {
+		opts.expvarName = name
 	})
 }
 
 // IgnoreCurrent records all current goroutines when the option is created, and ignores
 // them in any future Find/Verify calls.
+//
+// Use [NewBaseline] for a baseline that can be refreshed or merged.
 func IgnoreCurrent() Option {
-	excludeIDSet := map[int]bool{}
-	for _, s := range stack.All() {
-		excludeIDSet[s.ID()] = true
+	return NewBaseline()
+}
+
+// NewBaseline records all current goroutines, like [IgnoreCurrent].
+// The returned [Baseline] can be refreshed to also ignore goroutines
+// started later, e.g. by the setup of a later phase of a test suite,
+// and merged with other baselines.
+func NewBaseline() *Baseline {
+	b := &Baseline{}
+	b.Refresh()
+	return b
+}
+
+// IgnoreNonLocal ignores goroutines that were created by code outside
+// the main module of the binary, such as the goroutines that
+// dependencies and the standard library start for themselves.
+// Most programs only need to check for leaks created by their own code.
+//
+// The main module is found with [runtime/debug.ReadBuildInfo];
+// in tests, it's the module of the package being tested.
+// A goroutine is local if the function that created it is in
+// package main or in a package whose import path starts with the path
+// of the main module. Goroutines that weren't created by a function,
+// such as the main goroutine, are never ignored. Nothing is ignored if
+// the binary was built without module support.
+func IgnoreNonLocal() Option {
+	var mainPath string
+	if info := _buildInfo(); info != nil {
+		mainPath = info.Main.Path
+	}
+	return addFilter("IgnoreNonLocal()", func(s stack.Stack) bool {
+		creator := s.CreatedBy()
+		if mainPath == "" || creator == "" {
+			return false
+		}
+		return !isLocalFunction(mainPath, creator)
+	})
+}
+
+// isLocalFunction reports whether the function with the given
+// fully qualified name is in package main or in the module at modulePath.
+func isLocalFunction(modulePath, name string) bool {
+	if strings.HasPrefix(name, "main.") {
+		return true
 	}
-	return addFilter(func(s stack.Stack) bool {
-		return excludeIDSet[s.ID()]
+	rest, ok := strings.CutPrefix(name, modulePath)
+	return ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/"))
+}
+
+// IgnoreTestStacks ignores goroutines that the testing package runs
+// while tests are running. It is one of the [DefaultFilters].
+func IgnoreTestStacks() Option {
+	return addFilter("IgnoreTestStacks()", isTestStack)
+}
+
+// IgnoreSyscallStacks ignores goroutines that are blocked in a system call
+// in the background, as happens when code uses cgo.
+//
+// Deprecated: Use [IgnoreCgoCallbacks], which ignores these goroutines
+// too and is one of the [DefaultFilters].
+func IgnoreSyscallStacks() Option {
+	return addFilter("IgnoreSyscallStacks()", isSyscallStack)
+}
+
+// IgnoreStdLibStacks ignores goroutines that the standard library runs
+// in the background, such as the os/signal loop.
+// It is one of the [DefaultFilters].
+func IgnoreStdLibStacks() Option {
+	return addFilter("IgnoreStdLibStacks()", isStdLibStack)
+}
+
+// IgnoreTraceStacks ignores the goroutine reading an execution trace,
+// e.g. when tests are run with -trace. It is one of the [DefaultFilters].
+func IgnoreTraceStacks() Option {
+	return addFilter("IgnoreTraceStacks()", isTraceStack)
+}
+
+// IgnoreFuzzStacks ignores goroutines of the fuzzing engine.
+// It is one of the [DefaultFilters].
+func IgnoreFuzzStacks() Option {
+	return addFilter("IgnoreFuzzStacks()", isFuzzStack)
+}
+
+// IgnoreRuntimeGCStacks ignores the runtime's goroutines for garbage
+// collection and finalizers, which some Go versions include in goroutine
+// dumps. See [stack.Stack.IsRuntimeGC]. It is one of the [DefaultFilters].
+func IgnoreRuntimeGCStacks() Option {
+	return addFilter("IgnoreRuntimeGCStacks()", isRuntimeGCStack)
+}
+
+// IgnorePlatformStacks ignores goroutines that the runtime and standard
+// library run on some platforms only, such as those handling JavaScript
+// events with GOOS=js, and name lookups that Windows completes in the
+// background after they're canceled. It is one of the [DefaultFilters].
+func IgnorePlatformStacks() Option {
+	return addFilter("IgnorePlatformStacks()", isPlatformStack)
+}
+
+// DefaultFilters returns the filters that every leak check applies
+// unless [DisableDefaultFilters] is given.
+// Together with DisableDefaultFilters, it allows selecting
+// all but some of the defaults:
+//
+//	goleak.VerifyNone(t,
+//		goleak.DisableDefaultFilters(),
+//		goleak.IgnoreTestStacks(),
+//		goleak.IgnoreStdLibStacks(),
+//	)
+func DefaultFilters() []Option {
+	return []Option{
+		IgnoreTestStacks(),
+		IgnoreCgoCallbacks(),
+		IgnoreStdLibStacks(),
+		IgnoreTraceStacks(),
+		IgnoreFuzzStacks(),
+		IgnoreRuntimeGCStacks(),
+		IgnorePlatformStacks(),
+		IgnoreInstrumentationStacks(),
+	}
+}
+
+// DisableDefaultFilters removes the [DefaultFilters] from a leak check,
+// so that only goroutines excluded by other options are ignored.
+func DisableDefaultFilters() Option {
+	return optionFunc(func(opts *opts) {
+		opts.defaultFilters = nil
+	})
+}
+
+// DisableDefaultFilter removes the given filters from the [DefaultFilters]
+// of a leak check, keeping the others:
+//
+//	goleak.VerifyNone(t, goleak.DisableDefaultFilter(goleak.IgnoreStdLibStacks()))
+//
+// Options that aren't among the DefaultFilters have no effect.
+func DisableDefaultFilter(filters ...Option) Option {
+	names := make(map[string]struct{})
+	for _, f := range filters {
+		var o opts
+		f.apply(&o)
+		for _, f := range o.filters {
+			names[f.name] = struct{}{}
+		}
+	}
+	return optionFunc(func(opts *opts) {
+		// Build a new slice since defaults may be shared.
+		kept := make([]filter, 0, len(opts.defaultFilters))
+		for _, f := range opts.defaultFilters {
+			if _, ok := names[f.name]; !ok {
+				kept = append(kept, f)
+			}
+		}
+		opts.defaultFilters = kept
+	})
+}
+
+// MaxDumpBytes limits the size of the goroutine dumps taken by leak checks
+// to n bytes. By default, dumps grow until they fit all goroutines,
+// which can take tens of megabytes in programs with many goroutines.
+// Goroutines that don't fit in the limit are not checked, so checks
+// whose dump was cut off fail even if they found no leaks, and note
+// it in their errors otherwise.
+//
+// Buffers for dumps are reused between checks regardless of this option,
+// so checks that run repeatedly, e.g. with [DetectGrowth],
+// allocate little once the buffer has grown.
+func MaxDumpBytes(n int) Option {
+	checkNotNegative("MaxDumpBytes", n)
+	return optionFunc(func(opts *opts) {
+		opts.maxDumpBytes = n
+	})
+}
+
+// StableAfter makes leak checks stop retrying once the remaining
+// goroutines, by ID and state, have been the same for k consecutive
+// attempts, rather than using up all retries on goroutines that won't
+// exit. Only attempts at least a tenth of the longest retry delay apart
+// are compared, so that exiting goroutines get time to make progress;
+// with the default retries, failing checks then fail in about a third
+// of the time.
+//
+// Checks stop after 3 stable attempts by default. StableAfter(0) turns
+// this off, so that checks retry as often as they are allowed to.
+func StableAfter(k int) Option {
+	checkNotNegative("StableAfter", k)
+	return optionFunc(func(opts *opts) {
+		opts.stableAfter = k
 	})
 }
 
@@ -173,33 +845,159 @@ func maxSleep(d time.Duration) Option {
 	})
 }
 
-func addFilter(f func(stack.Stack) bool) Option {
+// filter excludes the goroutines it matches from leak checks.
+type filter struct {
+	// name describes the option that added the filter,
+	// e.g. `IgnoreTopFunction("example.com/foo.worker")`.
+	name  string
+	match func(stack.Stack) bool
+	// internal is set for filters that checks add on their own,
+	// which aren't tracked by FilterUsage.
+	internal bool
+}
+
+func addFilter(name string, f func(stack.Stack) bool) Option {
+	return optionFunc(func(opts *opts) {
+		opts.filters = append(opts.filters, filter{name: name, match: f})
+	})
+}
+
+func addInclude(f func(stack.Stack) bool) Option {
+	return optionFunc(func(opts *opts) {
+		opts.includes = append(opts.includes, f)
+	})
+}
+
+// Options set by SetDefaults.
+var (
+	_defaultOptionsMu sync.RWMutex
+	_defaultOptions   []Option
+)
+
+// SetDefaults sets options that are applied to every leak check
+// before the options passed to it, e.g. from TestMain or an init function:
+//
+//	func TestMain(m *testing.M) {
+//		goleak.SetDefaults(goleak.IgnoreTopFunction("example.com/pkg.worker"))
+//		goleak.VerifyTestMain(m)
+//	}
+//
+// Each call replaces the previous defaults; call SetDefaults without
+// options to clear them. SetDefaults is safe for concurrent use.
+// Cleanup must not be passed to SetDefaults as it would prevent Find from running.
+func SetDefaults(options ...Option) {
+	_defaultOptionsMu.Lock()
+	defer _defaultOptionsMu.Unlock()
+	_defaultOptions = append([]Option(nil), options...)
+}
+
+// _profileEnv names the environment variable that selects
+// a profile for every leak check.
+const _profileEnv = "GOLEAK_PROFILE"
+
+// Profiles registered with Profile.
+var (
+	_profilesMu sync.RWMutex
+	_profiles   = make(map[string][]Option)
+)
+
+// Profile registers a named set of options, e.g. with different
+// suppressions and timing for unit, integration and e2e tests.
+// Registering a profile with an existing name replaces it.
+//
+// Profiles are selected per leak check with [UseProfile],
+// or for all leak checks by setting the GOLEAK_PROFILE environment
+// variable to the name of a profile. Profiles selected by GOLEAK_PROFILE
+// are applied after [SetDefaults] and before the options of each check;
+// like with UseProfile, leak checks panic if no profile with that name
+// was registered, so that a misspelled name doesn't go unnoticed.
+func Profile(name string, options ...Option) {
+	_profilesMu.Lock()
+	defer _profilesMu.Unlock()
+	_profiles[name] = append([]Option(nil), options...)
+}
+
+func lookupProfile(name string) ([]Option, bool) {
+	_profilesMu.RLock()
+	defer _profilesMu.RUnlock()
+	options, ok := _profiles[name]
+	return options, ok
+}
+
+// UseProfile applies the options of the profile registered under name
+// with [Profile]. It panics if no such profile was registered
+// by the time the option is used.
+func UseProfile(name string) Option {
 	return optionFunc(func(opts *opts) {
-		opts.filters = append(opts.filters, f)
+		options, ok := lookupProfile(name)
+		if !ok {
+			panic(fmt.Sprintf("goleak: unknown profile %q", name))
+		}
+		for _, option := range options {
+			option.apply(opts)
+		}
 	})
 }
 
 func buildOpts(options ...Option) *opts {
 	opts := &opts{
-		maxRetries: _defaultRetries,
-		maxSleep:   100 * time.Millisecond,
-	}
-	opts.filters = append(opts.filters,
-		isTestStack,
-		isSyscallStack,
-		isStdLibStack,
-		isTraceStack,
-	)
+		maxRetries:     _defaultRetries,
+		stableAfter:    _defaultStableAfter,
+		maxSleep:       100 * time.Millisecond,
+		failFastStates: _defaultFailFastStates,
+		defaultFilters: builtinFilters(),
+		leakExitCode:   1,
+	}
+
+	_defaultOptionsMu.RLock()
+	defaults := _defaultOptions
+	_defaultOptionsMu.RUnlock()
+	for _, option := range defaults {
+		option.apply(opts)
+	}
+
+	if name := os.Getenv(_profileEnv); name != "" {
+		profile, ok := lookupProfile(name)
+		if !ok {
+			panic(fmt.Sprintf("goleak: unknown profile %q in %v", name, _profileEnv))
+		}
+		for _, option := range profile {
+			option.apply(opts)
+		}
+	}
+
 	for _, option := range options {
 		option.apply(opts)
 	}
 	return opts
 }
 
+// builtinFilters returns the filters behind DefaultFilters.
+func builtinFilters() []filter {
+	return []filter{
+		{name: "IgnoreTestStacks()", match: isTestStack},
+		{name: _cgoCallbacksFilter, match: isCgoStack},
+		{name: "IgnoreStdLibStacks()", match: isStdLibStack},
+		{name: "IgnoreTraceStacks()", match: isTraceStack},
+		{name: "IgnoreFuzzStacks()", match: isFuzzStack},
+		{name: "IgnoreRuntimeGCStacks()", match: isRuntimeGCStack},
+		{name: "IgnorePlatformStacks()", match: isPlatformStack},
+		{name: "IgnoreInstrumentationStacks()", match: isInstrumentationStack},
+	}
+}
+
+// buildOnlyOpts builds the options for FindOnly and VerifyOnly.
+// Unlike buildOpts, it ignores the options set by SetDefaults and
+// GOLEAK_PROFILE, since they describe the goroutines of the whole
+// program rather than the included ones. The built-in filters still
+// apply so that goroutines of the testing package are never reported.
 func buildOnlyOpts(options ...Option) *opts {
 	opts := &opts{
-		maxRetries: _defaultRetries,
-		maxSleep:   100 * time.Millisecond,
+		maxRetries:     _defaultRetries,
+		stableAfter:    _defaultStableAfter,
+		maxSleep:       100 * time.Millisecond,
+		failFastStates: _defaultFailFastStates,
+		defaultFilters: builtinFilters(),
 	}
 	for _, option := range options {
 		option.apply(opts)
@@ -207,15 +1005,96 @@ func buildOnlyOpts(options ...Option) *opts {
 	return opts
 }
 
+// _excludedByIncludes names the reason for excluding goroutines
+// that match none of the Include options.
+const _excludedByIncludes = "Include options"
+
+// filter reports whether s is excluded from the leak check.
 func (o *opts) filter(s stack.Stack) bool {
-	for _, filter := range o.filters {
-		if filter(s) {
+	return o.excludedBy(s) != ""
+}
+
+// excludedBy returns the name of the option that excludes s
+// from the leak check, or "" if s is checked.
+func (o *opts) excludedBy(s stack.Stack) string {
+	if len(o.includes) > 0 && !o.include(s) {
+		return _excludedByIncludes
+	}
+	for _, f := range o.defaultFilters {
+		if f.match(s) {
+			return f.name
+		}
+	}
+	for _, f := range o.filters {
+		if f.match(s) {
+			return f.name
+		}
+	}
+	for _, t := range o.ageTrackers {
+		if s.ID() == t.self {
+			return _excludedAsChecker
+		}
+	}
+	for _, w := range o.rulesWatchers {
+		if s.ID() == w.self {
+			return _excludedAsChecker
+		}
+		if name := w.rules().excludedBy(s); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// filterFuncs returns the functions of the filters given as options.
+func (o *opts) filterFuncs() []func(stack.Stack) bool {
+	funcs := make([]func(stack.Stack) bool, len(o.filters))
+	for i, f := range o.filters {
+		funcs[i] = f.match
+	}
+	return funcs
+}
+
+// stacks captures the stacks of all goroutines, subject to MaxDumpBytes.
+// Concurrent calls share dumps.
+func (o *opts) stacks() []stack.Stack {
+	stacks, _ := o.dump()
+	return stacks
+}
+
+// dump is like stacks, and also reports whether goroutines were left
+// out of the dump by MaxDumpBytes.
+func (o *opts) dump() (stacks []stack.Stack, truncated bool) {
+	stacks, truncated = _dumps.stacks(o.maxDumpBytes)
+	if o.label != "" {
+		stacks = labeled(stacks, o.label)
+	}
+	return stacks, truncated
+}
+
+// include reports whether s matches any of the Include options.
+func (o *opts) include(s stack.Stack) bool {
+	for _, include := range o.includes {
+		if include(s) {
 			return true
 		}
 	}
 	return false
 }
 
+// failFast reports whether any of the given stacks is in a state
+// that cannot resolve by itself, making further retries pointless.
+func (o *opts) failFast(stacks []stack.Stack) bool {
+	for _, s := range stacks {
+		for _, state := range o.failFastStates {
+			if strings.HasPrefix(s.State(), state) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (o *opts) retry(i int) bool {
 	if i >= o.maxRetries {
 		return false
@@ -225,8 +1104,59 @@ func (o *opts) retry(i int) bool {
 	if d > o.maxSleep {
 		d = o.maxSleep
 	}
-	time.Sleep(d)
-	return true
+	if !o.deadline.IsZero() {
+		remaining := time.Until(o.deadline)
+		if remaining <= 0 {
+			if o.logger != nil {
+				o.logger.Debug("goleak: not retrying past the deadline of the test", "attempt", i+1)
+			}
+			return false
+		}
+		d = min(d, remaining)
+	}
+	if o.ctx == nil {
+		time.Sleep(d)
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-o.ctx.Done():
+		return false
+	}
+}
+
+// stability tracks the goroutines remaining after the attempts
+// of a leak check for StableAfter.
+type stability struct {
+	key      string // IDs and states of the remaining goroutines
+	attempts int    // consecutive attempts that found the same key
+}
+
+// stable reports whether the goroutines remaining after attempt i
+// are the same as those of the previous StableAfter attempts.
+func (o *opts) stable(st *stability, i int, stacks []stack.Stack) bool {
+	if o.stableAfter == 0 {
+		return false
+	}
+
+	var sb strings.Builder
+	for _, s := range stacks {
+		fmt.Fprintf(&sb, "%d %s\n", s.ID(), s.State())
+	}
+	key := sb.String()
+
+	// Attempt i follows a delay of about 1µs<<(i-1).
+	settled := i > 0 && time.Microsecond<<min(i-1, 30) >= o.maxSleep/10
+	switch {
+	case key != st.key:
+		st.key, st.attempts = key, 0
+	case settled:
+		st.attempts++
+	}
+	return st.attempts >= o.stableAfter
 }
 
 // isTestStack is a default filter installed to automatically skip goroutines
@@ -242,8 +1172,11 @@ func isTestStack(s stack.Stack) bool {
 	// function with all seed corpus have run.
 	// testing.runFuzzing is for fuzz testing, it's blocked until a failing
 	// input is found.
+	// testing.(*F).Fuzz.func1 runs a fuzz test, it's blocked while each input
+	// runs in a separate goroutine.
 	switch s.FirstFunction() {
-	case "testing.RunTests", "testing.(*T).Run", "testing.(*T).Parallel", "testing.runFuzzing", "testing.runFuzzTests":
+	case "testing.RunTests", "testing.(*T).Run", "testing.(*T).Parallel", "testing.runFuzzing", "testing.runFuzzTests",
+		"testing.(*F).Fuzz.func1":
 		// In pre1.7 and post-1.7, background goroutines started by the testing
 		// package are blocked waiting on a channel.
 		return strings.HasPrefix(s.State(), "chan receive")
@@ -254,7 +1187,7 @@ func isTestStack(s stack.Stack) bool {
 func isSyscallStack(s stack.Stack) bool {
 	// Typically runs in the background when code uses CGo:
 	// https://github.com/golang/go/issues/16714
-	return s.HasFunction("runtime.goexit") && strings.HasPrefix(s.State(), "syscall")
+	return strings.HasPrefix(s.State(), "syscall") && s.HasFunction("runtime.goexit")
 }
 
 func isStdLibStack(s stack.Stack) bool {
@@ -267,3 +1200,40 @@ func isStdLibStack(s stack.Stack) bool {
 	// Using signal.Notify will start a runtime goroutine.
 	return s.HasFunction("runtime.ensureSigM")
 }
+
+// isFuzzStack is a default filter installed to skip goroutines run by the
+// fuzzing engine, both in the coordinating process and in its workers.
+// This includes goroutines started on its behalf, e.g. by os/exec
+// to manage worker processes.
+func isFuzzStack(s stack.Stack) bool {
+	const fuzzPkg = "internal/fuzz."
+	// Check the raw trace first so that other stacks aren't parsed in full.
+	if strings.Contains(s.Full(), fuzzPkg) {
+		if strings.HasPrefix(s.CreatedBy(), fuzzPkg) {
+			return true
+		}
+		if s.HasFunctionFunc(func(name string) bool { return strings.HasPrefix(name, fuzzPkg) }) {
+			return true
+		}
+	}
+
+	// The testing package stops fuzzing on interrupt with signal.NotifyContext,
+	// whose goroutine doesn't reference the fuzzing engine.
+	return s.FirstFunction() == "os/signal.NotifyContext.func1" && isFuzzing()
+}
+
+func isRuntimeGCStack(s stack.Stack) bool {
+	return s.IsRuntimeGC()
+}
+
+// isFuzzing reports whether this is a test binary that is fuzzing,
+// either as the coordinator or as one of its workers.
+func isFuzzing() bool {
+	if f := flag.Lookup("test.fuzzworker"); f != nil && f.Value.String() == "true" {
+		return true
+	}
+	if f := flag.Lookup("test.fuzz"); f != nil && f.Value.String() != "" {
+		return true
+	}
+	return false
+}
@@ -21,6 +21,10 @@
 package goleak
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -71,11 +75,36 @@ func TestOptionsFilters(t *testing.T) {
 		"startBlockedG should not be filtered out. running: %v", stack.All())
 }
 
+func TestOptionsDefaultFilters(t *testing.T) {
+	cur := stack.Current()
+	all := getStableAll(t, cur)
+
+	countUnfiltered := func(opts *opts) int {
+		var unmatched int
+		for _, s := range all {
+			if s.ID() != cur.ID() && !opts.filter(s) {
+				unmatched++
+			}
+		}
+		return unmatched
+	}
+
+	require.Zero(t, countUnfiltered(buildOpts()))
+	require.NotZero(t, countUnfiltered(buildOpts(DisableDefaultFilters())),
+		"the testing package's goroutines should not be filtered without default filters")
+	require.NotZero(t, countUnfiltered(buildOpts(DisableDefaultFilters(), IgnoreSyscallStacks())),
+		"the testing package's goroutines should not be filtered without IgnoreTestStacks")
+
+	defaults := append([]Option{DisableDefaultFilters()}, DefaultFilters()...)
+	require.Zero(t, countUnfiltered(buildOpts(defaults...)),
+		"DefaultFilters should restore the default behavior")
+}
+
 func TestOptionsIgnoreAnyFunction(t *testing.T) {
 	cur := stack.Current()
 	opts := buildOpts(IgnoreAnyFunction("github.com/projectdiscovery/goleak.(*blockedG).run"))
 
-	for _, s := range stack.All() {
+	for _, s := range getStableAll(t, cur) {
 		if s.ID() == cur.ID() {
 			continue
 		}
@@ -88,54 +117,272 @@ func TestOptionsIgnoreAnyFunction(t *testing.T) {
 	}
 }
 
-func TestOptionsIgnoreAnyContainingPkg(t *testing.T) {
-	cur := stack.Current()
-	opts := buildOnlyOpts(IgnoreAnyContainingPkg("testing"))
+func TestOptionsIgnoreFunctionGlob(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+	blocked := blockedStack(t)
 
-	for _, s := range stack.All() {
-		if s.ID() == cur.ID() {
-			continue
-		}
+	tests := []struct {
+		pattern string
+		ignored bool
+	}{
+		{`github.com/projectdiscovery/goleak.(\*blockedG).block`, true},
+		{`github.com/projectdiscovery/*.(\*blockedG).*`, true},
+		{`github.com/*/goleak.(\*blockedG).run`, true},
+		{`github.com/*.(\*blockedG).block`, false}, // '*' does not match '/'
+		{`github.com/projectdiscovery/goleak.(\*other).*`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			opts := buildOpts(IgnoreFunctionGlob(tt.pattern))
+			assert.Equal(t, tt.ignored, opts.filter(blocked))
+		})
+	}
 
-		if opts.filter(s) {
-			continue
-		}
+	assert.Panics(t, func() { IgnoreFunctionGlob("[a") }, "malformed patterns should panic")
+}
 
-		t.Errorf("Unexpected goroutine: %v", s)
+func TestOptionsRegexp(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	blocked := blockedStack(t)
+
+	tests := []struct {
+		desc    string
+		opt     Option
+		ignored bool
+	}{
+		{"any function", IgnoreFunctionRegexp(regexp.MustCompile(`\(\*blockedG\)\.run$`)), true},
+		{"any function mismatch", IgnoreFunctionRegexp(regexp.MustCompile(`^main\.`)), false},
+		{"top function", IgnoreTopFunctionRegexp(regexp.MustCompile(`\(\*blockedG\)\.block$`)), true},
+		{"top function mismatch", IgnoreTopFunctionRegexp(regexp.MustCompile(`\(\*blockedG\)\.run$`)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, buildOpts(tt.opt).filter(blocked))
+		})
 	}
+
+	assert.Panics(t, func() { IgnoreFunctionRegexp(nil) })
+	assert.Panics(t, func() { IgnoreTopFunctionRegexp(nil) })
 }
 
-func TestOptionsIncludeAllContainingPkg(t *testing.T) {
-	cur := stack.Current()
-	opts := buildOnlyOpts(IncludeAllContainingPkg("testing"))
+func TestOptionsIgnoreStack(t *testing.T) {
+	dump := []byte(strings.Join([]string{
+		"goroutine 7 [IO wait]:",
+		"internal/poll.runtime_pollWait(0x7f3c1a2b4e28, 0x72)",
+		"	/usr/local/go/src/runtime/netpoll.go:343 +0x85",
+		"github.com/miekg/dns.(*Server).serveUDP(0xc000120000, {0x7b0f60, 0xc000012345})",
+		"	/app/vendor/github.com/miekg/dns/server.go:453 +0x1d",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:20 +0x85",
+		"",
+	}, "\n"))
+	stacks, err := stack.ParseDump(dump)
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
 
-	for _, s := range stack.All() {
-		if s.ID() == cur.ID() {
-			continue
-		}
+	tests := []struct {
+		desc    string
+		opt     Option
+		ignored bool
+	}{
+		{"contains path", IgnoreStackContaining("/vendor/github.com/miekg/dns/"), true},
+		{"contains arguments", IgnoreStackContaining("(0xc000120000, "), true},
+		{"contains creator", IgnoreStackContaining("created by main.main"), true},
+		{"contains header", IgnoreStackContaining("IO wait"), false},
+		{"contains mismatch", IgnoreStackContaining("/vendor/golang.org/"), false},
+		{"matches path", IgnoreStackMatching(regexp.MustCompile(`/vendor/github\.com/miekg/dns/.+\.go:\d+`)), true},
+		{"matches whole line", IgnoreStackMatching(regexp.MustCompile(`^\t/app/main\.go:20 \+0x85$`)), true},
+		{"matches within a line only", IgnoreStackMatching(regexp.MustCompile(`runtime_pollWait.*netpoll`)), false},
+		{"matches header", IgnoreStackMatching(regexp.MustCompile(`^goroutine`)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, buildOpts(tt.opt).filter(stacks[0]))
+		})
+	}
 
-		if opts.filter(s) {
-			continue
+	assert.Panics(t, func() { IgnoreStackContaining("foo\nbar") })
+	assert.Panics(t, func() { IgnoreStackMatching(nil) })
+}
+
+func TestOptionsIgnoreFile(t *testing.T) {
+	dump := []byte(strings.Join([]string{
+		"goroutine 7 [IO wait]:",
+		"github.com/miekg/dns.(*Server).serveUDP(0xc000120000)",
+		"	/app/vendor/github.com/miekg/dns/server.go:453 +0x1d",
+		"github.com/Azure/go-autorest/autorest.(*Client).poll(...)",
+		"	/root/go/pkg/mod/github.com/!azure/go-autorest/autorest@v0.11.29/client.go:90",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:20 +0x85",
+		"",
+	}, "\n"))
+	stacks, err := stack.ParseDump(dump)
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+
+	tests := []struct {
+		desc    string
+		opt     Option
+		ignored bool
+	}{
+		{"file absolute", IgnoreFile("/app/vendor/github.com/miekg/dns/server.go"), true},
+		{"file absolute glob", IgnoreFile("/app/vendor/github.com/miekg/dns/*.go"), true},
+		{"file absolute must match from the root", IgnoreFile("/vendor/github.com/miekg/dns/*.go"), false},
+		{"file relative", IgnoreFile("vendor/github.com/miekg/dns/*.go"), true},
+		{"file base name", IgnoreFile("server.go"), true},
+		{"file mismatch", IgnoreFile("vendor/golang.org/*/*.go"), false},
+		{"file of creator", IgnoreFile("main.go"), false},
+		{"module vendored", IgnoreModule("github.com/miekg/dns"), true},
+		{"module cached", IgnoreModule("github.com/Azure/go-autorest/autorest"), true},
+		{"module parent", IgnoreModule("github.com/Azure/go-autorest"), false},
+		{"module prefix", IgnoreModule("github.com/miekg/dn"), false},
+		{"module mismatch", IgnoreModule("golang.org/x/net"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, buildOpts(tt.opt).filter(stacks[0]))
+		})
+	}
+
+	assert.Panics(t, func() { IgnoreFile("[") })
+}
+
+func TestOptionsIgnoreNonLocal(t *testing.T) {
+	goroutine := func(id int, createdBy string) string {
+		lines := []string{
+			fmt.Sprintf("goroutine %d [chan receive]:", id),
+			"example.com/worker.run()",
+			"	/app/worker.go:10 +0x1d",
 		}
+		if createdBy != "" {
+			lines = append(lines,
+				"created by "+createdBy+" in goroutine 1",
+				"	/app/start.go:20 +0x85",
+			)
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+	dump := strings.Join([]string{
+		goroutine(1, ""),
+		goroutine(2, "github.com/projectdiscovery/goleak.startWorker"),
+		goroutine(3, "github.com/projectdiscovery/goleak/stack.startWorker"),
+		goroutine(4, "main.main"),
+		goroutine(5, "github.com/projectdiscovery/goleakfoo.startWorker"),
+		goroutine(6, "net/http.(*Server).Serve"),
+	}, "\n")
 
-		t.Errorf("Unexpected goroutine: %v", s)
+	err := FindInDump([]byte(dump), IgnoreNonLocal())
+	require.Error(t, err)
+	for _, id := range []int{1, 2, 3, 4} {
+		assert.Contains(t, err.Error(), fmt.Sprintf("Goroutine %d ", id))
+	}
+	for _, id := range []int{5, 6} {
+		assert.NotContains(t, err.Error(), fmt.Sprintf("Goroutine %d ", id))
 	}
 }
 
+func TestIgnoreFrameWhere(t *testing.T) {
+	dump := []byte(`goroutine 7 [chan receive]:
+example.com/pool.worker(0xc000010000, 0x2a)
+	/app/pool.go:10 +0x85
+created by example.com/pool.Start in goroutine 1
+	/app/pool.go:5 +0x25
+
+goroutine 8 [chan receive]:
+example.com/pool.worker(0xc000010000, 0x7)
+	/app/pool.go:10 +0x85
+created by example.com/pool.Start in goroutine 1
+	/app/pool.go:5 +0x25
+`)
+	sentinel := IgnoreFrameWhere(func(e stack.Entry) bool {
+		args := e.Args()
+		return e.Function() == "example.com/pool.worker" &&
+			len(args) > 1 && args[1].Value == 0x2a
+	})
+
+	err := FindInDump(dump, sentinel)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Goroutine 7 ")
+	assert.Contains(t, err.Error(), "Goroutine 8 ")
+
+	t.Run("creator is not a frame", func(t *testing.T) {
+		err := FindInDump(dump, IgnoreFrameWhere(func(e stack.Entry) bool {
+			return e.Function() == "example.com/pool.Start"
+		}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Goroutine 7 ")
+	})
+
+	t.Run("nil function", func(t *testing.T) {
+		assert.Panics(t, func() { IgnoreFrameWhere(nil) })
+	})
+}
+
+func TestOptionsIgnoreAnyContainingPkg(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	blocked := blockedStack(t)
+	assert.True(t, buildOnlyOpts(IgnoreAnyContainingPkg("github.com/projectdiscovery/goleak")).filter(blocked))
+	assert.False(t, buildOnlyOpts(IgnoreAnyContainingPkg("github.com/projectdiscovery/goleak/stack")).filter(blocked),
+		"subpackages should not match their parent")
+	assert.False(t, buildOnlyOpts(IgnoreAnyContainingPkg("testing")).filter(blocked))
+}
+
+func TestOptionsIncludeAllContainingPkg(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	// Include options exclude the goroutines they don't match,
+	// rather than those they match.
+	blocked := blockedStack(t)
+	assert.False(t, buildOnlyOpts(IncludeAllContainingPkg("github.com/projectdiscovery/goleak")).filter(blocked))
+	assert.True(t, buildOnlyOpts(IncludeAllContainingPkg("testing")).filter(blocked))
+
+	stacks := FilterStacks(stack.All(), IncludeAllContainingPkg("github.com/projectdiscovery/goleak"),
+		IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	assert.Contains(t, stackIDs(stacks), blocked.ID())
+	assert.Empty(t, FilterStacks(stack.All(), IncludeAllContainingPkg("example.com/unused")))
+}
+
 func TestOptionsIgnoreAnyContainingStruct(t *testing.T) {
-	cur := stack.Current()
-	opts := buildOnlyOpts(IgnoreAnyContainingStruct("testing.(*M)"))
+	bg := startBlockedG()
+	defer bg.unblock()
 
-	for _, s := range stack.All() {
-		if s.ID() == cur.ID() {
-			continue
-		}
+	blocked := blockedStack(t)
+	assert.True(t, buildOnlyOpts(IgnoreAnyContainingStruct("github.com/projectdiscovery/goleak.(*blockedG)")).filter(blocked))
+	assert.False(t, buildOnlyOpts(IgnoreAnyContainingStruct("github.com/projectdiscovery/goleak.(*opts)")).filter(blocked))
+	assert.False(t, buildOnlyOpts(IgnoreAnyContainingStruct("testing.(*M)")).filter(blocked))
+}
 
-		if opts.filter(s) {
-			continue
-		}
+func TestOptionsInclude(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
 
-		t.Errorf("Unexpected goroutine: %v", s)
+	blocked := blockedStack(t)
+
+	tests := []struct {
+		desc    string
+		opt     Option
+		include bool
+	}{
+		{"top function", IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"), true},
+		{"other top function", IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).run"), false},
+		{"created by", IncludeCreatedBy("github.com/projectdiscovery/goleak.startBlockedG"), true},
+		{"other created by", IncludeCreatedBy("github.com/projectdiscovery/goleak.(*blockedG).run"), false},
+		{"struct", IncludeAnyContainingStruct("github.com/projectdiscovery/goleak.(*blockedG)"), true},
+		{"other struct", IncludeAnyContainingStruct("github.com/projectdiscovery/goleak.(*opts)"), false},
+		{"package", IncludeAllContainingPkg("github.com/projectdiscovery/goleak"), true},
+		{"other package", IncludeAllContainingPkg("github.com/projectdiscovery/goleak/stack"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			opts := buildOnlyOpts(tt.opt)
+			assert.Equal(t, tt.include, !opts.filter(blocked))
+		})
 	}
 }
 
@@ -150,3 +397,233 @@ func TestOptionsRetry(t *testing.T) {
 	assert.False(t, opts.retry(51), "Attempt 51/51 should not allow retrying")
 	assert.False(t, opts.retry(52), "Attempt 52/51 should not allow retrying")
 }
+
+func TestOptionsFailFast(t *testing.T) {
+	stacks, err := stack.ParseStack([]byte(strings.Join([]string{
+		"goroutine 1 [chan receive (nil chan)]:",
+		"main.nilRecv()",
+		"	/path/to/main.go:10",
+		"",
+		"goroutine 2 [chan receive, 3 minutes]:",
+		"main.recv()",
+		"	/path/to/main.go:20",
+		"",
+		"goroutine 3 [semacquire]:",
+		"main.lock()",
+		"	/path/to/main.go:30",
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+	require.Len(t, stacks, 3)
+	nilRecv, recv, sema := stacks[0:1], stacks[1:2], stacks[2:3]
+
+	opts := buildOpts()
+	assert.True(t, opts.failFast(nilRecv), "nil channel receive can never resolve")
+	assert.False(t, opts.failFast(recv), "channel receive may resolve")
+	assert.False(t, opts.failFast(sema), "semacquire may resolve")
+	assert.True(t, opts.failFast(stacks), "any fail-fast goroutine should fail fast")
+
+	opts = buildOpts(FailFastStates("semacquire", "chan receive"))
+	assert.True(t, opts.failFast(recv), "custom state prefix should match")
+	assert.True(t, opts.failFast(sema), "custom state should match")
+
+	opts = buildOpts(FailFastStates())
+	assert.False(t, opts.failFast(stacks), "no states should disable failing fast")
+}
+
+func TestOptionsFuzzStack(t *testing.T) {
+	stacks, err := stack.ParseStack([]byte(strings.Join([]string{
+		"goroutine 5 [select]:",
+		"internal/fuzz.(*worker).coordinate(0xc0000f6000)",
+		"	/usr/lib/go/src/internal/fuzz/worker.go:119 +0x1e5",
+		"created by internal/fuzz.CoordinateFuzzing in goroutine 1",
+		"	/usr/lib/go/src/internal/fuzz/fuzz.go:127 +0x8e5",
+		"",
+		"goroutine 6 [syscall]:",
+		"os.(*Process).Wait(0xc000012000)",
+		"	/usr/lib/go/src/os/exec_unix.go:43 +0x65",
+		"created by internal/fuzz.(*worker).start in goroutine 5",
+		"	/usr/lib/go/src/internal/fuzz/worker.go:337 +0x4f5",
+		"",
+		"goroutine 7 [chan receive]:",
+		"example.com/fuzz.worker()",
+		"	/app/fuzz.go:10 +0x1",
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+	require.Len(t, stacks, 3)
+
+	assert.True(t, isFuzzStack(stacks[0]), "fuzzing engine goroutine")
+	assert.True(t, isFuzzStack(stacks[1]), "goroutine created by the fuzzing engine")
+	assert.False(t, isFuzzStack(stacks[2]), "user goroutine")
+}
+
+func TestSetDefaults(t *testing.T) {
+	t.Cleanup(func() { SetDefaults() })
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	SetDefaults(IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	require.NoError(t, Find(), "defaults should apply to Find")
+
+	ft := &fakeT{}
+	VerifyNone(ft)
+	require.Empty(t, ft.errors, "defaults should apply to VerifyNone")
+
+	SetDefaults()
+	require.Error(t, Find(testOptions()), "clearing defaults should find the leak")
+}
+
+func TestSetDefaultsConcurrent(t *testing.T) {
+	t.Cleanup(func() { SetDefaults() })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefaults(maxSleep(time.Millisecond))
+		}()
+		go func() {
+			defer wg.Done()
+			buildOpts()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProfiles(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	Profile("test-ignore-blocked", IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	Profile("test-fast", testOptions())
+
+	t.Run("UseProfile", func(t *testing.T) {
+		require.NoError(t, Find(UseProfile("test-ignore-blocked")))
+		require.Error(t, Find(UseProfile("test-fast")))
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		assert.PanicsWithValue(t, `goleak: unknown profile "test-missing"`, func() {
+			_ = Find(UseProfile("test-missing"))
+		})
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("GOLEAK_PROFILE", "test-ignore-blocked")
+		require.NoError(t, Find())
+
+		t.Setenv("GOLEAK_PROFILE", "test-missing")
+		assert.PanicsWithValue(t, `goleak: unknown profile "test-missing" in GOLEAK_PROFILE`, func() {
+			_ = Find(testOptions())
+		})
+	})
+}
+
+func TestCombine(t *testing.T) {
+	var cleanups []string
+	cleanup := func(name string) Option {
+		return Cleanup(func(int) { cleanups = append(cleanups, name) })
+	}
+
+	suite := Options{
+		IgnoreTopFunction("example.com/foo.worker"),
+		cleanup("suite"),
+		MaxDumpBytes(1 << 10),
+	}
+	db := Combine(
+		IgnoreAnyFunction("example.com/db.(*Pool).reaper"),
+		cleanup("db"),
+		MaxDumpBytes(1<<20),
+	)
+	opts := buildOpts(suite, db, Options{IncludeTopFunction("example.com/foo.run")})
+
+	var names []string
+	for _, f := range opts.filters {
+		names = append(names, f.name)
+	}
+	assert.Equal(t, []string{
+		`IgnoreTopFunction("example.com/foo.worker")`,
+		`IgnoreAnyFunction("example.com/db.(*Pool).reaper")`,
+	}, names, "filters should append")
+	assert.Len(t, opts.includes, 1, "nested bundles should apply")
+	assert.Equal(t, 1<<20, opts.maxDumpBytes, "the last setting should win")
+
+	require.NotNil(t, opts.cleanup)
+	opts.cleanup(0)
+	assert.Equal(t, []string{"suite", "db"}, cleanups, "cleanups should chain")
+}
+
+func TestMaxDumpBytes(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	err := Find(testOptions(), MaxDumpBytes(1<<20))
+	require.Error(t, err, "blockedG should fit in a large dump")
+	assert.NotContains(t, err.Error(), "MaxDumpBytes")
+
+	err = Find(testOptions(), MaxDumpBytes(1))
+	require.EqualError(t, err, "goroutine dump exceeded MaxDumpBytes(1), "+
+		"so goroutines that didn't fit in it weren't checked",
+		"a truncated dump should not pass")
+
+	require.Error(t, Find(testOptions(), MaxDumpBytes(1), Pretty()))
+
+	// Leaks found in a truncated dump may not be the only ones.
+	o := buildOpts(MaxDumpBytes(4096))
+	assert.Equal(t, "\ngoroutine dump exceeded MaxDumpBytes(4096); goroutines that didn't fit in it weren't checked\n",
+		o.notes(nil, leakCheck{dumpTruncated: true}))
+}
+
+func TestOptionsRuntimeGCStack(t *testing.T) {
+	dump := []byte(strings.Join([]string{
+		"goroutine 18 [GC worker (idle)]:",
+		"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/proc.go:424 +0xce",
+		"runtime.gcBgMarkWorker(0xc000100000)",
+		"	/usr/local/go/src/runtime/mgc.go:1363 +0xe5",
+		"created by runtime.gcBgMarkStartWorkers in goroutine 1",
+		"	/usr/local/go/src/runtime/mgc.go:1279 +0x105",
+		"",
+	}, "\n"))
+
+	require.NoError(t, FindInDump(dump))
+	require.Error(t, FindInDump(dump, DisableDefaultFilters()))
+}
+
+func TestStableAfter(t *testing.T) {
+	t.Run("stable", func(t *testing.T) {
+		stacks, err := stack.ParseDump([]byte("goroutine 7 [chan receive]:\nmain.worker()\n\t/app/main.go:10 +0x25\n"))
+		require.NoError(t, err)
+		opts := buildOpts(testOptions(), StableAfter(2))
+
+		var st stability
+		for i := 0; i <= 8; i++ {
+			assert.False(t, opts.stable(&st, i, stacks), "attempt %d is too early", i)
+		}
+		assert.True(t, opts.stable(&st, 9, stacks))
+		assert.False(t, opts.stable(&st, 10, nil), "changed goroutines reset the count")
+		assert.False(t, opts.stable(&st, 11, nil))
+		assert.True(t, opts.stable(&st, 12, nil))
+
+		assert.False(t, buildOpts(StableAfter(0)).stable(&stability{}, 20, stacks))
+	})
+
+	t.Run("leak", func(t *testing.T) {
+		bg := startBlockedG()
+		defer bg.unblock()
+
+		var stats Stats
+		require.Error(t, Find(testOptions(), OnStats(func(s Stats) { stats = s })))
+		assert.Less(t, stats.Retries, _defaultRetries, "should stop retrying early")
+
+		require.Error(t, Find(testOptions(), StableAfter(0), OnStats(func(s Stats) { stats = s })))
+		assert.Equal(t, _defaultRetries, stats.Retries)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		assert.Panics(t, func() { StableAfter(-1) })
+	})
+}
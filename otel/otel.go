@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package otel records goleak results as OpenTelemetry metrics and spans.
+//
+// A Recorder reports the number of leaked goroutines found by its most
+// recent leak check as a gauge, and turns growth reported by
+// [goleak.DetectGrowth] into a counter and, optionally, span events.
+//
+//	rec, err := otel.NewRecorder(meterProvider, otel.WithTracerProvider(tracerProvider))
+//	if err != nil {
+//		return err
+//	}
+//	go rec.Watch(ctx, goleak.DetectGrowth(ctx, time.Minute, 10))
+package otel
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/stack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _instrumentationName = "github.com/projectdiscovery/goleak/otel"
+
+// Option configures a Recorder.
+type Option interface {
+	apply(*Recorder)
+}
+
+type optionFunc func(*Recorder)
+
+func (f optionFunc) apply(r *Recorder) { f(r) }
+
+// WithTracerProvider makes the Recorder emit a span with an event per
+// growing fingerprint for every growth report it receives in Watch.
+// Without it, no spans are emitted.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionFunc(func(r *Recorder) {
+		r.tracer = tp.Tracer(_instrumentationName)
+	})
+}
+
+// Recorder records leak check results as OpenTelemetry metrics.
+type Recorder struct {
+	tracer trace.Tracer // nil if spans are disabled
+	growth metric.Int64Counter
+	leaks  atomic.Int64 // result of the last leak check
+}
+
+// NewRecorder builds a Recorder that creates its instruments
+// with the given MeterProvider:
+//
+//   - goleak.leaks: gauge of leaked goroutines found by the last check
+//   - goleak.growth: counter of goroutines added by sustained growth
+func NewRecorder(mp metric.MeterProvider, options ...Option) (*Recorder, error) {
+	r := &Recorder{}
+	for _, opt := range options {
+		opt.apply(r)
+	}
+
+	meter := mp.Meter(_instrumentationName)
+	var err error
+	_, err = meter.Int64ObservableGauge("goleak.leaks",
+		metric.WithDescription("Number of leaked goroutines found by the last leak check."),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(r.leaks.Load())
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.growth, err = meter.Int64Counter("goleak.growth",
+		metric.WithDescription("Number of goroutines added by sustained growth, per fingerprint."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Find runs [goleak.Find] with the given options and records the number
+// of leaked goroutines it found.
+// Any OnLeak option in options is replaced by the Recorder's own.
+func (r *Recorder) Find(options ...goleak.Option) error {
+	var leaks int
+	options = append(options, goleak.OnLeak(func(stacks []stack.Stack) {
+		leaks = len(stacks)
+	}))
+	err := goleak.Find(options...)
+	r.leaks.Store(int64(leaks))
+	return err
+}
+
+// Watch records every report received from reports
+// until the channel is closed or ctx is done.
+func (r *Recorder) Watch(ctx context.Context, reports <-chan goleak.GrowthReport) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case report, ok := <-reports:
+			if !ok {
+				return
+			}
+			r.RecordGrowth(ctx, report)
+		}
+	}
+}
+
+// RecordGrowth records a single growth report.
+func (r *Recorder) RecordGrowth(ctx context.Context, report goleak.GrowthReport) {
+	var span trace.Span
+	if r.tracer != nil {
+		ctx, span = r.tracer.Start(ctx, "goleak.growth", trace.WithTimestamp(report.Time))
+		defer span.End()
+	}
+
+	for _, g := range report.Growth {
+		attrs := []attribute.KeyValue{
+			attribute.String("goleak.fingerprint", g.Fingerprint),
+			attribute.String("goleak.function", g.Example.FirstFunction()),
+		}
+		r.growth.Add(ctx, int64(g.To-g.From), metric.WithAttributes(attrs...))
+
+		if span != nil {
+			span.AddEvent("goroutine growth", trace.WithAttributes(append(attrs,
+				attribute.Int("goleak.from", g.From),
+				attribute.Int("goleak.to", g.To),
+				attribute.String("goleak.window", report.Window.String()),
+			)...))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func blockedFunc(ch chan struct{}) {
+	<-ch
+}
+
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	got := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+	return got
+}
+
+func TestRecorderFind(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	rec, err := NewRecorder(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go blockedFunc(done)
+
+	require.Error(t, rec.Find(goleak.FailFastStates("chan receive")))
+	gauge := collect(t, reader)["goleak.leaks"].(metricdata.Gauge[int64])
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, int64(1), gauge.DataPoints[0].Value)
+
+	close(done)
+	require.NoError(t, rec.Find())
+	gauge = collect(t, reader)["goleak.leaks"].(metricdata.Gauge[int64])
+	assert.Equal(t, int64(0), gauge.DataPoints[0].Value)
+}
+
+func TestRecorderGrowth(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	spans := tracetest.NewSpanRecorder()
+	rec, err := NewRecorder(
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+	)
+	require.NoError(t, err)
+
+	stacks, err := stack.ParseStack([]byte("goroutine 1 [select]:\nexample.com/foo.worker()\n\t/foo.go:1\n"))
+	require.NoError(t, err)
+
+	reports := make(chan goleak.GrowthReport, 1)
+	reports <- goleak.GrowthReport{
+		Time:   time.Now(),
+		Window: time.Minute,
+		Growth: []goleak.FingerprintGrowth{
+			{Fingerprint: "abc", From: 2, To: 7, Example: stacks[0]},
+		},
+	}
+	close(reports)
+	rec.Watch(context.Background(), reports)
+
+	sum := collect(t, reader)["goleak.growth"].(metricdata.Sum[int64])
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(5), sum.DataPoints[0].Value)
+	fp, ok := sum.DataPoints[0].Attributes.Value("goleak.fingerprint")
+	require.True(t, ok)
+	assert.Equal(t, "abc", fp.AsString())
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "goleak.growth", ended[0].Name())
+	require.Len(t, ended[0].Events(), 1)
+	assert.Equal(t, "goroutine growth", ended[0].Events()[0].Name)
+}
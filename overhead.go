@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import "time"
+
+// MaxOverheadPercent bounds the share of time that the periodic checks
+// of [DetectGrowth] and [StartTimeline] spend capturing and parsing
+// goroutine dumps, for busy services where a dump of every goroutine
+// at every interval is too expensive, e.g. proxies with 100k goroutines.
+//
+// After each dump, sampling is skipped until the time since the dump
+// started is at least 100/percent times what the dump took: with
+// MaxOverheadPercent(1), a dump that took 20ms is followed by no other
+// dump for 2s. Skipped intervals of DetectGrowth reuse the previous
+// sample, so growth is only seen at the intervals that do take dumps,
+// and skipped intervals of a Timeline are left out of its samples.
+//
+// percent must be greater than 0 and at most 100.
+func MaxOverheadPercent(percent float64) Option {
+	if !(percent > 0 && percent <= 100) {
+		invalidOption("MaxOverheadPercent", "percent %v must be in (0, 100]", percent)
+	}
+	return optionFunc(func(opts *opts) {
+		opts.maxOverheadPercent = percent
+	})
+}
+
+// overheadBudget decides when periodic checks may take another dump
+// under MaxOverheadPercent. A nil budget allows every dump.
+type overheadBudget struct {
+	percent float64
+	next    time.Time // earliest time of the next dump
+}
+
+// overheadBudget returns the budget for a periodic check,
+// or nil if MaxOverheadPercent wasn't given.
+func (o *opts) overheadBudget() *overheadBudget {
+	if o.maxOverheadPercent == 0 {
+		return nil
+	}
+	return &overheadBudget{percent: o.maxOverheadPercent}
+}
+
+// allow reports whether a dump may be taken at now.
+func (b *overheadBudget) allow(now time.Time) bool {
+	return b == nil || !now.Before(b.next)
+}
+
+// spend records a dump that started at start and took cost.
+func (b *overheadBudget) spend(start time.Time, cost time.Duration) {
+	if b == nil {
+		return
+	}
+	b.next = start.Add(time.Duration(float64(cost) * 100 / b.percent))
+}
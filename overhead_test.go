@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxOverheadPercent(t *testing.T) {
+	t.Run("budget", func(t *testing.T) {
+		budget := buildOpts(MaxOverheadPercent(10)).overheadBudget()
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.True(t, budget.allow(start))
+
+		budget.spend(start, 20*time.Millisecond)
+		assert.False(t, budget.allow(start.Add(100*time.Millisecond)))
+		assert.False(t, budget.allow(start.Add(199*time.Millisecond)))
+		assert.True(t, budget.allow(start.Add(200*time.Millisecond)))
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		budget := buildOpts().overheadBudget()
+		assert.Nil(t, budget)
+		budget.spend(time.Now(), time.Hour)
+		assert.True(t, budget.allow(time.Now()))
+	})
+
+	t.Run("Timeline", func(t *testing.T) {
+		// Any dump takes far longer than 1/10^6 of 10ms.
+		tl := StartTimeline(time.Millisecond, MaxOverheadPercent(1e-6))
+		time.Sleep(10 * time.Millisecond)
+		tl.Stop()
+		assert.Len(t, tl.Samples(), 1, "samples after the first should be skipped")
+	})
+
+	t.Run("DetectGrowth", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		reports := DetectGrowth(ctx, 20*time.Millisecond, 1, MaxOverheadPercent(1e-6))
+
+		done := make(chan struct{})
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			go waitOn(done)
+			time.Sleep(10 * time.Millisecond)
+		}
+		select {
+		case report := <-reports:
+			t.Errorf("growth must not be seen without new samples: %v", report)
+		default:
+		}
+
+		cancel()
+		for range reports {
+			// Drain until DetectGrowth stops.
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, percent := range []float64{0, -1, 101} {
+			assert.Panics(t, func() { MaxOverheadPercent(percent) }, "percent %v", percent)
+		}
+	})
+}
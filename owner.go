@@ -0,0 +1,163 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _stdOwner is the owner of goroutines created by the standard library.
+const _stdOwner = "std"
+
+var _buildInfo = sync.OnceValue(func() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	return info
+})
+
+// ownerOf returns the module that owns the code which created the
+// goroutine, e.g. "github.com/foo/bar@v1.2.3", "std" for the standard
+// library, or an empty string if it can't be told.
+//
+// The module is taken from the file path of the creation frame if the
+// module was built from the module cache. This works for dumps of
+// other binaries too. Otherwise the package of the creating function
+// is looked up in the build info of the running binary.
+func ownerOf(s stack.Stack) string {
+	creator := s.CreatedBy()
+	if creator == "" {
+		return ""
+	}
+	if owner := cachedModule(filepath.ToSlash(s.SourceEntry().File())); owner != "" {
+		return owner
+	}
+
+	pkg := packagePath(creator)
+	if pkg == "main" {
+		return mainModule(_buildInfo())
+	}
+	if first, _, _ := strings.Cut(pkg, "/"); !strings.Contains(first, ".") {
+		// Only standard library import paths have no dot
+		// in their first element.
+		return _stdOwner
+	}
+	return moduleOf(_buildInfo(), pkg)
+}
+
+// cachedModule returns the module version of a file in the module cache,
+// e.g. "github.com/Foo/bar@v1.2.3" for
+// /home/user/go/pkg/mod/github.com/!foo/bar@v1.2.3/baz/baz.go.
+func cachedModule(file string) string {
+	_, rest, ok := strings.Cut(file, "/pkg/mod/")
+	if !ok {
+		return ""
+	}
+	at := strings.IndexByte(rest, '@')
+	if at < 0 {
+		return ""
+	}
+	version, _, _ := strings.Cut(rest[at:], "/")
+	return unescapeModulePath(rest[:at]) + version
+}
+
+// unescapeModulePath reverses escapeModulePath.
+func unescapeModulePath(escaped string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range escaped {
+		switch {
+		case r == '!':
+			upper = true
+			continue
+		case upper && 'a' <= r && r <= 'z':
+			r -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// moduleOf returns the module in info that provides pkg.
+func moduleOf(info *debug.BuildInfo, pkg string) string {
+	if info == nil {
+		return ""
+	}
+	if isLocalFunction(info.Main.Path, pkg+".") {
+		return mainModule(info)
+	}
+
+	// Modules may be nested, so pick the longest match.
+	var owner *debug.Module
+	for _, dep := range info.Deps {
+		if (pkg == dep.Path || strings.HasPrefix(pkg, dep.Path+"/")) &&
+			(owner == nil || len(dep.Path) > len(owner.Path)) {
+			owner = dep
+		}
+	}
+	if owner == nil {
+		return ""
+	}
+	version := owner.Version
+	if owner.Replace != nil && owner.Replace.Version != "" {
+		version = owner.Replace.Version
+	}
+	return moduleVersion(owner.Path, version)
+}
+
+// mainModule returns the main module in info.
+func mainModule(info *debug.BuildInfo) string {
+	if info == nil {
+		return ""
+	}
+	return moduleVersion(info.Main.Path, info.Main.Version)
+}
+
+func moduleVersion(path, version string) string {
+	// Modules built from a working tree have no meaningful version.
+	if version == "" || version == "(devel)" {
+		return path
+	}
+	return path + "@" + version
+}
+
+// packagePath returns the import path of the package that
+// the fully qualified function name belongs to,
+// e.g. "github.com/foo/bar" for "github.com/foo/bar.(*Baz).Run".
+func packagePath(name string) string {
+	dir, base := "", name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		dir, base = name[:i+1], name[i+1:]
+	}
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	// The runtime escapes dots in the last element of import paths,
+	// e.g. "gopkg.in/yaml%2ev3.Unmarshal".
+	return dir + strings.ReplaceAll(base, "%2e", ".")
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerOf(t *testing.T) {
+	created := func(createdBy, location string) stack.Stack {
+		lines := []string{
+			"goroutine 7 [chan receive]:",
+			"example.com/worker.run()",
+			"	/app/worker.go:10 +0x1d",
+		}
+		if createdBy != "" {
+			lines = append(lines, "created by "+createdBy+" in goroutine 1", "	"+location+" +0x85")
+		}
+		stacks, err := stack.ParseDump([]byte(strings.Join(lines, "\n") + "\n"))
+		require.NoError(t, err)
+		require.Len(t, stacks, 1)
+		return stacks[0]
+	}
+
+	tests := []struct {
+		desc      string
+		createdBy string
+		location  string
+		want      string
+	}{
+		{
+			desc: "no creator",
+		},
+		{
+			desc:      "module cache",
+			createdBy: "github.com/Azure/go-autorest/autorest.(*Client).Send",
+			location:  "/root/go/pkg/mod/github.com/!azure/go-autorest/autorest@v0.11.29/client.go:90",
+			want:      "github.com/Azure/go-autorest/autorest@v0.11.29",
+		},
+		{
+			desc:      "standard library",
+			createdBy: "net/http.(*Server).Serve",
+			location:  "/usr/local/go/src/net/http/server.go:3285",
+			want:      "std",
+		},
+		{
+			desc:      "main module",
+			createdBy: "github.com/projectdiscovery/goleak/stack.startWorker",
+			location:  "/src/goleak/stack/worker.go:20",
+			want:      "github.com/projectdiscovery/goleak",
+		},
+		{
+			desc:      "dependency",
+			createdBy: "github.com/stretchr/testify/assert.Eventually",
+			location:  "/vendor/github.com/stretchr/testify/assert/assertions.go:1900",
+			want:      "github.com/stretchr/testify@v1.8.4",
+		},
+		{
+			desc:      "unknown",
+			createdBy: "example.com/unknown.start",
+			location:  "/src/unknown/start.go:20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, ownerOf(created(tt.createdBy, tt.location)))
+		})
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{"main.main", "main"},
+		{"net/http.(*Server).Serve", "net/http"},
+		{"github.com/foo/bar.(*Baz).Run.func1", "github.com/foo/bar"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, packagePath(tt.give), "function %q", tt.give)
+	}
+}
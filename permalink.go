@@ -0,0 +1,165 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Environment variables that CI systems set to the commit being built,
+// used when the binary has no VCS information, as is the case for
+// test binaries.
+var _commitEnvVars = []string{
+	"GITHUB_SHA",    // GitHub Actions
+	"CI_COMMIT_SHA", // GitLab CI
+	"BUILDKITE_COMMIT",
+	"CIRCLE_SHA1",
+	"GIT_COMMIT", // Jenkins
+}
+
+// Permalink is a link to the source code of a frame of a leaked
+// goroutine, e.g. on GitHub or GitLab.
+type Permalink struct {
+	Function string `json:"function"`
+	URL      string `json:"url"`
+}
+
+// WithPermalinks makes [Pretty] and JSON reports link the frames of
+// leaked goroutines that are in the main module to their source code,
+// so that reviewers can jump from a leak report of a CI run to the code.
+//
+// template is the URL of a line of a file of the module, where
+// "{commit}", "{path}" and "{line}" are replaced with the commit,
+// the slash-separated path of the file relative to the module root,
+// and the line number, e.g.:
+//
+//	https://github.com/org/repo/blob/{commit}/{path}#L{line}
+//	https://gitlab.com/org/repo/-/blob/{commit}/{path}#L{line}
+//
+// The commit is the "vcs.revision" of the build info of the binary,
+// or else the commit that the CI system is building, as set in
+// GITHUB_SHA, CI_COMMIT_SHA, BUILDKITE_COMMIT, CIRCLE_SHA1 or GIT_COMMIT.
+// If the template has "{commit}" but the commit can't be told,
+// no links are rendered.
+//
+// If the module is in a subdirectory of the repository, the template
+// should include it, e.g. ".../blob/{commit}/tools/{path}#L{line}".
+func WithPermalinks(template string) Option {
+	if !strings.Contains(template, "{path}") {
+		invalidOption("WithPermalinks", "template %q has no {path}", template)
+	}
+	return optionFunc(func(opts *opts) {
+		opts.permalinkTemplate = template
+	})
+}
+
+// vcsRevision returns the commit that the binary was built from, if known.
+func vcsRevision() string {
+	if info := _buildInfo(); info != nil {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				return setting.Value
+			}
+		}
+	}
+	for _, name := range _commitEnvVars {
+		if commit := os.Getenv(name); commit != "" {
+			return commit
+		}
+	}
+	return ""
+}
+
+// permalinks returns the links to the frames of s in the main module,
+// including the frame that created s, if WithPermalinks was used.
+func (o *opts) permalinks(s stack.Stack) []Permalink {
+	if o.permalinkTemplate == "" {
+		return nil
+	}
+	info := _buildInfo()
+	if info == nil || info.Main.Path == "" {
+		return nil
+	}
+	commit := vcsRevision()
+	if commit == "" && strings.Contains(o.permalinkTemplate, "{commit}") {
+		return nil
+	}
+
+	var links []Permalink
+	for _, entry := range s.Entries() {
+		name := entry.Function()
+		if entry.IsSource {
+			name = s.CreatedBy()
+		}
+		if url, ok := permalink(o.permalinkTemplate, commit, info.Main.Path, name, entry); ok {
+			links = append(links, Permalink{Function: name, URL: url})
+		}
+	}
+	return links
+}
+
+// permalink expands template for the location of entry, which is
+// in the function with the given name, if it's in the module at modulePath.
+//
+// The path of the file in the module is told by the package of the
+// function, e.g. "sub/foo.go" for a function in modulePath+"/sub"
+// defined in /home/user/src/module/sub/foo.go, since the file paths
+// in stacks may be absolute, or trimmed with -trimpath.
+func permalink(template, commit, modulePath, name string, entry stack.Entry) (string, bool) {
+	// External test packages are in the directory of the package.
+	// The directory of package main can't be told from its name.
+	pkg := strings.TrimSuffix(packagePath(name), "_test")
+	rest, ok := strings.CutPrefix(pkg, modulePath)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return "", false
+	}
+	line := entry.Line()
+	if line <= 0 {
+		return "", false
+	}
+	dir := strings.TrimPrefix(rest, "/")
+	file := path.Join(dir, path.Base(filepath.ToSlash(entry.File())))
+
+	return strings.NewReplacer(
+		"{commit}", commit,
+		"{path}", file,
+		"{line}", strconv.Itoa(line),
+	).Replace(template), true
+}
+
+// writePermalinks writes the links to the frames of a leaked goroutine
+// for Pretty reports.
+func writePermalinks(sb *strings.Builder, links []Permalink) {
+	if len(links) == 0 {
+		return
+	}
+	sb.WriteString(stack.Colors.BrightBlue("Permalinks").String() + ":\n")
+	for _, link := range links {
+		sb.WriteString("  " + link.Function + ": " + link.URL + "\n")
+	}
+	sb.WriteString("\n")
+}
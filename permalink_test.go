@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const _testPermalinkTemplate = "https://github.com/projectdiscovery/goleak/blob/{commit}/{path}#L{line}"
+
+func TestPermalink(t *testing.T) {
+	const mod = "github.com/foo/bar"
+
+	tests := []struct {
+		name     string
+		function string
+		location string
+		want     string
+	}{
+		{
+			name:     "module root",
+			function: "github.com/foo/bar.(*Server).run",
+			location: "\t/home/user/src/bar/server.go:42 +0x1d",
+			want:     "https://example.com/abc/server.go#L42",
+		},
+		{
+			name:     "subpackage",
+			function: "github.com/foo/bar/internal/pool.worker.func1",
+			location: "\t/home/user/src/bar/internal/pool/pool.go:7 +0x1d",
+			want:     "https://example.com/abc/internal/pool/pool.go#L7",
+		},
+		{
+			name:     "trimpath",
+			function: "github.com/foo/bar/sub.Run",
+			location: "\tgithub.com/foo/bar/sub/run.go:3 +0x1d",
+			want:     "https://example.com/abc/sub/run.go#L3",
+		},
+		{
+			name:     "external test package",
+			function: "github.com/foo/bar_test.TestRun",
+			location: "\t/home/user/src/bar/run_test.go:12 +0x1d",
+			want:     "https://example.com/abc/run_test.go#L12",
+		},
+		{
+			name:     "other module with common prefix",
+			function: "github.com/foo/barbaz.Run",
+			location: "\t/home/user/src/barbaz/run.go:3 +0x1d",
+		},
+		{
+			name:     "dependency",
+			function: "golang.org/x/sync/errgroup.(*Group).Go.func1",
+			location: "\t/home/user/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:78 +0x50",
+		},
+		{
+			name:     "package main",
+			function: "main.worker",
+			location: "\t/home/user/src/bar/cmd/bar/main.go:9 +0x19",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := stack.Entry{FunctionCall: tt.function + "()", Location: tt.location}
+			got, ok := permalink("https://example.com/{commit}/{path}#L{line}", "abc", mod, tt.function, entry)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithPermalinks(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "0123abcd")
+	commit := vcsRevision()
+	require.NotEmpty(t, commit)
+
+	dump := []byte(`goroutine 7 [chan receive]:
+github.com/projectdiscovery/goleak/stack.worker(0xc000010000)
+	/src/goleak/stack/worker.go:9 +0x19
+net/http.(*Server).Serve(0xc000010000)
+	/usr/local/go/src/net/http/server.go:3300 +0x1d
+created by github.com/projectdiscovery/goleak.startWorker in goroutine 1
+	/src/goleak/worker.go:5 +0x1d
+`)
+
+	err := FindInDump(dump, Pretty())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Permalinks")
+
+	err = FindInDump(dump, Pretty(), WithPermalinks(_testPermalinkTemplate))
+	require.Error(t, err)
+	out := err.Error()
+	assert.Contains(t, out, "Permalinks")
+	assert.Contains(t, out, fmt.Sprintf(
+		"github.com/projectdiscovery/goleak/stack.worker: https://github.com/projectdiscovery/goleak/blob/%v/stack/worker.go#L9", commit))
+	assert.Contains(t, out, fmt.Sprintf(
+		"github.com/projectdiscovery/goleak.startWorker: https://github.com/projectdiscovery/goleak/blob/%v/worker.go#L5", commit))
+	assert.NotContains(t, out, "server.go#L", "frames outside the module should not be linked")
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := CheckedOption(func() Option { return WithPermalinks("https://example.com/{commit}") })
+		assert.ErrorContains(t, err, "has no {path}")
+	})
+}
+
+func TestHandlerPermalinks(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "0123abcd")
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/goleak", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	waitForStable(t)
+	Handler(WithPermalinks(_testPermalinkTemplate)).ServeHTTP(rec, req)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Leaks, 1)
+
+	links := report.Leaks[0].Links
+	require.NotEmpty(t, links)
+	assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", links[0].Function)
+	assert.Regexp(t, `/blob/\w+/utils_test\.go#L\d+$`, links[0].URL)
+}
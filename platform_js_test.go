@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build js
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnorePlatformStacks(t *testing.T) {
+	dump := []byte(`goroutine 10 [waiting]:
+runtime.gopark(0x0, 0x0, 0x0, 0x0, 0x1)
+	/usr/local/go/src/runtime/proc.go:474 +0x24
+runtime.handleEvent()
+	/usr/local/go/src/runtime/lock_js.go:296 +0x25
+runtime.goexit({})
+	/usr/local/go/src/runtime/asm_wasm.s:413 +0x1
+`)
+	assert.NoError(t, FindInDump(dump))
+	assert.Error(t, FindInDump(dump, DisableDefaultFilters()))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build windows
+
+package goleak
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// isPlatformStack reports whether s is a goroutine that the runtime
+// runs on this platform. On Windows, name resolution calls blocking
+// functions of the system, such as GetAddrInfoW, on goroutines that
+// the net package lets finish in the background when the lookup is
+// canceled or times out; the wait may outlast the retries of a check.
+func isPlatformStack(s stack.Stack) bool {
+	if !strings.Contains(s.Full(), "net.") {
+		// Avoid parsing the frames of stacks that can't match.
+		return false
+	}
+	return s.HasFunctionFunc(func(name string) bool {
+		return strings.HasPrefix(name, "net.(*Resolver).lookupIP.func") ||
+			strings.HasPrefix(name, "net.lookupProtocol.func")
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build windows
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnorePlatformStacks(t *testing.T) {
+	dump := []byte(`goroutine 21 [syscall]:
+syscall.SyscallN(0x7ffb1c2b2f40?, {0xc00004bd48?, 0x6?, 0x0?})
+	C:/Program Files/Go/src/runtime/syscall_windows.go:519 +0x46
+syscall.GetAddrInfoW(0xc00001a0c0, 0x0, 0xc00004bec0, 0xc00004be88)
+	C:/Program Files/Go/src/syscall/zsyscall_windows.go:1153 +0xcd
+net.(*Resolver).lookupIP.func1()
+	C:/Program Files/Go/src/net/lookup_windows.go:126 +0x1c5
+net.(*Resolver).lookupIP.func2()
+	C:/Program Files/Go/src/net/lookup_windows.go:166 +0x1c
+created by net.(*Resolver).lookupIP in goroutine 20
+	C:/Program Files/Go/src/net/lookup_windows.go:165 +0x1fd
+`)
+	assert.NoError(t, FindInDump(dump))
+	assert.Error(t, FindInDump(dump, DisableDefaultFilters()))
+}
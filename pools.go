@@ -0,0 +1,209 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// poolDetector is a registered pool detector.
+type poolDetector struct {
+	name  string
+	match func(stack.Stack) bool
+}
+
+var (
+	_poolDetectorsMu sync.RWMutex
+	_poolDetectors   = map[string]func(stack.Stack) bool{
+		"ants":     isAntsWorker,
+		"conc":     isConcWorker,
+		"errgroup": isErrgroupGoWaiter,
+		"tunny":    isTunnyWorker,
+	}
+)
+
+// RegisterPoolDetector registers a detector for the workers of a
+// goroutine pool library with the given name, e.g. "workerpool", for
+// [IgnorePoolWorkers]. The matcher reports whether a goroutine is a
+// worker of the pool; only workers that are blocked, e.g. waiting for
+// tasks, are ignored, so matchers don't need to check their state.
+// Registering a detector with an existing name replaces it.
+//
+// Detectors for these pools are built in:
+//
+//   - "ants": workers of github.com/panjf2000/ants pools;
+//   - "conc": workers of github.com/sourcegraph/conc/pool pools;
+//   - "errgroup": goroutines of golang.org/x/sync/errgroup waiting in
+//     Group.Go for a slot of a group limited with SetLimit;
+//   - "tunny": workers of github.com/Jeffail/tunny pools.
+func RegisterPoolDetector(name string, matcher func(stack.Stack) bool) {
+	_poolDetectorsMu.Lock()
+	defer _poolDetectorsMu.Unlock()
+	_poolDetectors[name] = matcher
+}
+
+// IgnorePoolWorkers ignores the workers of goroutine pools that are
+// parked until they're given more work, which pools keep around to be
+// reused rather than leak. Pools are recognized by the detectors with
+// the given names, or by all registered detectors if none are given;
+// see [RegisterPoolDetector]. It panics if a name isn't registered.
+//
+// With [ReportIdlePoolWorkers], leak errors list the ignored workers.
+func IgnorePoolWorkers(names ...string) Option {
+	_poolDetectorsMu.RLock()
+	defer _poolDetectorsMu.RUnlock()
+	if len(names) == 0 {
+		for name := range _poolDetectors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	detectors := make([]poolDetector, 0, len(names))
+	for _, name := range names {
+		match, ok := _poolDetectors[name]
+		if !ok {
+			invalidOption("IgnorePoolWorkers", "unknown pool detector %q", name)
+		}
+		detectors = append(detectors, poolDetector{name: name, match: match})
+	}
+	filterName := fmt.Sprintf("IgnorePoolWorkers(%v)", strings.Join(names, ", "))
+	return optionFunc(func(opts *opts) {
+		opts.poolDetectors = append(opts.poolDetectors, detectors...)
+		opts.filters = append(opts.filters, filter{
+			name:  filterName,
+			match: func(s stack.Stack) bool { return poolOf(s, detectors) != "" },
+		})
+	})
+}
+
+// ReportIdlePoolWorkers makes leak errors list the pool workers that
+// [IgnorePoolWorkers] ignores, under "idle pool workers", so that pools
+// that are never closed can still be told apart.
+func ReportIdlePoolWorkers() Option {
+	return optionFunc(func(opts *opts) {
+		opts.reportIdlePoolWorkers = true
+	})
+}
+
+// poolOf returns the name of the first detector that recognizes s as
+// a parked pool worker, or an empty string if none does.
+func poolOf(s stack.Stack, detectors []poolDetector) string {
+	if !isBlocked(s) {
+		return ""
+	}
+	for _, d := range detectors {
+		if d.match(s) {
+			return d.name
+		}
+	}
+	return ""
+}
+
+// poolNotes lists the idle pool workers that are running, if
+// ReportIdlePoolWorkers was given.
+func (o *opts) poolNotes() string {
+	if !o.reportIdlePoolWorkers || len(o.poolDetectors) == 0 {
+		return ""
+	}
+	return idlePoolWorkers(o.stacks(), o.poolDetectors)
+}
+
+// idlePoolWorkers describes the goroutines of stacks that are parked
+// pool workers, by pool.
+func idlePoolWorkers(stacks []stack.Stack, detectors []poolDetector) string {
+	workers := make(map[string][]int)
+	var pools []string
+	for _, s := range stacks {
+		pool := poolOf(s, detectors)
+		if pool == "" {
+			continue
+		}
+		if _, ok := workers[pool]; !ok {
+			pools = append(pools, pool)
+		}
+		workers[pool] = append(workers[pool], s.ID())
+	}
+	if len(pools) == 0 {
+		return ""
+	}
+
+	sort.Strings(pools)
+	var sb strings.Builder
+	sb.WriteString("\nidle pool workers, not reported as leaks:\n")
+	for _, pool := range pools {
+		ids := workers[pool]
+		sort.Ints(ids)
+		fmt.Fprintf(&sb, "%v: %v\n", pool, goroutineList(ids))
+	}
+	return sb.String()
+}
+
+// hasFunctionIn returns a function that reports whether a stack has
+// a function of the package with the given import path prefix, with
+// a name that contains fn. Prefixes match all major versions of
+// a module, e.g. "github.com/panjf2000/ants" matches ants/v2.
+func hasFunctionIn(pkgPrefix, fn string) func(stack.Stack) bool {
+	match := func(name string) bool {
+		rest, ok := strings.CutPrefix(name, pkgPrefix)
+		return ok && strings.Contains(rest, fn)
+	}
+	return func(s stack.Stack) bool {
+		// Check the raw trace first so that other stacks aren't parsed in full.
+		return strings.Contains(s.Full(), pkgPrefix) && s.HasFunctionFunc(match)
+	}
+}
+
+// Workers of ants wait for tasks in the run method of goWorker, or of
+// goWorkerWithFunc for pools of a single function.
+var isAntsWorker = hasFunctionIn("github.com/panjf2000/ants", ".(*goWorker")
+
+// Workers of conc pools range over the tasks of the pool.
+var isConcWorker = hasFunctionIn("github.com/sourcegraph/conc/pool.", "(*Pool).worker")
+
+// Workers of tunny wait for requests in the run method of workerWrapper.
+var isTunnyWorker = hasFunctionIn("github.com/Jeffail/tunny.", "(*workerWrapper).run")
+
+// Goroutines wait in errgroup.(*Group).Go when the group is at its
+// limit. Those waiting in errgroup.(*Group).Wait aren't workers: they
+// wait for members that may have leaked, see DiagnoseGroups.
+func isErrgroupGoWaiter(s stack.Stack) bool {
+	const pkg = "golang.org/x/sync/errgroup."
+	if !strings.Contains(s.Full(), pkg) {
+		return false
+	}
+	// Only the top frame of the package: goroutines started by Go have
+	// (*Group).Go.func1 further down, and are running tasks instead.
+	for _, entry := range s.Entries() {
+		if entry.IsSource {
+			break
+		}
+		if name, ok := strings.CutPrefix(entry.Function(), pkg); ok {
+			return name == "(*Group).Go"
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// _poolsDump holds goroutines of the pools with built-in detectors.
+var _poolsDump = strings.Join([]string{
+	"goroutine 10 [chan receive]:",
+	"github.com/panjf2000/ants/v2.(*goWorker).run.func1()",
+	"	/go/pkg/mod/github.com/panjf2000/ants/v2@v2.10.0/worker.go:72 +0x9c",
+	"created by github.com/panjf2000/ants/v2.(*goWorker).run in goroutine 1",
+	"	/go/pkg/mod/github.com/panjf2000/ants/v2@v2.10.0/worker.go:48 +0x6a",
+	"",
+	"goroutine 11 [running]:",
+	"github.com/panjf2000/ants/v2.(*goWorkerWithFunc).run.func1()",
+	"	/go/pkg/mod/github.com/panjf2000/ants/v2@v2.10.0/worker_func.go:72 +0x9c",
+	"created by github.com/panjf2000/ants/v2.(*goWorkerWithFunc).run in goroutine 1",
+	"	/go/pkg/mod/github.com/panjf2000/ants/v2@v2.10.0/worker_func.go:48 +0x6a",
+	"",
+	"goroutine 12 [chan receive]:",
+	"github.com/sourcegraph/conc/pool.(*Pool).worker(0xc000120000)",
+	"	/go/pkg/mod/github.com/sourcegraph/conc@v0.3.0/pool/pool.go:154 +0x8b",
+	"github.com/sourcegraph/conc/panics.(*Catcher).Try(0xc000012345, 0xc000067f90)",
+	"	/go/pkg/mod/github.com/sourcegraph/conc@v0.3.0/panics/panics.go:23 +0x48",
+	"created by github.com/sourcegraph/conc.(*WaitGroup).Go in goroutine 1",
+	"	/go/pkg/mod/github.com/sourcegraph/conc@v0.3.0/waitgroup.go:30 +0x73",
+	"",
+	"goroutine 13 [select]:",
+	"github.com/Jeffail/tunny.(*workerWrapper).run(0xc000180000)",
+	"	/go/pkg/mod/github.com/!jeffail/tunny@v0.1.4/worker.go:94 +0x1b6",
+	"created by github.com/Jeffail/tunny.newWorkerWrapper in goroutine 1",
+	"	/go/pkg/mod/github.com/!jeffail/tunny@v0.1.4/worker.go:70 +0x1a5",
+	"",
+	"goroutine 14 [chan send]:",
+	"golang.org/x/sync/errgroup.(*Group).Go(0xc0001a0000, 0xc0001b0000)",
+	"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:71 +0x45",
+	"main.fetchAll()",
+	"	/app/main.go:30 +0x85",
+	"created by main.main in goroutine 1",
+	"	/app/main.go:20 +0x85",
+	"",
+	"goroutine 15 [chan receive]:",
+	"main.fetch()",
+	"	/app/main.go:50 +0x25",
+	"golang.org/x/sync/errgroup.(*Group).Go.func1()",
+	"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:78 +0x56",
+	"created by golang.org/x/sync/errgroup.(*Group).Go in goroutine 14",
+	"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:75 +0x96",
+	"",
+	"goroutine 16 [semacquire]:",
+	"sync.runtime_Semacquire(0xc0001a0008?)",
+	"	/usr/local/go/src/runtime/sema.go:62 +0x25",
+	"sync.(*WaitGroup).Wait(0xc0001a0000)",
+	"	/usr/local/go/src/sync/waitgroup.go:116 +0x48",
+	"golang.org/x/sync/errgroup.(*Group).Wait(0xc0001a0000)",
+	"	/go/pkg/mod/golang.org/x/sync@v0.7.0/errgroup/errgroup.go:56 +0x25",
+	"main.fetchAll()",
+	"	/app/main.go:31 +0x85",
+	"created by main.main in goroutine 1",
+	"	/app/main.go:20 +0x85",
+	"",
+}, "\n")
+
+func TestIgnorePoolWorkers(t *testing.T) {
+	stacks, err := stack.ParseDump([]byte(_poolsDump))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opt  Option
+		want []int
+	}{
+		// Goroutines waiting for groups aren't workers, so 16 is never ignored.
+		{"all", IgnorePoolWorkers(), []int{11, 15, 16}},
+		{"ants", IgnorePoolWorkers("ants"), []int{11, 12, 13, 14, 15, 16}},
+		{"conc", IgnorePoolWorkers("conc"), []int{10, 11, 13, 14, 15, 16}},
+		{"errgroup", IgnorePoolWorkers("errgroup"), []int{10, 11, 12, 13, 15, 16}},
+		{"tunny", IgnorePoolWorkers("tunny"), []int{10, 11, 12, 14, 15, 16}},
+		{"several", IgnorePoolWorkers("tunny", "conc"), []int{10, 11, 14, 15, 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stackIDs(FilterStacks(stacks, tt.opt)))
+		})
+	}
+
+	assert.Equal(t, "\nidle pool workers, not reported as leaks:\n"+
+		"ants: goroutine 10\n"+
+		"conc: goroutine 12\n"+
+		"errgroup: goroutine 14\n"+
+		"tunny: goroutine 13\n",
+		idlePoolWorkers(stacks, buildOpts(IgnorePoolWorkers()).poolDetectors))
+}
+
+// parkedPoolWorker is the worker of the pool of TestRegisterPoolDetector.
+func parkedPoolWorker(tasks chan struct{}) {
+	for range tasks {
+	}
+}
+
+func TestRegisterPoolDetector(t *testing.T) {
+	RegisterPoolDetector("test", func(s stack.Stack) bool {
+		return s.HasFunction("github.com/projectdiscovery/goleak.parkedPoolWorker")
+	})
+	defer func() {
+		_poolDetectorsMu.Lock()
+		delete(_poolDetectors, "test")
+		_poolDetectorsMu.Unlock()
+	}()
+
+	tasks := make(chan struct{})
+	defer close(tasks)
+	for i := 0; i < 2; i++ {
+		go parkedPoolWorker(tasks)
+	}
+	workers := func() []stack.Stack {
+		return FilterStacks(stack.All(), IncludeAnyFunction("github.com/projectdiscovery/goleak.parkedPoolWorker"))
+	}
+	require.Eventually(t, func() bool {
+		ws := workers()
+		return len(ws) == 2 && isBlocked(ws[0]) && isBlocked(ws[1])
+	}, 5*time.Second, time.Millisecond)
+	assert.Empty(t, FilterStacks(workers(), IgnorePoolWorkers("test")))
+
+	bg := startBlockedG()
+	defer bg.unblock()
+	err := Find(testOptions(), IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"),
+		IgnorePoolWorkers("test"))
+	assert.NoError(t, err, "pool workers should be ignored")
+
+	err = Find(testOptions(), IgnorePoolWorkers("test"), ReportIdlePoolWorkers())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "goleak.(*blockedG).block")
+	assert.NotContains(t, err.Error(), "goleak.parkedPoolWorker on top")
+	ids := stackIDs(workers())
+	sort.Ints(ids)
+	assert.Contains(t, err.Error(), "\nidle pool workers, not reported as leaks:\ntest: "+goroutineList(ids)+"\n")
+}
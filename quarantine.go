@@ -0,0 +1,185 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Quarantine reports leaked goroutines with the given fingerprints as
+// warnings rather than failures, for known-flaky leaks that shouldn't
+// block CI while they're being fixed. See [stack.Stack.Fingerprint];
+// fingerprints are included in the [Report] of every leak.
+//
+// Quarantined leaks are logged as a warning through the logger given
+// with [WithLogger], or written to stderr without one. Every occurrence
+// is counted; see [QuarantineStats].
+func Quarantine(fingerprints ...string) Option {
+	for _, fp := range fingerprints {
+		checkName("Quarantine", "fingerprint", fp)
+	}
+	return optionFunc(func(opts *opts) {
+		quarantine := make(map[string]struct{}, len(opts.quarantine)+len(fingerprints))
+		for fp := range opts.quarantine {
+			quarantine[fp] = struct{}{}
+		}
+		for _, fp := range fingerprints {
+			quarantine[fp] = struct{}{}
+		}
+		opts.quarantine = quarantine
+	})
+}
+
+// QuarantineFile reads fingerprints to [Quarantine] from the file at
+// path, one per line. Anything following a '#' is a comment,
+// e.g. to say why a leak is quarantined:
+//
+//	# Flaky since the connection pool was rewritten.
+//	9c1bb0e2a5d41fd3  # example.com/db.(*Pool).reap
+func QuarantineFile(path string) (Option, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fingerprints []string
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			fingerprints = append(fingerprints, fields[0])
+		default:
+			return nil, fmt.Errorf("%v:%d: expected a single fingerprint, got %q", path, lineNum, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %v: %w", path, err)
+	}
+	return Quarantine(fingerprints...), nil
+}
+
+// QuarantinedLeak counts the occurrences of a quarantined leak.
+type QuarantinedLeak struct {
+	Fingerprint   string `json:"fingerprint"`
+	FirstFunction string `json:"first_function"`
+	// Occurrences is the number of leaked goroutines with the
+	// fingerprint found by leak checks of this process.
+	Occurrences int `json:"occurrences"`
+}
+
+// Occurrences of quarantined leaks by fingerprint.
+var (
+	_quarantinedMu sync.Mutex
+	_quarantined   = make(map[string]*QuarantinedLeak)
+)
+
+// QuarantineStats returns the quarantined leaks that leak checks of
+// this process found so far, sorted by fingerprint.
+func QuarantineStats() []QuarantinedLeak {
+	_quarantinedMu.Lock()
+	defer _quarantinedMu.Unlock()
+	stats := make([]QuarantinedLeak, 0, len(_quarantined))
+	for _, q := range _quarantined {
+		stats = append(stats, *q)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Fingerprint < stats[j].Fingerprint })
+	return stats
+}
+
+// quarantines reports whether the options may quarantine goroutines,
+// by fingerprint, by quarantine rules or with watched rules files.
+func (o *opts) quarantines() bool {
+	return len(o.quarantine) > 0 || len(o.quarantineMatch) > 0 || len(o.rulesWatchers) > 0
+}
+
+// splitQuarantined separates the quarantined stacks from the others.
+func (o *opts) splitQuarantined(stacks []stack.Stack) (leaks, quarantined []stack.Stack) {
+	if !o.quarantines() {
+		return stacks, nil
+	}
+	for _, s := range stacks {
+		if o.quarantined(s) {
+			quarantined = append(quarantined, s)
+		} else {
+			leaks = append(leaks, s)
+		}
+	}
+	return leaks, quarantined
+}
+
+// quarantined reports whether s is quarantined by fingerprint
+// or by a quarantine rule, including those of watched rules files.
+func (o *opts) quarantined(s stack.Stack) bool {
+	if _, ok := o.quarantine[s.Fingerprint()]; ok {
+		return true
+	}
+	for _, match := range o.quarantineMatch {
+		if match(s) {
+			return true
+		}
+	}
+	for _, w := range o.rulesWatchers {
+		if w.rules().quarantined(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// quarantineLeaks counts and warns about leaks that are quarantined,
+// and returns the others.
+func (o *opts) quarantineLeaks(stacks []stack.Stack) []stack.Stack {
+	leaks, quarantined := o.splitQuarantined(stacks)
+	if len(quarantined) == 0 {
+		return leaks
+	}
+
+	_quarantinedMu.Lock()
+	for _, s := range quarantined {
+		fp := s.Fingerprint()
+		q, ok := _quarantined[fp]
+		if !ok {
+			q = &QuarantinedLeak{Fingerprint: fp, FirstFunction: s.FirstFunction()}
+			_quarantined[fp] = q
+		}
+		q.Occurrences++
+	}
+	_quarantinedMu.Unlock()
+
+	if o.logger != nil {
+		o.logger.Warn("goleak: found quarantined leaks", "count", len(quarantined), "stacks", fmt.Sprint(quarantined))
+	} else {
+		fmt.Fprintf(_osStderr, "goleak: warning: found %d quarantined leaked goroutines:\n%v\n", len(quarantined), quarantined)
+	}
+	return leaks
+}
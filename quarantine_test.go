@@ -0,0 +1,162 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quarantineOccurrences returns the number of occurrences
+// of the quarantined leak with the given fingerprint.
+func quarantineOccurrences(fp string) int {
+	for _, q := range QuarantineStats() {
+		if q.Fingerprint == fp {
+			return q.Occurrences
+		}
+	}
+	return 0
+}
+
+func TestQuarantine(t *testing.T) {
+	defer clearOSStubs()
+	var stderr bytes.Buffer
+	_osStderr = &stderr
+
+	bg := startBlockedG()
+	defer bg.unblock()
+	fp := blockedStack(t).Fingerprint()
+	before := quarantineOccurrences(fp)
+
+	t.Run("warns without failing", func(t *testing.T) {
+		stderr.Reset()
+		require.NoError(t, Find(testOptions(), Quarantine(fp)))
+		assert.Contains(t, stderr.String(), "goleak: warning: found 1 quarantined leaked goroutines:\n")
+		assert.Contains(t, stderr.String(), "goleak.(*blockedG).block")
+		assert.Equal(t, before+1, quarantineOccurrences(fp))
+
+		stats := QuarantineStats()
+		require.NotEmpty(t, stats)
+		for _, q := range stats {
+			if q.Fingerprint == fp {
+				assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", q.FirstFunction)
+			}
+		}
+	})
+
+	t.Run("other leaks fail", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() { <-done }()
+		defer close(done)
+
+		err := Find(testOptions(), Quarantine(fp))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TestQuarantine.func2.1")
+		assert.NotContains(t, err.Error(), "(*blockedG).block")
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		stderr.Reset()
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		require.NoError(t, Find(testOptions(), Quarantine(fp), WithLogger(logger)))
+		assert.Contains(t, buf.String(), `level=WARN msg="goleak: found quarantined leaks" count=1`)
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		waitForStable(t)
+		req := httptest.NewRequest(http.MethodGet, "/debug/goleak", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		Handler(Quarantine(fp)).ServeHTTP(rec, req)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Empty(t, report.Leaks)
+		assert.Contains(t, report.Quarantined, QuarantinedLeak{
+			Fingerprint:   fp,
+			FirstFunction: "github.com/projectdiscovery/goleak.(*blockedG).block",
+			Occurrences:   quarantineOccurrences(fp),
+		})
+
+		rec = httptest.NewRecorder()
+		Handler(Quarantine(fp)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/goleak", nil))
+		assert.Equal(t, "1 quarantined goroutines are not shown\nno unexpected goroutines\n", rec.Body.String())
+	})
+
+	t.Run("handler with rules", func(t *testing.T) {
+		opt, err := WithRules(Rule{Action: RuleQuarantine, Fingerprint: fp})
+		require.NoError(t, err)
+		waitForStable(t)
+		req := httptest.NewRequest(http.MethodGet, "/debug/goleak", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		Handler(opt).ServeHTTP(rec, req)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Empty(t, report.Leaks)
+		assert.Contains(t, report.Quarantined, QuarantinedLeak{
+			Fingerprint:   fp,
+			FirstFunction: "github.com/projectdiscovery/goleak.(*blockedG).block",
+			Occurrences:   quarantineOccurrences(fp),
+		})
+	})
+
+	assert.Panics(t, func() { Quarantine("") })
+}
+
+func TestQuarantineFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(dir, t.Name()[len("TestQuarantineFile/"):])
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		opt, err := QuarantineFile(write(t, "# flaky leaks\n\naaaa\nbbbb  # example.com/db.(*Pool).reap\n"))
+		require.NoError(t, err)
+		opts := buildOpts(opt, Quarantine("cccc"))
+		assert.Equal(t, map[string]struct{}{"aaaa": {}, "bbbb": {}, "cccc": {}}, opts.quarantine)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := write(t, "aaaa\nbbbb cccc\n")
+		_, err := QuarantineFile(path)
+		assert.EqualError(t, err, path+`:2: expected a single fingerprint, got "bbbb cccc"`)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := QuarantineFile(filepath.Join(dir, "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+const _pprofGoroutinePath = "/debug/pprof/goroutine"
+
+// remoteOpts configures how FindAtURL talks to remote services.
+type remoteOpts struct {
+	header    http.Header
+	tlsConfig *tls.Config
+}
+
+// WithHeader adds a header to the requests made by [FindAtURL],
+// e.g. for authentication. It may be given multiple times.
+func WithHeader(key, value string) Option {
+	return optionFunc(func(opts *opts) {
+		if opts.remote.header == nil {
+			opts.remote.header = make(http.Header)
+		}
+		opts.remote.header.Add(key, value)
+	})
+}
+
+// WithTLSConfig sets the TLS configuration used by [FindAtURL]
+// for https URLs, e.g. to present client certificates
+// or trust a private certificate authority.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return optionFunc(func(opts *opts) {
+		opts.remote.tlsConfig = cfg
+	})
+}
+
+// FindAtURL fetches the goroutines of a remote service from its
+// net/http/pprof endpoint and looks for unexpected goroutines among them,
+// like [FindInDump].
+//
+// rawURL is either the base URL of the service, e.g. http://host:6060,
+// or the full URL of its goroutine profile if it is not mounted
+// at the default /debug/pprof/goroutine path.
+// The goroutine serving the profile is ignored.
+func FindAtURL(ctx context.Context, rawURL string, options ...Option) error {
+	opts := buildOpts(options...)
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parse URL: %w", err)
+	}
+	if !strings.HasSuffix(u.Path, "/goroutine") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + _pprofGoroutinePath
+	}
+	q := u.Query()
+	q.Set("debug", "2")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range opts.remote.header {
+		req.Header[key] = values
+	}
+
+	client := http.DefaultClient
+	if opts.remote.tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = opts.remote.tlsConfig
+		// Don't leave the connections of this one-off transport behind.
+		defer transport.CloseIdleConnections()
+		client = &http.Client{Transport: transport}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch goroutines: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch goroutines: unexpected status %v from %v", res.Status, u.Redacted())
+	}
+
+	// Parse the profile as it arrives
+	// rather than holding all of it in memory.
+	stacks, err := stack.ParseReader(res.Body)
+	if err != nil {
+		return fmt.Errorf("parse goroutine dump: %w", err)
+	}
+	addFilter("goroutine profile writer", isProfileWriterStack).apply(opts)
+	return findInStacks(stacks, opts)
+}
+
+// isProfileWriterStack reports whether s is the goroutine that
+// wrote a goroutine profile.
+func isProfileWriterStack(s stack.Stack) bool {
+	return s.HasFunction("runtime/pprof.writeGoroutineStacks")
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAtURL(t *testing.T) {
+	dump := strings.Join([]string{
+		"goroutine 9 [running]:",
+		"runtime/pprof.writeGoroutineStacks({0x7b0f60, 0xc000012345})",
+		"	/usr/lib/go/src/runtime/pprof/pprof.go:703 +0x6a",
+		"net/http/pprof.handler.ServeHTTP({0xc000014000, 0x9}, {0x7b3a38, 0xc000130000}, 0x0?)",
+		"	/usr/lib/go/src/net/http/pprof/pprof.go:259 +0x2d4",
+		"",
+		"goroutine 7 [select]:",
+		"main.worker()",
+		"	/app/main.go:42 +0x25",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:20 +0x85",
+		"",
+	}, "\n")
+
+	var gotPath, gotQuery, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotAuth = r.URL.Path, r.URL.RawQuery, r.Header.Get("Authorization")
+		if gotAuth != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(dump))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	t.Run("base URL", func(t *testing.T) {
+		err := FindAtURL(ctx, srv.URL, WithHeader("Authorization", "Bearer token"))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "main.worker")
+		assert.NotContains(t, err.Error(), "writeGoroutineStacks", "profile writer should be ignored")
+		assert.Equal(t, "/debug/pprof/goroutine", gotPath)
+		assert.Equal(t, "debug=2", gotQuery)
+	})
+
+	t.Run("profile URL", func(t *testing.T) {
+		err := FindAtURL(ctx, srv.URL+"/internal/goroutine",
+			WithHeader("Authorization", "Bearer token"),
+			IgnoreTopFunction("main.worker"))
+		require.NoError(t, err)
+		assert.Equal(t, "/internal/goroutine", gotPath)
+	})
+
+	t.Run("bad status", func(t *testing.T) {
+		err := FindAtURL(ctx, srv.URL)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "unexpected status 401 Unauthorized")
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(dump))
+		}))
+		defer tlsSrv.Close()
+
+		err := FindAtURL(ctx, tlsSrv.URL, IgnoreTopFunction("main.worker"))
+		require.Error(t, err, "untrusted certificate should be rejected")
+
+		cfg := tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig
+		err = FindAtURL(ctx, tlsSrv.URL, WithTLSConfig(cfg), IgnoreTopFunction("main.worker"))
+		require.NoError(t, err)
+	})
+}
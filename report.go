@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Report is a machine-readable description of leaked goroutines,
+// suitable for encoding as JSON.
+type Report struct {
+	Leaks []LeakedGoroutine `json:"leaks"`
+	// Quarantined counts the leaks quarantined with [Quarantine]
+	// that were found by this process. Quarantined goroutines
+	// are not listed in Leaks.
+	Quarantined []QuarantinedLeak `json:"quarantined,omitempty"`
+}
+
+// LeakedGoroutine describes a single leaked goroutine in a Report.
+type LeakedGoroutine struct {
+	ID            int    `json:"id"`
+	State         string `json:"state"`
+	FirstFunction string `json:"first_function"`
+	Fingerprint   string `json:"fingerprint"`
+	// CreatedBy is the "created by" line of the stack, if any.
+	CreatedBy string `json:"created_by,omitempty"`
+	// CreatorID is the ID of the goroutine that created this one.
+	// Only available from Go 1.21.
+	CreatorID int `json:"creator_id,omitempty"`
+	// Owner is the module that owns the code that created the goroutine,
+	// e.g. "github.com/foo/bar@v1.2.3", or "std" for the standard library.
+	// It is empty if the owner can't be told.
+	// Modules are recognized by module cache paths in the stack,
+	// or else by the build info of the running binary,
+	// which only applies to stacks of the same binary.
+	Owner string `json:"owner,omitempty"`
+	// Ancestry holds the IDs of the goroutines that led to this one,
+	// closest first. Only available with GODEBUG=tracebackancestors=N.
+	Ancestry []int `json:"ancestry,omitempty"`
+	// LockedToThread reports whether the goroutine called
+	// runtime.LockOSThread without unlocking.
+	LockedToThread bool   `json:"locked_to_thread,omitempty"`
+	Stack          string `json:"stack"`
+	// Trend is how the number of leaked goroutines with the same
+	// fingerprint changed while the leak check retried: TrendStable,
+	// TrendGrowing, TrendShrinking or TrendChanging. It's empty if the
+	// check didn't retry.
+	Trend string `json:"trend,omitempty"`
+	// Links link the frames of the goroutine to their source code.
+	// Only set with [WithPermalinks].
+	Links []Permalink `json:"links,omitempty"`
+}
+
+// NewReport builds a Report from the given leaked stacks.
+func NewReport(stacks []stack.Stack) Report {
+	leaks := make([]LeakedGoroutine, 0, len(stacks))
+	for _, s := range stacks {
+		leak := LeakedGoroutine{
+			ID:             s.ID(),
+			State:          s.State(),
+			FirstFunction:  s.FirstFunction(),
+			Fingerprint:    s.Fingerprint(),
+			CreatedBy:      s.SourceEntry().FunctionCall,
+			CreatorID:      s.CreatorID(),
+			Owner:          ownerOf(s),
+			LockedToThread: s.LockedToThread(),
+			Stack:          s.Full(),
+		}
+		for _, ancestor := range s.Ancestors() {
+			leak.Ancestry = append(leak.Ancestry, ancestor.ID)
+		}
+		leaks = append(leaks, leak)
+	}
+	return Report{Leaks: leaks}
+}
+
+// report is like NewReport, and adds what the options ask for
+// and the trends of the check that found the stacks.
+func (o *opts) report(stacks []stack.Stack, check leakCheck) Report {
+	report := NewReport(stacks)
+	for i, s := range stacks {
+		report.Leaks[i].Links = o.permalinks(s)
+		report.Leaks[i].Trend, _ = check.trends.trend(s.Fingerprint())
+	}
+	return report
+}
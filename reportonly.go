@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import "fmt"
+
+// ReportOnly makes [VerifyNone], [VerifyTestMain] and the other Verify
+// functions report leaks without failing: tests pass, VerifyTestMain
+// keeps the exit code of the tests, and [Verify] doesn't panic.
+// This allows a burn-in period that collects data on existing leaks
+// before enforcing them:
+//
+//	goleak.SetDefaults(goleak.ReportOnly())
+//
+// Leaks are logged with t.Log if the TestingT supports it, and as a
+// warning through the logger given with [WithLogger], if any.
+// Otherwise, they're written to stderr. ReportOnly takes precedence
+// over [FailWith].
+func ReportOnly() Option {
+	return optionFunc(func(opts *opts) {
+		opts.reportOnly = true
+	})
+}
+
+type testLogger interface {
+	Log(args ...interface{})
+}
+
+// fail handles leaks found by a Verify function: by default it marks t
+// as failed, unless the FailWith or ReportOnly options say otherwise.
+func (o *opts) fail(t TestingT, err error) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	switch {
+	case o.reportOnly:
+		o.reportLeaks(t, err)
+	case o.failWith != nil:
+		o.failWith(err)
+	default:
+		t.Error(err)
+	}
+}
+
+// reportLeaks reports leaks without failing for ReportOnly.
+// t may be nil if there is no test to log them to.
+func (o *opts) reportLeaks(t TestingT, err error) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	if o.logger != nil {
+		o.logger.Warn("goleak: found leaks in report-only mode", "error", err)
+	}
+	if l, ok := t.(testLogger); ok {
+		l.Log(fmt.Sprintf("goleak: report only: %v", err))
+	} else if o.logger == nil {
+		fmt.Fprintf(_osStderr, "goleak: report only: %v\n", err)
+	}
+}
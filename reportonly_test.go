@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loggingT is a TestingT that supports t.Log.
+type loggingT struct {
+	fakeT
+
+	logs []string
+}
+
+func (lt *loggingT) Log(args ...interface{}) {
+	lt.logs = append(lt.logs, fmt.Sprint(args...))
+}
+
+func TestReportOnly(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	t.Run("VerifyNone logs to t", func(t *testing.T) {
+		lt := &loggingT{}
+		VerifyNone(lt, testOptions(), ReportOnly())
+		assert.Empty(t, lt.errors, "leaks should not fail the test")
+		require.Len(t, lt.logs, 1)
+		assert.Contains(t, lt.logs[0], "goleak: report only: found unexpected goroutines")
+	})
+
+	t.Run("overrides FailWith", func(t *testing.T) {
+		lt := &loggingT{}
+		VerifyNone(lt, testOptions(), ReportOnly(), FailWith(func(err error) {
+			t.Errorf("FailWith should not be called: %v", err)
+		}))
+		assert.Len(t, lt.logs, 1)
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		ft := &fakeT{}
+		VerifyNone(ft, testOptions(), ReportOnly(), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+		assert.Empty(t, ft.errors)
+		assert.Contains(t, buf.String(), `level=WARN msg="goleak: found leaks in report-only mode"`)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		defer clearOSStubs()
+		var buf bytes.Buffer
+		_osStderr = &buf
+		assert.NotPanics(t, func() { Verify(testOptions(), ReportOnly()) })
+		assert.Contains(t, buf.String(), "goleak: report only: found unexpected goroutines")
+	})
+
+	t.Run("VerifyTestMain", func(t *testing.T) {
+		defer clearOSStubs()
+		exitCode, stderr := osStubs()
+		VerifyTestMain(dummyTestMain(0), testOptions(), ReportOnly())
+		assert.Equal(t, 0, <-exitCode, "exit code should not be changed")
+		assert.Contains(t, <-stderr, "goleak: report only: found unexpected goroutines")
+	})
+}
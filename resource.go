@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ResourceTracker detects leaks of a kind of resource other than
+// goroutines, such as database connections, GPU handles or temporary
+// files. Register trackers with [RegisterResourceTracker] to have them
+// checked by [VerifyNoneAll].
+type ResourceTracker interface {
+	// Snapshot records the resources that are in use.
+	Snapshot() any
+
+	// Diff compares a snapshot taken when a check started
+	// to one taken when it ended, and returns the resources
+	// that were leaked in between, if any.
+	Diff(before, after any) []any
+
+	// Describe describes a leaked resource returned by Diff
+	// on a single line, e.g. with where it was acquired.
+	Describe(leaked any) string
+}
+
+var (
+	_trackersMu sync.RWMutex
+	_trackers   = make(map[string]ResourceTracker)
+)
+
+// RegisterResourceTracker registers a tracker for the kind of resource
+// with the given name, e.g. "temporary files". The name is used in
+// reports. Registering a tracker with an existing name replaces it.
+func RegisterResourceTracker(name string, rt ResourceTracker) {
+	_trackersMu.Lock()
+	defer _trackersMu.Unlock()
+	_trackers[name] = rt
+}
+
+// namedTracker is a registered ResourceTracker.
+type namedTracker struct {
+	name string
+	ResourceTracker
+}
+
+// registeredTrackers returns the registered trackers, sorted by name.
+func registeredTrackers() []namedTracker {
+	_trackersMu.RLock()
+	defer _trackersMu.RUnlock()
+	trackers := make([]namedTracker, 0, len(_trackers))
+	for name, rt := range _trackers {
+		trackers = append(trackers, namedTracker{name: name, ResourceTracker: rt})
+	}
+	sort.Slice(trackers, func(i, j int) bool { return trackers[i].name < trackers[j].name })
+	return trackers
+}
+
+// VerifyNoneAll marks the given TestingF as failed if the test leaks
+// goroutines or any resource of a tracker registered with
+// [RegisterResourceTracker]. Call it at the start of the test:
+//
+//	func TestFoo(t *testing.T) {
+//		goleak.VerifyNoneAll(t)
+//		// ...
+//	}
+//
+// Goroutines and resources in use when VerifyNoneAll is called are
+// ignored. All leaks are reported together once the test completes,
+// retrying like [VerifyNone] to let resources be released.
+// Options are interpreted as for VerifyNone.
+func VerifyNoneAll(t TestingF, options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	trackers := registeredTrackers()
+	before := make([]any, len(trackers))
+	for i, rt := range trackers {
+		before[i] = rt.Snapshot()
+	}
+
+	options = append(options, ignoreExisting())
+	t.Cleanup(func() {
+		opts := buildOpts(options...)
+		opts.capRetries(t)
+		var cleanup func(int)
+		cleanup, opts.cleanup = opts.cleanup, nil
+
+		var reports []string
+		if err := find(opts); err != nil {
+			reports = append(reports, err.Error())
+		}
+		for i, rt := range trackers {
+			var leaked []any
+			for j := 0; ; j++ {
+				leaked = rt.Diff(before[i], rt.Snapshot())
+				if len(leaked) == 0 || !opts.retry(j) {
+					break
+				}
+			}
+			if len(leaked) > 0 {
+				var sb strings.Builder
+				fmt.Fprintf(&sb, "found leaked %v:\n", rt.name)
+				for _, r := range leaked {
+					fmt.Fprintf(&sb, "  %v\n", rt.Describe(r))
+				}
+				reports = append(reports, sb.String())
+			}
+		}
+
+		if len(reports) > 0 {
+			opts.fail(t, errors.New(strings.Join(reports, "\n")))
+		}
+		if cleanup != nil {
+			cleanup(0)
+		}
+	})
+}
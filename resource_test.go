@@ -0,0 +1,147 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFiles tracks made up temporary files.
+type fakeFiles struct {
+	mu   sync.Mutex
+	open map[string]bool
+}
+
+func (f *fakeFiles) set(name string, open bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.open[name] = open
+}
+
+func (f *fakeFiles) Snapshot() any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name, open := range f.open {
+		if open {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeFiles) Diff(before, after any) []any {
+	existing := make(map[string]bool)
+	for _, name := range before.([]string) {
+		existing[name] = true
+	}
+	var leaked []any
+	for _, name := range after.([]string) {
+		if !existing[name] {
+			leaked = append(leaked, name)
+		}
+	}
+	return leaked
+}
+
+func (f *fakeFiles) Describe(leaked any) string {
+	return fmt.Sprintf("file %v", leaked)
+}
+
+func withTracker(t *testing.T, name string, rt ResourceTracker) {
+	_trackersMu.Lock()
+	orig := _trackers
+	_trackers = make(map[string]ResourceTracker)
+	_trackersMu.Unlock()
+	t.Cleanup(func() {
+		_trackersMu.Lock()
+		_trackers = orig
+		_trackersMu.Unlock()
+	})
+	RegisterResourceTracker(name, rt)
+}
+
+func TestVerifyNoneAll(t *testing.T) {
+	files := &fakeFiles{open: map[string]bool{"existing": true}}
+	withTracker(t, "temporary files", files)
+
+	run := func(during func()) *fakeF {
+		ff := &fakeF{}
+		VerifyNoneAll(ff, testOptions())
+		during()
+		for _, f := range ff.cleanups {
+			f()
+		}
+		return ff
+	}
+
+	t.Run("no leaks", func(t *testing.T) {
+		ff := run(func() {
+			files.set("closed", true)
+			files.set("closed", false)
+		})
+		assert.Empty(t, ff.errors)
+	})
+
+	t.Run("leaked resource", func(t *testing.T) {
+		ff := run(func() { files.set("leaked", true) })
+		defer files.set("leaked", false)
+		require.Len(t, ff.errors, 1)
+		assert.Equal(t, "found leaked temporary files:\n  file leaked\n", ff.errors[0])
+	})
+
+	t.Run("leaked goroutine and resource", func(t *testing.T) {
+		var bg *blockedG
+		ff := run(func() {
+			bg = startBlockedG()
+			files.set("leaked", true)
+		})
+		bg.unblock()
+		files.set("leaked", false)
+		require.Len(t, ff.errors, 1)
+		assert.Contains(t, ff.errors[0], "found unexpected goroutines")
+		assert.Contains(t, ff.errors[0], "\nfound leaked temporary files:\n  file leaked\n")
+	})
+
+	t.Run("released while retrying", func(t *testing.T) {
+		ff := run(func() {
+			files.set("slow", true)
+			go files.set("slow", false)
+		})
+		assert.Empty(t, ff.errors)
+	})
+}
+
+func TestRegisterResourceTrackerReplaces(t *testing.T) {
+	withTracker(t, "files", &fakeFiles{})
+	replacement := &fakeFiles{}
+	RegisterResourceTracker("files", replacement)
+	trackers := registeredTrackers()
+	require.Len(t, trackers, 1)
+	assert.Same(t, replacement, trackers[0].ResourceTracker)
+}
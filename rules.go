@@ -0,0 +1,336 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of rules.
+const (
+	// RuleIgnore ignores the goroutines a rule matches,
+	// like [IgnoreTopFunction] and the other Ignore options.
+	RuleIgnore = "ignore"
+	// RuleInclude only checks the goroutines that this or another
+	// include rule matches, like [IncludeTopFunction] and the other
+	// Include options.
+	RuleInclude = "include"
+	// RuleAllowCount ignores the goroutines a rule matches as long as
+	// a check finds at most Count of them. If it finds more, all of
+	// them are reported.
+	RuleAllowCount = "allow-count"
+	// RuleQuarantine reports the goroutines a rule matches as warnings
+	// rather than failures, like [Quarantine].
+	RuleQuarantine = "quarantine"
+)
+
+// Rule describes goroutines that leak checks treat specially, so that
+// the filters of a program can be changed without rebuilding it; see
+// [WithRulesFile].
+//
+// A rule matches a goroutine if all of its match fields that are set
+// match, and must set at least one of them.
+type Rule struct {
+	// Action is what to do with the goroutines that the rule matches:
+	// RuleIgnore, RuleInclude, RuleAllowCount or RuleQuarantine.
+	Action string `json:"action" yaml:"action"`
+	// Count is the number of goroutines a RuleAllowCount rule allows.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+	// Reason says why the rule is needed. It's ignored by leak checks.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Top matches goroutines with the function on top of their stack.
+	Top string `json:"top,omitempty" yaml:"top,omitempty"`
+	// Any matches goroutines with the function anywhere in their stack.
+	Any string `json:"any,omitempty" yaml:"any,omitempty"`
+	// CreatedBy matches goroutines that the function created.
+	CreatedBy string `json:"created-by,omitempty" yaml:"created-by,omitempty"`
+	// Package matches goroutines with a function of the package
+	// anywhere in their stack, like [IgnoreAnyContainingPkg].
+	Package string `json:"package,omitempty" yaml:"package,omitempty"`
+	// Regex matches goroutines with a line of their stack trace
+	// that matches the regular expression, like [IgnoreStackMatching].
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	// State matches goroutines with the wait reason,
+	// e.g. "chan receive"; see [stack.Stack.WaitReason].
+	State string `json:"state,omitempty" yaml:"state,omitempty"`
+	// Duration matches goroutines that have been blocked for at least
+	// the duration, e.g. "10m", as parsed by [time.ParseDuration].
+	// The runtime only tells how long goroutines have been blocked
+	// in minutes, and only after a minute.
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	// Fingerprint matches goroutines with the fingerprint;
+	// see [stack.Stack.Fingerprint].
+	Fingerprint string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+}
+
+// String formats the action and match fields of the rule,
+// e.g. `allow-count(2) top="main.worker" state="IO wait"`.
+func (r Rule) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Action)
+	if r.Action == RuleAllowCount {
+		fmt.Fprintf(&sb, "(%d)", r.Count)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"top", r.Top},
+		{"any", r.Any},
+		{"created-by", r.CreatedBy},
+		{"package", r.Package},
+		{"regex", r.Regex},
+		{"state", r.State},
+		{"duration", r.Duration},
+		{"fingerprint", r.Fingerprint},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&sb, " %v=%q", field.name, field.value)
+		}
+	}
+	return sb.String()
+}
+
+// rulesFile is the format of rules files.
+type rulesFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// WithRules returns an option that applies the given rules,
+// or an error if any of them is invalid.
+func WithRules(rules ...Rule) (Option, error) {
+	return rulesOption("WithRules", rules)
+}
+
+// WithRulesFile reads rules from the YAML or JSON file at path and
+// returns an option that applies them, so that the goroutines that
+// leak checks ignore can be tuned without rebuilding the program:
+//
+//	rules:
+//	  - action: ignore
+//	    top: example.com/db.(*Pool).reaper
+//	    reason: The pool is shared by all tests.
+//	  - action: allow-count
+//	    created-by: example.com/server.(*Server).Serve
+//	    state: IO wait
+//	    count: 2
+//	  - action: quarantine
+//	    package: example.com/cache
+//	    duration: 10m
+//
+// See [Rule] for the fields of rules. Unknown fields are an error, so
+// that misspelled fields don't silently match more goroutines.
+func WithRulesFile(path string) (Option, error) {
+	rules, err := LoadRules(path)
+	if err != nil {
+		return nil, err
+	}
+	return rulesOption(fmt.Sprintf("WithRulesFile(%q)", path), rules)
+}
+
+// LoadRules reads the rules of the YAML or JSON file at path,
+// in the format described by [WithRulesFile], without checking them.
+func LoadRules(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRules(path, b)
+}
+
+// parseRules parses the contents b of the rules file at path.
+func parseRules(path string, b []byte) ([]Rule, error) {
+	var f rulesFile
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	// JSON is valid YAML, so one decoder reads both.
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse rules %v: %w", path, err)
+	}
+	return f.Rules, nil
+}
+
+// rulesOption checks the rules and combines them into an option,
+// naming them after the option that loaded them.
+func rulesOption(source string, rules []Rule) (Option, error) {
+	options := make(Options, 0, len(rules))
+	for i, r := range rules {
+		name := fmt.Sprintf("%v rule %d", source, i+1)
+		opt, err := CheckedOption(func() Option { return r.option(name) })
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, opt)
+	}
+	return options, nil
+}
+
+// option returns the option that applies r, named name.
+// It panics with an *OptionError if r is invalid.
+func (r Rule) option(name string) Option {
+	match := r.matcher(name)
+	switch r.Action {
+	case RuleIgnore:
+		return addFilter(name, match)
+	case RuleInclude:
+		return addInclude(match)
+	case RuleAllowCount:
+		checkNotNegative(name, r.Count)
+		return optionFunc(func(opts *opts) {
+			opts.allowances = append(opts.allowances, allowance{name: name, match: match, count: r.Count})
+		})
+	case RuleQuarantine:
+		return optionFunc(func(opts *opts) {
+			opts.quarantineMatch = append(opts.quarantineMatch, match)
+		})
+	case "":
+		invalidOption(name, "no action")
+	default:
+		invalidOption(name, "unknown action %q", r.Action)
+	}
+	return nil
+}
+
+// matcher returns a function that reports whether a goroutine
+// matches all the match fields of r.
+func (r Rule) matcher(name string) func(stack.Stack) bool {
+	var matches []func(stack.Stack) bool
+	if r.Top != "" {
+		checkFunctionName(name, r.Top)
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.FirstFunction() == r.Top
+		})
+	}
+	if r.Any != "" {
+		checkFunctionName(name, r.Any)
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.HasFunction(r.Any)
+		})
+	}
+	if r.CreatedBy != "" {
+		checkFunctionName(name, r.CreatedBy)
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.CreatedBy() == r.CreatedBy
+		})
+	}
+	if r.Package != "" {
+		checkName(name, "package", r.Package)
+		re := containingRegexp(r.Package)
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.HasFunctionFunc(re.MatchString)
+		})
+	}
+	if r.Regex != "" {
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			invalidOption(name, "%v", err)
+		}
+		matches = append(matches, func(s stack.Stack) bool {
+			for rest := s.Full(); rest != ""; {
+				var line string
+				line, rest, _ = strings.Cut(rest, "\n")
+				if re.MatchString(line) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+	if r.State != "" {
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.WaitReason() == r.State
+		})
+	}
+	if r.Duration != "" {
+		d, err := time.ParseDuration(r.Duration)
+		if err != nil {
+			invalidOption(name, "%v", err)
+		}
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.WaitDuration() >= d
+		})
+	}
+	if r.Fingerprint != "" {
+		checkName(name, "fingerprint", r.Fingerprint)
+		matches = append(matches, func(s stack.Stack) bool {
+			return s.Fingerprint() == r.Fingerprint
+		})
+	}
+	if len(matches) == 0 {
+		invalidOption(name, "no match fields")
+	}
+
+	return func(s stack.Stack) bool {
+		for _, match := range matches {
+			if !match(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// allowance ignores the goroutines it matches
+// as long as a check finds at most count of them.
+type allowance struct {
+	name  string
+	match func(stack.Stack) bool
+	count int
+}
+
+// allow removes the stacks of allowances that match at most their
+// count of them, and if counts is not nil, adds the number of removed
+// stacks to it. allow modifies the passed in stacks slice.
+func (o *opts) allow(stacks []stack.Stack, counts map[string]int) []stack.Stack {
+	for _, a := range o.allowances {
+		n := 0
+		for _, s := range stacks {
+			if a.match(s) {
+				n++
+			}
+		}
+		if n == 0 || n > a.count {
+			continue
+		}
+		kept := stacks[:0]
+		for _, s := range stacks {
+			if !a.match(s) {
+				kept = append(kept, s)
+			}
+		}
+		stacks = kept
+		if counts != nil {
+			counts[a.name] += n
+		}
+	}
+	for _, w := range o.rulesWatchers {
+		stacks = w.rules().allow(stacks, counts)
+	}
+	return stacks
+}
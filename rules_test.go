@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// _rulesDump holds the goroutines that the rules tests match rules against.
+var _rulesDump = strings.Join([]string{
+	"goroutine 7 [chan receive]:",
+	"main.worker()",
+	"	/app/main.go:42 +0x25",
+	"created by main.main in goroutine 1",
+	"	/app/main.go:20 +0x85",
+	"",
+	"goroutine 8 [chan receive, 12 minutes]:",
+	"main.worker()",
+	"	/app/main.go:42 +0x25",
+	"created by main.main in goroutine 1",
+	"	/app/main.go:20 +0x85",
+	"",
+	"goroutine 9 [IO wait]:",
+	"internal/poll.runtime_pollWait(0x7f0000000000, 0x72)",
+	"	/usr/local/go/src/runtime/netpoll.go:345 +0x85",
+	"example.com/server.(*conn).serve()",
+	"	/app/server/conn.go:10 +0x1",
+	"created by example.com/server.(*Server).Serve in goroutine 1",
+	"	/app/server/server.go:30 +0x85",
+	"",
+}, "\n")
+
+func rulesDump(t *testing.T) []stack.Stack {
+	stacks, err := stack.ParseDump([]byte(_rulesDump))
+	require.NoError(t, err)
+	return stacks
+}
+
+func stackIDs(stacks []stack.Stack) []int {
+	ids := make([]int, len(stacks))
+	for i, s := range stacks {
+		ids[i] = s.ID()
+	}
+	return ids
+}
+
+func TestWithRules(t *testing.T) {
+	stacks := rulesDump(t)
+
+	tests := []struct {
+		name string
+		rule Rule
+		want []int
+	}{
+		{"top", Rule{Action: RuleIgnore, Top: "main.worker"}, []int{9}},
+		{"any", Rule{Action: RuleIgnore, Any: "example.com/server.(*conn).serve"}, []int{7, 8}},
+		{"created-by", Rule{Action: RuleIgnore, CreatedBy: "example.com/server.(*Server).Serve"}, []int{7, 8}},
+		{"package", Rule{Action: RuleIgnore, Package: "example.com/server"}, []int{7, 8}},
+		{"regex", Rule{Action: RuleIgnore, Regex: `^\s+/app/main\.go:42 `}, []int{9}},
+		{"state", Rule{Action: RuleIgnore, State: "chan receive"}, []int{9}},
+		{"duration", Rule{Action: RuleIgnore, Duration: "10m"}, []int{7, 9}},
+		{"all fields", Rule{Action: RuleIgnore, Top: "main.worker", Duration: "10m"}, []int{7, 9}},
+		{"include", Rule{Action: RuleInclude, State: "IO wait"}, []int{9}},
+		{"allow-count within", Rule{Action: RuleAllowCount, Top: "main.worker", Count: 2}, []int{9}},
+		{"allow-count exceeded", Rule{Action: RuleAllowCount, Top: "main.worker", Count: 1}, []int{7, 8, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := WithRules(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stackIDs(FilterStacks(stacks, opt)))
+		})
+	}
+
+	t.Run("quarantine", func(t *testing.T) {
+		opt, err := WithRules(
+			Rule{Action: RuleQuarantine, Top: "main.worker", Duration: "1m"},
+			Rule{Action: RuleQuarantine, Fingerprint: stacks[2].Fingerprint()},
+		)
+		require.NoError(t, err)
+		leaks, quarantined := buildOpts(opt).splitQuarantined(stacks)
+		assert.Equal(t, []int{7}, stackIDs(leaks))
+		assert.Equal(t, []int{8, 9}, stackIDs(quarantined))
+	})
+
+	t.Run("filter usage", func(t *testing.T) {
+		resetFilterUsage(t)
+		opt, err := WithRules(
+			Rule{Action: RuleIgnore, State: "IO wait"},
+			Rule{Action: RuleAllowCount, Top: "main.worker", Count: 2},
+			Rule{Action: RuleIgnore, Top: "main.other"},
+		)
+		require.NoError(t, err)
+		assert.NoError(t, FindInDump([]byte(_rulesDump), opt))
+		assert.Equal(t, map[string]int{
+			"WithRules rule 1": 1,
+			"WithRules rule 2": 2,
+			"WithRules rule 3": 0,
+		}, FilterUsage())
+	})
+}
+
+func TestWithRulesInvalid(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		wantErr string
+	}{
+		{Rule{Top: "main.worker"}, "goleak: WithRules rule 1: no action"},
+		{Rule{Action: "skip", Top: "main.worker"}, `goleak: WithRules rule 1: unknown action "skip"`},
+		{Rule{Action: RuleIgnore}, "goleak: WithRules rule 1: no match fields"},
+		{Rule{Action: RuleIgnore, Top: "main.worker()"}, `goleak: WithRules rule 1: function name "main.worker()" must not include arguments`},
+		{Rule{Action: RuleIgnore, Regex: "("}, "goleak: WithRules rule 1: error parsing regexp: missing closing ): `(`"},
+		{Rule{Action: RuleIgnore, Duration: "soon"}, `goleak: WithRules rule 1: time: invalid duration "soon"`},
+		{Rule{Action: RuleAllowCount, Top: "main.worker", Count: -1}, "goleak: WithRules rule 1: negative value -1"},
+	}
+	for _, tt := range tests {
+		_, err := WithRules(tt.rule)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+}
+
+func TestWithRulesFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	stacks := rulesDump(t)
+
+	t.Run("yaml", func(t *testing.T) {
+		path := write(t, "rules.yaml", strings.Join([]string{
+			"rules:",
+			"  - action: ignore",
+			"    top: main.worker",
+			"    reason: Workers run for the lifetime of the process.",
+			"  - action: allow-count",
+			"    created-by: example.com/server.(*Server).Serve",
+			"    count: 1",
+		}, "\n"))
+		opt, err := WithRulesFile(path)
+		require.NoError(t, err)
+		assert.Empty(t, FilterStacks(stacks, opt))
+
+		rules, err := LoadRules(path)
+		require.NoError(t, err)
+		assert.Equal(t, Rule{
+			Action:    RuleAllowCount,
+			CreatedBy: "example.com/server.(*Server).Serve",
+			Count:     1,
+		}, rules[1])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		path := write(t, "rules.json", `{"rules": [{"action": "include", "state": "IO wait"}]}`)
+		opt, err := WithRulesFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, []int{9}, stackIDs(FilterStacks(stacks, opt)))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		opt, err := WithRulesFile(write(t, "empty.yaml", ""))
+		require.NoError(t, err)
+		assert.Len(t, FilterStacks(stacks, opt), 3)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := write(t, "typo.yaml", "rules:\n  - action: ignore\n    createdby: main.main\n")
+		_, err := WithRulesFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse rules "+path)
+		assert.Contains(t, err.Error(), "field createdby not found")
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		path := write(t, "invalid.yaml", "rules:\n  - action: ignore\n")
+		_, err := WithRulesFile(path)
+		assert.EqualError(t, err, `goleak: WithRulesFile("`+path+`") rule 1: no match fields`)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := WithRulesFile(filepath.Join(dir, "missing.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// RulesWatcher applies the rules of a rules file to leak checks and
+// reloads them when the file changes, so that long-running programs,
+// e.g. ones serving [Handler] or checking periodically with [Find],
+// can be tuned without a restart. See [WatchRulesFile].
+type RulesWatcher struct {
+	path     string
+	source   string
+	interval time.Duration
+	logger   *slog.Logger
+	stderr   io.Writer
+	self     int // ID of the goroutine that polls the file
+
+	// content and lastErr are only accessed by the polling goroutine.
+	content []byte
+	lastErr string
+	current atomic.Pointer[watchedRules]
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// watchedRules is the rules of a RulesWatcher as of a reload.
+type watchedRules struct {
+	rules []Rule
+	opts  *opts
+}
+
+// WatchRulesFile reads rules from the YAML or JSON file at path,
+// in the format described by [WithRulesFile], and polls the file for
+// changes every interval. Checks that are given [RulesWatcher.Option]
+// apply the rules that were last read:
+//
+//	rules, err := goleak.WatchRulesFile("/etc/app/goleak.yaml", 10*time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	defer rules.Stop()
+//	http.Handle("/debug/goleak", goleak.Handler(rules.Option()))
+//
+// Changes are logged with the rules that were added and removed,
+// through the logger given with [WithLogger], or to stderr without one;
+// other options are ignored. If the file can't be read or has invalid
+// rules, the error is logged and the previous rules are kept.
+// WatchRulesFile returns an error if the rules are invalid to begin with.
+//
+// The watcher polls in a background goroutine, which is ignored by
+// checks that are given its Option. It must be stopped with Stop.
+func WatchRulesFile(path string, interval time.Duration, options ...Option) (*RulesWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("non-positive interval %v", interval)
+	}
+	w := &RulesWatcher{
+		path:     path,
+		source:   fmt.Sprintf("WatchRulesFile(%q)", path),
+		interval: interval,
+		logger:   buildOnlyOpts(options...).logger,
+		stderr:   _osStderr,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := w.load(b)
+	if err != nil {
+		return nil, err
+	}
+	w.content = b
+	w.current.Store(rules)
+
+	started := make(chan int)
+	go w.run(started)
+	w.self = <-started
+	close(started)
+	return w, nil
+}
+
+// Option returns an option that applies the current rules of the
+// watcher to a check, and ignores the goroutine of the watcher.
+// Checks that run for a long time, such as [Handler] and
+// [DetectGrowth], apply the rules of every reload.
+//
+// The filters of include rules exclude the goroutines that they don't
+// match. Unlike with [IncludeTopFunction] and the other Include
+// options, goroutines must match them as well as any Include options
+// given to the check.
+func (w *RulesWatcher) Option() Option {
+	return optionFunc(func(opts *opts) {
+		opts.rulesWatchers = append(opts.rulesWatchers, w)
+	})
+}
+
+// Rules returns the rules that were last read.
+func (w *RulesWatcher) Rules() []Rule {
+	return append([]Rule(nil), w.current.Load().rules...)
+}
+
+// rules returns the current options of the rules of w.
+func (w *RulesWatcher) rules() *opts {
+	return w.current.Load().opts
+}
+
+// Stop stops polling the file and waits for the background goroutine
+// to exit. Checks keep applying the rules that were last read.
+// It is safe to call Stop multiple times.
+func (w *RulesWatcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+func (w *RulesWatcher) run(started chan int) {
+	defer close(w.done)
+
+	started <- stack.Current().ID()
+	<-started // wait for self to be set
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll reloads the rules if the file changed.
+func (w *RulesWatcher) poll() {
+	b, err := os.ReadFile(w.path)
+	if err == nil && bytes.Equal(b, w.content) {
+		w.lastErr = ""
+		return
+	}
+	var rules *watchedRules
+	if err == nil {
+		w.content = b
+		rules, err = w.load(b)
+	}
+	if err != nil {
+		// Errors are logged once, until the file changes again.
+		if msg := err.Error(); msg != w.lastErr {
+			w.lastErr = msg
+			w.logError(err)
+		}
+		return
+	}
+	w.lastErr = ""
+	old := w.current.Swap(rules)
+	added, removed := diffRules(old.rules, rules.rules)
+	w.logReload(added, removed)
+}
+
+// load parses and checks the rules of the file contents b.
+func (w *RulesWatcher) load(b []byte) (*watchedRules, error) {
+	rules, err := parseRules(w.path, b)
+	if err != nil {
+		return nil, err
+	}
+	opt, err := rulesOption(w.source, rules)
+	if err != nil {
+		return nil, err
+	}
+	opts := &opts{}
+	opt.apply(opts)
+	return &watchedRules{rules: rules, opts: opts}, nil
+}
+
+func (w *RulesWatcher) logError(err error) {
+	if w.logger != nil {
+		w.logger.Error("goleak: reload rules, keeping the previous rules", "path", w.path, "error", err)
+		return
+	}
+	fmt.Fprintf(w.stderr, "goleak: reload rules: %v; keeping the previous rules\n", err)
+}
+
+func (w *RulesWatcher) logReload(added, removed []string) {
+	if w.logger != nil {
+		w.logger.Info("goleak: reloaded rules", "path", w.path, "added", added, "removed", removed)
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "goleak: reloaded rules from %v:\n", w.path)
+	for _, r := range added {
+		sb.WriteString("  + " + r + "\n")
+	}
+	for _, r := range removed {
+		sb.WriteString("  - " + r + "\n")
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		sb.WriteString("  no changes to rules\n")
+	}
+	io.WriteString(w.stderr, sb.String())
+}
+
+// diffRules returns the rules of newer that older doesn't have, and
+// the rules of older that newer doesn't have, formatted with String.
+// Reordered rules are neither.
+func diffRules(older, newer []Rule) (added, removed []string) {
+	counts := make(map[string]int)
+	for _, r := range older {
+		counts[r.String()]++
+	}
+	for _, r := range newer {
+		if s := r.String(); counts[s] > 0 {
+			counts[s]--
+		} else {
+			added = append(added, s)
+		}
+	}
+	for _, r := range older {
+		if s := r.String(); counts[s] > 0 {
+			counts[s]--
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
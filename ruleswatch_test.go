@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a bytes.Buffer that's safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchRulesFile(t *testing.T) {
+	defer clearOSStubs()
+	var stderr lockedBuffer
+	_osStderr = &stderr
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(t *testing.T, content string) {
+		// Rename the file into place so that the watcher can't read it
+		// half-written.
+		require.NoError(t, os.WriteFile(path+".tmp", []byte(content), 0o644))
+		require.NoError(t, os.Rename(path+".tmp", path))
+	}
+	write(t, "rules:\n  - action: ignore\n    top: main.worker\n")
+
+	w, err := WatchRulesFile(path, time.Millisecond)
+	require.NoError(t, err)
+	defer w.Stop()
+	stacks := rulesDump(t)
+	filtered := func() []int { return stackIDs(FilterStacks(stacks, w.Option())) }
+	assert.Equal(t, []int{9}, filtered())
+
+	t.Run("ignores the watcher", func(t *testing.T) {
+		for _, s := range FilterStacks(stack.All(), w.Option()) {
+			assert.NotEqual(t, w.self, s.ID())
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		write(t, strings.Join([]string{
+			"rules:",
+			"  - action: ignore",
+			"    state: IO wait",
+			"  - action: allow-count",
+			"    top: main.worker",
+			"    count: 1",
+		}, "\n"))
+		require.Eventually(t, func() bool {
+			return strings.Contains(stderr.String(), "goleak: reloaded rules")
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, "goleak: reloaded rules from "+path+":\n"+
+			`  + ignore state="IO wait"`+"\n"+
+			`  + allow-count(1) top="main.worker"`+"\n"+
+			`  - ignore top="main.worker"`+"\n", stderr.String())
+		assert.Equal(t, []int{7, 8}, filtered())
+		assert.Len(t, w.Rules(), 2)
+	})
+
+	t.Run("invalid rules are kept", func(t *testing.T) {
+		write(t, "rules:\n  - action: ignore\n")
+		require.Eventually(t, func() bool {
+			return strings.Contains(stderr.String(), "goleak: reload rules:")
+		}, time.Second, time.Millisecond)
+		assert.Contains(t, stderr.String(),
+			`goleak: reload rules: goleak: WatchRulesFile("`+path+`") rule 1: no match fields; keeping the previous rules`)
+		assert.Equal(t, []int{7, 8}, filtered())
+	})
+
+	w.Stop()
+	w.Stop()
+	assert.Equal(t, []int{7, 8}, filtered(), "rules should apply after Stop")
+}
+
+func TestWatchRulesFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": []}`), 0o644))
+
+	var buf lockedBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	w, err := WatchRulesFile(path, time.Millisecond, WithLogger(logger))
+	require.NoError(t, err)
+	defer w.Stop()
+
+	content := `{"rules": [{"action": "quarantine", "created-by": "main.main"}]}`
+	require.NoError(t, os.WriteFile(path+".tmp", []byte(content), 0o644))
+	require.NoError(t, os.Rename(path+".tmp", path))
+	require.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "goleak: reloaded rules")
+	}, time.Second, time.Millisecond)
+	assert.Contains(t, buf.String(), `added="[quarantine created-by=\"main.main\"]" removed=[]`)
+
+	leaks, quarantined := buildOpts(w.Option()).splitQuarantined(rulesDump(t))
+	assert.Equal(t, []int{9}, stackIDs(leaks))
+	assert.Equal(t, []int{7, 8}, stackIDs(quarantined))
+}
+
+func TestWatchRulesFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := WatchRulesFile(filepath.Join(dir, "missing.yaml"), time.Second)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - action: skip\n    top: main.main\n"), 0o644))
+	_, err = WatchRulesFile(path, time.Second)
+	assert.EqualError(t, err, `goleak: WatchRulesFile("`+path+`") rule 1: unknown action "skip"`)
+
+	_, err = WatchRulesFile(path, 0)
+	assert.EqualError(t, err, "non-positive interval 0s")
+}
+
+func TestDiffRules(t *testing.T) {
+	a := Rule{Action: RuleIgnore, Top: "main.a"}
+	b := Rule{Action: RuleIgnore, Top: "main.b"}
+	c := Rule{Action: RuleAllowCount, Any: "main.c", Count: 2}
+
+	added, removed := diffRules([]Rule{a, b, b}, []Rule{c, b, a})
+	assert.Equal(t, []string{`allow-count(2) any="main.c"`}, added)
+	assert.Equal(t, []string{`ignore top="main.b"`}, removed)
+
+	added, removed = diffRules([]Rule{a, b}, []Rule{b, a})
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
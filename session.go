@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"sync"
+)
+
+// Session is a leak check around part of a test, started with [Begin].
+type Session struct {
+	parent *Session
+	// options are the options given to this session and those
+	// inherited from its parents.
+	options []Option
+	// existing ignores the goroutines running when the session began.
+	existing Option
+	ended    bool
+}
+
+// The innermost session that has not ended yet.
+var (
+	_sessionMu sync.Mutex
+	_session   *Session
+)
+
+// Begin starts a leak check session that is completed with End:
+//
+//	s := goleak.Begin()
+//	defer s.End(t)
+//
+// End fails the test if goroutines that were started after Begin are
+// still running. Sessions can be nested: sessions begun while another
+// session is active inherit its options, other than [Cleanup], and
+// ignore the goroutines started by the outer session up to that point.
+// This lets tests start long-lived infrastructure up-front and check
+// sub-operations in detail.
+//
+// Like [VerifyNone], sessions cannot be used with t.Parallel.
+func Begin(options ...Option) *Session {
+	_sessionMu.Lock()
+	defer _sessionMu.Unlock()
+
+	s := &Session{parent: _session}
+	if s.parent != nil {
+		s.options = append(s.options, inherited(s.parent.options))
+	}
+	s.options = append(s.options, options...)
+	s.existing = ignoreExisting()
+	_session = s
+	return s
+}
+
+// End marks the given TestingT as failed if goroutines started since
+// the session began are still running, retrying like [VerifyNone].
+// Sessions must be ended in the reverse order that they were begun;
+// ending a session also ends the sessions nested in it.
+func (s *Session) End(t TestingT) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	_sessionMu.Lock()
+	var err error
+	switch {
+	case s.ended:
+		err = errors.New("goleak: session ended more than once")
+	case _session != s:
+		err = errors.New("goleak: session ended before its nested sessions")
+	}
+	if !s.ended {
+		for cur := _session; cur != s.parent; cur = cur.parent {
+			cur.ended = true
+		}
+		_session = s.parent
+	}
+	_sessionMu.Unlock()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	VerifyNone(t, append(s.options, s.existing)...)
+}
+
+// inherited applies the options of a parent session to the check of a
+// nested session, without their cleanup functions, which only run when
+// the parent session ends.
+func inherited(options []Option) Option {
+	return optionFunc(func(opts *opts) {
+		cleanup := opts.cleanup
+		for _, o := range options {
+			o.apply(opts)
+		}
+		opts.cleanup = cleanup
+	})
+}
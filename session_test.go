@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	t.Run("no leaks", func(t *testing.T) {
+		ft := &fakeT{}
+		s := Begin()
+		s.End(ft)
+		assert.Empty(t, ft.errors)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		outer := Begin(testOptions())
+		infra := startBlockedG()
+
+		ft := &fakeT{}
+		inner := Begin()
+		inner.End(ft)
+		assert.Empty(t, ft.errors, "goroutines of the outer session should be ignored")
+
+		inner = Begin()
+		leak := startBlockedG()
+		inner.End(ft)
+		require.Len(t, ft.errors, 1, "goroutines of the inner session should be reported")
+		assert.Contains(t, ft.errors[0], "blockedG")
+		leak.unblock()
+
+		ft = &fakeT{}
+		outer.End(ft)
+		require.Len(t, ft.errors, 1, "goroutines of the outer session should be reported when it ends")
+		assert.Contains(t, ft.errors[0], "blockedG")
+		infra.unblock()
+	})
+
+	t.Run("nested cleanup", func(t *testing.T) {
+		var cleanups []string
+		outer := Begin(testOptions(), Cleanup(func(int) { cleanups = append(cleanups, "outer") }))
+		inner := Begin(Cleanup(func(int) { cleanups = append(cleanups, "inner") }))
+
+		ft := &fakeT{}
+		inner.End(ft)
+		assert.Empty(t, ft.errors)
+		assert.Equal(t, []string{"inner"}, cleanups, "nested sessions should not run the cleanup of the outer session")
+
+		outer.End(ft)
+		assert.Empty(t, ft.errors)
+		assert.Equal(t, []string{"inner", "outer"}, cleanups)
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		outer := Begin()
+		inner := Begin()
+
+		ft := &fakeT{}
+		outer.End(ft)
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "ended before its nested sessions")
+
+		ft = &fakeT{}
+		inner.End(ft)
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "ended more than once")
+	})
+}
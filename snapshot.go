@@ -0,0 +1,298 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Snapshot records how many goroutines share each fingerprint
+// at a point in time. Snapshots are JSON-serializable
+// so that they can be compared across runs or deployments.
+type Snapshot struct {
+	Time time.Time `json:"time"`
+	// Counts maps fingerprints to the number of goroutines with them.
+	// See [stack.Stack.Fingerprint].
+	Counts map[string]int `json:"counts"`
+	// Functions maps fingerprints to the top function of one of the
+	// goroutines with them, to make snapshots readable.
+	Functions map[string]string `json:"functions,omitempty"`
+	// States maps fingerprints to the number of goroutines with them
+	// in each state, e.g. "chan receive".
+	States map[string]map[string]int `json:"states,omitempty"`
+}
+
+// TakeSnapshot records the goroutines of the running process
+// that are not excluded by the given options.
+// The calling goroutine is not included.
+func TakeSnapshot(options ...Option) Snapshot {
+	opts := buildOpts(options...)
+	return newSnapshot(time.Now(), filterStacks(opts.stacks(), stack.Current().ID(), opts))
+}
+
+func newSnapshot(t time.Time, stacks []stack.Stack) Snapshot {
+	snap := Snapshot{
+		Time:      t,
+		Counts:    make(map[string]int),
+		Functions: make(map[string]string),
+		States:    make(map[string]map[string]int),
+	}
+	for _, s := range stacks {
+		fp := s.Fingerprint()
+		snap.Counts[fp]++
+		if _, ok := snap.Functions[fp]; !ok {
+			snap.Functions[fp] = s.FirstFunction()
+			snap.States[fp] = make(map[string]int)
+		}
+		snap.States[fp][s.State()]++
+	}
+	return snap
+}
+
+// SnapshotStore persists snapshots.
+type SnapshotStore interface {
+	Save(Snapshot) error
+}
+
+// SnapshotFile returns a SnapshotStore that writes every snapshot
+// to the file at path as JSON, replacing the previous one.
+// Use [LoadSnapshot] to read it back.
+func SnapshotFile(path string) SnapshotStore {
+	return snapshotFile(path)
+}
+
+type snapshotFile string
+
+func (path snapshotFile) Save(snap Snapshot) error {
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first
+	// so that readers never see a partial snapshot.
+	f, err := os.CreateTemp(filepath.Dir(string(path)), filepath.Base(string(path))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), string(path))
+}
+
+// LoadSnapshot reads a snapshot written by [SnapshotFile].
+func LoadSnapshot(path string) (Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("parse snapshot %v: %w", path, err)
+	}
+	return snap, nil
+}
+
+// PersistSnapshots makes [DetectGrowth] save a snapshot of the
+// goroutines it observed to store at the end of every window.
+// Errors are logged if a logger was given with [WithLogger].
+func PersistSnapshots(store SnapshotStore) Option {
+	return optionFunc(func(opts *opts) {
+		opts.snapshotStore = store
+	})
+}
+
+// SnapshotDiff describes how goroutines changed between two snapshots.
+// Each list is sorted by fingerprint.
+type SnapshotDiff struct {
+	// Before and After are the times of the older and newer snapshots.
+	Before, After time.Time
+	// Added lists fingerprints that are only present in the newer snapshot.
+	Added []SignatureDiff
+	// Removed lists fingerprints that are only present in the older snapshot.
+	Removed []SignatureDiff
+	// Grown lists fingerprints whose count increased.
+	Grown []SignatureDiff
+	// Changed lists fingerprints whose count didn't increase,
+	// but whose goroutines are in different states.
+	// It's only set if both snapshots have states.
+	Changed []SignatureDiff
+}
+
+// SignatureDiff is the change of a single fingerprint between snapshots.
+type SignatureDiff struct {
+	Fingerprint string
+	Function    string
+	From, To    int
+	// FromStates and ToStates count the goroutines in each state,
+	// if the snapshots have states.
+	FromStates, ToStates map[string]int
+}
+
+// Empty reports whether no goroutines were added, removed or grown.
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Grown) == 0
+}
+
+// DiffSnapshots compares the older snapshot a with the newer snapshot b.
+func DiffSnapshots(a, b Snapshot) SnapshotDiff {
+	function := func(fp string) string {
+		if f, ok := b.Functions[fp]; ok {
+			return f
+		}
+		return a.Functions[fp]
+	}
+
+	diff := SnapshotDiff{Before: a.Time, After: b.Time}
+	for fp, to := range b.Counts {
+		from, ok := a.Counts[fp]
+		sd := SignatureDiff{
+			Fingerprint: fp,
+			Function:    function(fp),
+			From:        from,
+			To:          to,
+			FromStates:  a.States[fp],
+			ToStates:    b.States[fp],
+		}
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, sd)
+		case to > from:
+			diff.Grown = append(diff.Grown, sd)
+		case sd.FromStates != nil && sd.ToStates != nil && !maps.Equal(sd.FromStates, sd.ToStates):
+			diff.Changed = append(diff.Changed, sd)
+		}
+	}
+	for fp, from := range a.Counts {
+		if _, ok := b.Counts[fp]; !ok {
+			diff.Removed = append(diff.Removed, SignatureDiff{
+				Fingerprint: fp,
+				Function:    function(fp),
+				From:        from,
+				FromStates:  a.States[fp],
+			})
+		}
+	}
+
+	for _, sds := range [][]SignatureDiff{diff.Added, diff.Removed, diff.Grown, diff.Changed} {
+		sort.Slice(sds, func(i, j int) bool { return sds[i].Fingerprint < sds[j].Fingerprint })
+	}
+	return diff
+}
+
+// String renders the diff like a unified diff of the goroutines,
+// one line per fingerprint: "+" for added and grown fingerprints,
+// "-" for removed ones and "~" for ones whose goroutines changed state,
+// e.g.:
+//
+//	--- before (2024-05-01T10:00:00Z)
+//	+++ after (2024-05-01T10:00:02Z)
+//	+ main.worker: 0 -> 2 goroutines (chan receive: 2) [3f2a9c]
+//	- main.setup: 1 -> 0 goroutines (select: 1) [8b1e07]
+//	~ main.pool: chan receive: 4 -> chan receive: 2, select: 2 [c4d5e6]
+func (d SnapshotDiff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- before%v\n+++ after%v\n", snapshotTime(d.Before), snapshotTime(d.After))
+	if d.Empty() && len(d.Changed) == 0 {
+		sb.WriteString("  no changes\n")
+		return sb.String()
+	}
+
+	counted := func(op string, sd SignatureDiff, states map[string]int) {
+		fmt.Fprintf(&sb, "%v %v: %d -> %d goroutines", op, sd.Function, sd.From, sd.To)
+		if len(states) > 0 {
+			fmt.Fprintf(&sb, " (%v)", stateCounts(states))
+		}
+		fmt.Fprintf(&sb, " [%v]\n", sd.Fingerprint)
+	}
+	for _, sd := range d.Added {
+		counted("+", sd, sd.ToStates)
+	}
+	for _, sd := range d.Grown {
+		counted("+", sd, sd.ToStates)
+	}
+	for _, sd := range d.Removed {
+		counted("-", sd, sd.FromStates)
+	}
+	for _, sd := range d.Changed {
+		fmt.Fprintf(&sb, "~ %v: %v -> %v [%v]\n",
+			sd.Function, stateCounts(sd.FromStates), stateCounts(sd.ToStates), sd.Fingerprint)
+	}
+	return sb.String()
+}
+
+func snapshotTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return " (" + t.Format(time.RFC3339Nano) + ")"
+}
+
+// stateCounts formats the number of goroutines in each state,
+// e.g. "chan receive: 2, select: 1", sorted by state.
+func stateCounts(states map[string]int) string {
+	strs := make([]string, 0, len(states))
+	for state, n := range states {
+		strs = append(strs, fmt.Sprintf("%v: %d", state, n))
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ", ")
+}
+
+// DiffFrom makes leak checks that find leaks also report how the
+// goroutines changed since snap was taken: which were started,
+// which exited and which changed state, rendered like
+// [SnapshotDiff.String]. This helps to debug the ordering of setup and
+// teardown, e.g. with a snapshot taken with [TakeSnapshot] after setup.
+//
+// The goroutines found by the check, i.e. those that its options
+// don't exclude, are compared with snap, so snap should be taken with
+// the same filters.
+func DiffFrom(snap Snapshot) Option {
+	return optionFunc(func(opts *opts) {
+		opts.diffFrom = &snap
+	})
+}
+
+// snapshotNotes renders the changes of the goroutines since the
+// snapshot given with DiffFrom, for the report of leaked stacks.
+func (o *opts) snapshotNotes(stacks []stack.Stack) string {
+	if o.diffFrom == nil {
+		return ""
+	}
+	diff := DiffSnapshots(*o.diffFrom, newSnapshot(time.Now(), stacks))
+	return "\nchanges since the snapshot:\n" + diff.String()
+}
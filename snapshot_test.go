@@ -0,0 +1,174 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	// Let goroutines of earlier tests exit so that they don't
+	// show up in one snapshot but not the other.
+	getStableAll(t, stack.Current())
+	before := TakeSnapshot()
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	after := TakeSnapshot()
+	diff := DiffSnapshots(before, after)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", diff.Added[0].Function)
+	assert.Equal(t, 1, diff.Added[0].To)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a := Snapshot{
+		Counts:    map[string]int{"same": 2, "grown": 1, "shrunk": 5, "removed": 1},
+		Functions: map[string]string{"removed": "main.old"},
+	}
+	b := Snapshot{
+		Counts:    map[string]int{"same": 2, "grown": 4, "shrunk": 3, "added": 2},
+		Functions: map[string]string{"added": "main.new", "grown": "main.grown"},
+	}
+
+	diff := DiffSnapshots(a, b)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []SignatureDiff{{Fingerprint: "added", Function: "main.new", To: 2}}, diff.Added)
+	assert.Equal(t, []SignatureDiff{{Fingerprint: "removed", Function: "main.old", From: 1}}, diff.Removed)
+	assert.Equal(t, []SignatureDiff{{Fingerprint: "grown", Function: "main.grown", From: 1, To: 4}}, diff.Grown)
+
+	assert.True(t, DiffSnapshots(a, a).Empty())
+}
+
+func TestSnapshotDiffString(t *testing.T) {
+	before := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	a := Snapshot{
+		Time:      before,
+		Counts:    map[string]int{"pool": 4, "setup": 1, "same": 1},
+		Functions: map[string]string{"pool": "main.pool", "setup": "main.setup", "same": "main.same"},
+		States: map[string]map[string]int{
+			"pool":  {"chan receive": 4},
+			"setup": {"select": 1},
+			"same":  {"select": 1},
+		},
+	}
+	b := Snapshot{
+		Time:      before.Add(2 * time.Second),
+		Counts:    map[string]int{"pool": 4, "worker": 2, "same": 1},
+		Functions: map[string]string{"pool": "main.pool", "worker": "main.worker", "same": "main.same"},
+		States: map[string]map[string]int{
+			"pool":   {"chan receive": 2, "select": 2},
+			"worker": {"chan receive": 2},
+			"same":   {"select": 1},
+		},
+	}
+
+	diff := DiffSnapshots(a, b)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "main.pool", diff.Changed[0].Function)
+	assert.False(t, diff.Empty())
+
+	assert.Equal(t, `--- before (2024-05-01T10:00:00Z)
++++ after (2024-05-01T10:00:02Z)
++ main.worker: 0 -> 2 goroutines (chan receive: 2) [worker]
+- main.setup: 1 -> 0 goroutines (select: 1) [setup]
+~ main.pool: chan receive: 4 -> chan receive: 2, select: 2 [pool]
+`, diff.String())
+
+	assert.Equal(t, "--- before\n+++ after\n  no changes\n", DiffSnapshots(Snapshot{}, Snapshot{}).String())
+}
+
+func TestDiffFrom(t *testing.T) {
+	getStableAll(t, stack.Current())
+	snap := TakeSnapshot()
+
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	err := Find(testOptions(), DiffFrom(snap))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changes since the snapshot:\n--- before (")
+	assert.Regexp(t, `\+ github.com/projectdiscovery/goleak.\(\*blockedG\).block: 0 -> 1 goroutines \(chan receive: 1\)`, err.Error())
+
+	err = Find(testOptions())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "changes since the snapshot")
+}
+
+type memorySnapshotStore struct {
+	mu    sync.Mutex
+	saved []Snapshot
+}
+
+func (s *memorySnapshotStore) Save(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, snap)
+	return nil
+}
+
+func (s *memorySnapshotStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.saved)
+}
+
+func TestPersistSnapshots(t *testing.T) {
+	defer VerifyNone(t)
+
+	t.Run("custom store", func(t *testing.T) {
+		store := &memorySnapshotStore{}
+		ctx, cancel := context.WithCancel(context.Background())
+		reports := DetectGrowth(ctx, 5*time.Millisecond, 1, PersistSnapshots(store))
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			for range reports {
+			}
+		}()
+
+		require.Eventually(t, func() bool { return store.len() >= 2 }, time.Second, time.Millisecond)
+		cancel()
+		<-drained
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "snapshot.json")
+		snap := TakeSnapshot()
+		snap.Counts["abc"] = 3
+		snap.Functions["abc"] = "main.worker"
+		require.NoError(t, SnapshotFile(path).Save(snap))
+
+		got, err := LoadSnapshot(path)
+		require.NoError(t, err)
+		assert.True(t, DiffSnapshots(snap, got).Empty(), "loaded snapshot should match saved one")
+		assert.Equal(t, "main.worker", got.Functions["abc"])
+	})
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// WithSourceContext makes [Pretty] reports show the source code around
+// two frames of each leaked goroutine, when their files are available
+// locally: the top frame outside the standard library, which is usually
+// where the goroutine is stuck, and the line that created the goroutine.
+// lines is the number of lines shown before and after each of them.
+// Zero, the default, shows no source.
+func WithSourceContext(lines int) Option {
+	checkNotNegative("WithSourceContext", lines)
+	return optionFunc(func(opts *opts) {
+		opts.sourceLines = lines
+	})
+}
+
+// sourceFiles caches the lines of the source files read for a report.
+// Files that can't be read are cached as nil.
+type sourceFiles map[string][]string
+
+func (sf sourceFiles) lines(file string) []string {
+	lines, ok := sf[file]
+	if !ok {
+		if b, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(b), "\n")
+		}
+		sf[file] = lines
+	}
+	return lines
+}
+
+// sourceContext renders the source around the top user frame
+// and the creation site of s, if their files can be read.
+func (o *opts) sourceContext(s stack.Stack, files sourceFiles) string {
+	var sb strings.Builder
+	if entry, ok := topUserEntry(s); ok {
+		writeSnippet(&sb, "Top Frame Source", entry, o.sourceLines, files)
+	}
+	if entry := s.SourceEntry(); entry.IsSource {
+		writeSnippet(&sb, "Creation Source", entry, o.sourceLines, files)
+	}
+	return sb.String()
+}
+
+// topUserEntry returns the first frame of s that's outside
+// the standard library.
+func topUserEntry(s stack.Stack) (stack.Entry, bool) {
+	for _, entry := range s.Entries() {
+		if entry.IsSource {
+			break
+		}
+		if !isStdLibFunction(entry.Function()) {
+			return entry, true
+		}
+	}
+	return stack.Entry{}, false
+}
+
+// isStdLibFunction reports whether the function with the given name,
+// e.g. "net/http.(*Server).Serve", belongs to the standard library,
+// whose import paths don't start with a domain name.
+func isStdLibFunction(name string) bool {
+	first, _, ok := strings.Cut(name, "/")
+	if !ok {
+		// Packages at the root, e.g. "fmt.Println" or "main.main".
+		pkg, _, _ := strings.Cut(name, ".")
+		return pkg != "main"
+	}
+	return !strings.Contains(first, ".")
+}
+
+// writeSnippet writes the lines around the location of entry,
+// marking its line, e.g.:
+//
+//	Top Frame Source: /app/main.go:20
+//	   19 |	for {
+//	>  20 |		<-ch
+//	   21 |	}
+func writeSnippet(sb *strings.Builder, title string, entry stack.Entry, context int, files sourceFiles) {
+	file, line := entry.File(), entry.Line()
+	lines := files.lines(file)
+	if line <= 0 || line > len(lines) {
+		return
+	}
+
+	fmt.Fprintf(sb, "%v: %v:%d\n", stack.Colors.BrightBlue(title), file, line)
+	first, last := max(line-context, 1), min(line+context, len(lines))
+	width := len(fmt.Sprint(last))
+	for n := first; n <= last; n++ {
+		text := fmt.Sprintf("%*d | %s", width, n, strings.TrimRight(lines[n-1], "\r"))
+		if n == line {
+			fmt.Fprintf(sb, "> %v\n", stack.Colors.BrightGreen(text))
+		} else {
+			fmt.Fprintf(sb, "  %v\n", text)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSourceContext(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join([]string{
+		"package main",
+		"",
+		"func main() {",
+		"	ch := make(chan int)",
+		"	go worker(ch)",
+		"}",
+		"",
+		"func worker(ch chan int) {",
+		"	<-ch",
+		"}",
+	}, "\n")), 0o644))
+
+	dump := []byte(fmt.Sprintf(`goroutine 7 [chan receive]:
+main.worker(0xc000010000)
+	%[1]v:9 +0x19
+created by main.main in goroutine 1
+	%[1]v:5 +0x1d
+`, file))
+
+	err := FindInDump(dump, Pretty())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Top Frame Source")
+
+	err = FindInDump(dump, Pretty(), WithSourceContext(1))
+	require.Error(t, err)
+	out := err.Error()
+	assert.Contains(t, out, "Top Frame Source")
+	assert.Contains(t, out, file+":9")
+	assert.Contains(t, out, "   8 | func worker(ch chan int) {")
+	assert.Contains(t, out, " 9 | \t<-ch")
+	assert.Contains(t, out, "  10 | }")
+	assert.NotContains(t, out, "7 | ", "only 1 line of context should be shown")
+
+	assert.Contains(t, out, "Creation Source")
+	assert.Contains(t, out, "5 | \tgo worker(ch)")
+	assert.Contains(t, out, "  4 | \tch := make(chan int)")
+
+	// Missing files are skipped.
+	dump = []byte(strings.ReplaceAll(string(dump), file, "/nonexistent/main.go"))
+	err = FindInDump(dump, Pretty(), WithSourceContext(1))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Top Frame Source")
+	assert.NotContains(t, err.Error(), "Creation Source")
+}
+
+func TestIsStdLibFunction(t *testing.T) {
+	tests := []struct {
+		give string
+		want bool
+	}{
+		{"fmt.Println", true},
+		{"net/http.(*Server).Serve", true},
+		{"main.main", false},
+		{"example.com/foo.worker", false},
+		{"github.com/projectdiscovery/goleak.(*blockedG).block", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isStdLibFunction(tt.give), tt.give)
+	}
+}
@@ -19,4 +19,12 @@
 // THE SOFTWARE.
 
 // Package stack is used for parsing stacks from `runtime.Stack`.
+//
+// # Stability
+//
+// All, AllWithBuffer, Current, ParseDump and the methods of Stack
+// are stable: they are covered by this module's semantic versioning,
+// so other tools can use them instead of parsing stacks themselves.
+// Other identifiers, such as Colors, BuildGraph and PrintGraph,
+// exist to render goleak's own output and may change.
 package stack
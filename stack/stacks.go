@@ -24,11 +24,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/logrusorgru/aurora/v4"
 )
@@ -38,8 +42,6 @@ const _defaultBufferSize = 64 * 1024 // 64 KiB
 var (
 	// Colors is an aurora instance with colors enabled.
 	Colors = aurora.New(aurora.WithColors(true))
-	// sourceGoroutineRe is a regexp to match the goroutine ID in a "created by" line.
-	sourceGoroutineRe = regexp.MustCompile(`goroutine (\d+)`)
 )
 
 // Entry represents a single entry in a Goroutine's stack.
@@ -52,22 +54,193 @@ type Entry struct {
 	IsSource bool
 }
 
+// File returns the path of the source file from the entry's Location,
+// e.g. "/home/user/foo/server.go" for a location like:
+//
+//	<tab>/home/user/foo/server.go:42 +0x1d
+//
+// It returns an empty string if the entry has no location.
+func (e Entry) File() string {
+	loc := strings.TrimPrefix(e.Location, "\t")
+	loc, _, _ = strings.Cut(loc, " +0x")
+	if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+		loc = loc[:i]
+	}
+	return loc
+}
+
+// Line returns the line number from the entry's Location,
+// e.g. 42 for a location like:
+//
+//	<tab>/home/user/foo/server.go:42 +0x1d
+//
+// It returns 0 if the entry has no location.
+func (e Entry) Line() int {
+	loc := strings.TrimPrefix(e.Location, "\t")
+	loc, _, _ = strings.Cut(loc, " +0x")
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return 0
+	}
+	line, _, _ := strings.Cut(loc[i+1:], " ")
+	n, err := strconv.Atoi(line)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Function returns the fully qualified name of the function of the
+// entry, without its arguments, e.g. "example.com/foo.(*Server).Serve".
+// For the "created by" entry, it's the function that created the
+// goroutine.
+func (e Entry) Function() string {
+	name, _, err := parseFuncName(e.FunctionCall)
+	if err != nil {
+		return e.FunctionCall
+	}
+	return name
+}
+
+// Arg is an argument word of a function call in a stack trace.
+type Arg struct {
+	// Value is the value of the word, e.g. a pointer or an integer.
+	// It's zero if the value is unknown.
+	Value uint64
+	// Known is false if the runtime couldn't tell the value,
+	// which is printed as "_".
+	Known bool
+	// Uncertain is true if the value may be stale,
+	// which is printed with a trailing "?".
+	Uncertain bool
+}
+
+// Args returns the arguments of the function call of the entry,
+// in the order printed by the runtime, e.g. the pointer to the receiver
+// of a method first. The runtime prints arguments as machine words, and
+// the words of aggregates such as structs and strings in braces; their
+// words are returned in place, so that an argument may span several
+// Args. The runtime prints at most ten words per frame and "..." if
+// there are more; only the printed words are returned.
+//
+// Args returns nil for the "created by" entry, and for functions
+// whose arguments weren't printed, e.g. inlined ones printed as "(...)".
+func (e Entry) Args() []Arg {
+	if e.IsSource {
+		return nil
+	}
+	call := strings.TrimSuffix(e.FunctionCall, ")")
+	i := strings.LastIndexByte(call, '(')
+	if i < 0 || len(call) == len(e.FunctionCall) {
+		return nil
+	}
+
+	var args []Arg
+	for _, word := range strings.Split(call[i+1:], ", ") {
+		word = strings.Trim(word, "{}")
+		switch word {
+		case "", "...":
+			continue
+		case "_":
+			args = append(args, Arg{})
+			continue
+		}
+		word, uncertain := strings.CutSuffix(word, "?")
+		value, err := strconv.ParseUint(word, 0, 64)
+		if err != nil {
+			continue
+		}
+		args = append(args, Arg{Value: value, Known: true, Uncertain: uncertain})
+	}
+	return args
+}
+
+// Ancestor is a goroutine that transitively created another goroutine.
+// Ancestors are only reported by the runtime when the program runs with
+// GODEBUG=tracebackancestors=N.
+type Ancestor struct {
+	// ID is the goroutine ID of the ancestor.
+	ID int
+	// Entries is the stack of the ancestor at the time it
+	// created its descendant.
+	Entries []Entry
+}
+
+// FirstFunction returns the name of the function at the top of the
+// ancestor's stack, like [Stack.FirstFunction], or an empty string if
+// it has none.
+func (a Ancestor) FirstFunction() string {
+	if len(a.Entries) == 0 {
+		return ""
+	}
+	return a.Entries[0].Function()
+}
+
 // Stack represents a single Goroutine's stack.
 type Stack struct {
 	id    int
 	state string // e.g. 'running', 'chan receive'
 
+	// Attributes parsed from state.
+	waitReason     string
+	waitDuration   time.Duration
+	lockedToThread bool
+
 	// The first function on the stack.
 	firstFunction string
 
-	// A set of all functions in the stack,
-	allFunctions map[string]struct{}
+	// ID of the goroutine that created this one, or 0 if unknown.
+	creatorID int
 
 	// Full, raw stack trace.
 	fullStack string
 
+	// The rest of the stack, parsed on first use.
+	frames *frames
+}
+
+// frames holds the parts of a stack that most filters don't need.
+// They are parsed from the raw stack trace on first use, so that
+// goroutines that are filtered by their first function or state
+// are never parsed in full.
+//
+// Stacks are copied by value, so frames is shared between copies
+// and guarded by once.
+type frames struct {
+	once sync.Once
+
+	// Raw tracebacks of the ancestors of the goroutine, if any.
+	ancestry string
+
+	// A set of all functions in the stack,
+	allFunctions map[string]struct{}
+
 	// entries is a list of stack entries
 	entries []Entry
+
+	// ancestors of this goroutine, closest first.
+	ancestors []Ancestor
+
+	err error
+}
+
+// _noFrames is used for stacks that weren't parsed from a trace,
+// such as the zero value.
+var _noFrames = &frames{}
+
+// parsed returns the frames of the stack, parsing them if needed.
+func (s Stack) parsed() *frames {
+	f := s.frames
+	if f == nil {
+		return _noFrames
+	}
+	f.once.Do(func() { f.err = f.parse(s.fullStack) })
+	if f.err != nil {
+		// The trace was already checked by ParseStack,
+		// or came from the runtime; either way it should parse.
+		panic(fmt.Sprintf("Failed to parse stack trace: %v\n%s", f.err, s.fullStack))
+	}
+	return f
 }
 
 // ID returns the goroutine ID.
@@ -80,6 +253,48 @@ func (s Stack) State() string {
 	return s.state
 }
 
+// WaitReason returns the Goroutine's state without the attributes
+// the runtime appends to it, e.g. "chan receive" for a goroutine
+// in state "chan receive, 5 minutes, locked to thread".
+func (s Stack) WaitReason() string {
+	return s.waitReason
+}
+
+// WaitDuration returns roughly how long the Goroutine has been blocked.
+// The runtime only reports this in minutes, for goroutines that have
+// been blocked for at least a minute; it is zero otherwise.
+func (s Stack) WaitDuration() time.Duration {
+	return s.waitDuration
+}
+
+// LockedToThread reports whether the Goroutine is locked to its
+// OS thread with runtime.LockOSThread.
+func (s Stack) LockedToThread() bool {
+	return s.lockedToThread
+}
+
+// Wait reasons that the runtime only uses for its own goroutines that
+// support garbage collection and finalizers.
+var _runtimeGCWaitReasons = map[string]struct{}{
+	"GC worker (idle)":   {},
+	"GC worker (active)": {},
+	"GC sweep wait":      {},
+	"GC scavenge wait":   {},
+	"force gc (idle)":    {},
+	"finalizer wait":     {},
+	"cleanup wait":       {},
+}
+
+// IsRuntimeGC reports whether the Goroutine is one of the runtime's
+// goroutines for garbage collection and finalizers,
+// such as a GC worker or the background scavenger.
+// These are reported in goroutine dumps of some Go versions and
+// GOTRACEBACK settings, but are never created by user code.
+func (s Stack) IsRuntimeGC() bool {
+	_, ok := _runtimeGCWaitReasons[s.waitReason]
+	return ok
+}
+
 // Full returns the full stack trace for this goroutine.
 func (s Stack) Full() string {
 	return s.fullStack
@@ -93,16 +308,43 @@ func (s Stack) FirstFunction() string {
 // HasFunction reports whether the stack has the given function
 // anywhere in it.
 func (s Stack) HasFunction(name string) bool {
-	_, ok := s.allFunctions[name]
+	if !strings.Contains(s.fullStack, name) {
+		// Avoid parsing the frames of stacks that can't match.
+		return false
+	}
+	_, ok := s.parsed().allFunctions[name]
 	return ok
 }
 
-func (s Stack) MatchAnyEntry(regex string) bool {
-	escapedRegex := regexp.QuoteMeta(regex)
-	re := regexp.MustCompile(escapedRegex)
+// HasFunctionFunc reports whether the stack has a function anywhere
+// in it for which match returns true.
+func (s Stack) HasFunctionFunc(match func(name string) bool) bool {
+	for name := range s.parsed().allFunctions {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFileFunc reports whether the stack has a frame anywhere in it
+// whose source file matches. Like with HasFunction,
+// the location of the "created by" line is not considered
+// since it belongs to the creator.
+func (s Stack) HasFileFunc(match func(file string) bool) bool {
+	for _, entry := range s.parsed().entries {
+		if !entry.IsSource && match(entry.File()) {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, entry := range s.entries {
-		if re.MatchString(entry.FunctionCall) {
+// MatchAnyEntry reports whether any entry of the stack contains
+// the given text. Despite its name, the text is matched literally.
+func (s Stack) MatchAnyEntry(text string) bool {
+	for _, entry := range s.parsed().entries {
+		if strings.Contains(entry.FunctionCall, text) {
 			return true
 		}
 	}
@@ -110,10 +352,13 @@ func (s Stack) MatchAnyEntry(regex string) bool {
 }
 
 // MatchAnyFunction reports whether the stack has any matching function
-// for given regex anywhere
+// for given regex anywhere.
+//
+// The regex is compiled on every call. When matching many stacks,
+// compile it once and use HasFunctionFunc(re.MatchString) instead.
 func (s Stack) MatchAnyFunction(regex string) bool {
 	re := regexp.MustCompile(regex)
-	for name := range s.allFunctions {
+	for name := range s.parsed().allFunctions {
 		if re.MatchString(name) {
 			return true
 		}
@@ -121,6 +366,83 @@ func (s Stack) MatchAnyFunction(regex string) bool {
 	return false
 }
 
+// Fingerprint returns an identifier for the shape of the stack:
+// the functions on it in order, and the function that created it.
+// Goroutines that were started from the same place and are blocked
+// in the same place share a fingerprint, regardless of their IDs,
+// arguments or state.
+//
+// Fingerprints are meant to identify the same leak across runs,
+// platforms and small code edits, so they're normalized: they don't
+// depend on pointer addresses, goroutine IDs or line numbers, or on the
+// runtime frames and wrappers that only some tracebacks show, e.g.
+// with GOTRACEBACK=system. They do depend on the numbering of closures,
+// e.g. ".func2", so that distinct closures of a function are told apart.
+// Use LineFingerprint to tell apart goroutines blocked on
+// different lines of the same functions, and LooseFingerprint to
+// ignore the numbering of closures.
+func (s Stack) Fingerprint() string {
+	return s.fingerprint(false, false)
+}
+
+// LineFingerprint is like Fingerprint, but also depends on the line
+// numbers of the frames, so it's more precise, but changes with edits
+// of the code around the stack.
+func (s Stack) LineFingerprint() string {
+	return s.fingerprint(true, false)
+}
+
+// LooseFingerprint is like Fingerprint, but doesn't depend on the
+// numbering of closures and of the wrappers of go and defer statements,
+// which changes when closures are added before them in the same
+// function. It's less precise: goroutines in different closures of the
+// same functions share it.
+func (s Stack) LooseFingerprint() string {
+	return s.fingerprint(false, true)
+}
+
+func (s Stack) fingerprint(lines, anyClosure bool) string {
+	h := fnv.New64a()
+	for _, entry := range s.parsed().entries {
+		name, _, err := parseFuncName(entry.FunctionCall)
+		if err != nil {
+			name = entry.FunctionCall
+		}
+		if !isFingerprintFrame(name, entry) {
+			continue
+		}
+		if anyClosure {
+			name = _closureIndexRE.ReplaceAllString(name, ".$1")
+		}
+		io.WriteString(h, name)
+		if lines {
+			fmt.Fprintf(h, ":%d", entry.Line())
+		}
+		h.Write([]byte{'\n'})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// _closureIndexRE matches the numbering of closures and of the wrappers
+// of go and defer statements in function names, e.g. ".func2" and
+// ".func1.3" for closures in closures, or ".gowrap1".
+var _closureIndexRE = regexp.MustCompile(`\.(func|gowrap|deferwrap)\d+(?:\.\d+)*`)
+
+// isFingerprintFrame reports whether the frame of the function
+// with the given name is shown in all tracebacks. Unexported runtime
+// functions, e.g. runtime.gopark, and autogenerated wrappers are only
+// shown in tracebacks of crashes and with GOTRACEBACK=system.
+func isFingerprintFrame(name string, entry Entry) bool {
+	if strings.HasPrefix(entry.Location, "\t<autogenerated>") {
+		return false
+	}
+	rest, ok := strings.CutPrefix(name, "runtime.")
+	if !ok || entry.IsSource {
+		return true
+	}
+	return rest != "" && 'A' <= rest[0] && rest[0] <= 'Z'
+}
+
 // String returns a string representation of the stack.
 func (s Stack) String() string {
 	return fmt.Sprintf(
@@ -128,25 +450,100 @@ func (s Stack) String() string {
 		s.id, s.state, s.firstFunction, s.Full())
 }
 
-// SourceGoroutineID returns the goroutine ID of the source goroutine
-func (s Stack) SourceGoroutineID() int {
-	for _, entry := range s.entries {
+// Trimmed returns a copy of the stack for display that keeps at most
+// maxFrames of its frames from the top, or all of them if maxFrames
+// is zero or less. If hideRuntime is true, frames of functions of the
+// runtime package are dropped first, unless only such frames remain.
+// The "created by" entry and ancestors are kept. Dropped frames are
+// marked with a line like "...3 frames elided...".
+//
+// Trimming changes what the stack's methods report, so it's meant
+// for stacks that are about to be printed, not for filtering.
+func (s Stack) Trimmed(maxFrames int, hideRuntime bool) Stack {
+	entries := s.parsed().entries
+	var calls []Entry
+	var source *Entry
+	for i, entry := range entries {
 		if entry.IsSource {
-			matches := sourceGoroutineRe.FindStringSubmatch(entry.FunctionCall)
-			if len(matches) == 2 {
-				id, err := strconv.Atoi(matches[1])
-				if err == nil {
-					return id
-				}
+			source = &entries[i]
+			continue
+		}
+		calls = append(calls, entry)
+	}
+
+	kept := calls
+	if hideRuntime {
+		kept = make([]Entry, 0, len(calls))
+		for _, entry := range calls {
+			if !strings.HasPrefix(entry.FunctionCall, "runtime.") {
+				kept = append(kept, entry)
 			}
 		}
+		if len(kept) == 0 {
+			kept = calls
+		}
+	}
+	if maxFrames > 0 && len(kept) > maxFrames {
+		kept = kept[:maxFrames]
+	}
+	if len(kept) == len(calls) {
+		return s
+	}
+
+	var full strings.Builder
+	for _, entry := range kept {
+		full.WriteString(entry.FunctionCall + "\n" + entry.Location + "\n")
+	}
+	fmt.Fprintf(&full, "...%d frames elided...\n", len(calls)-len(kept))
+	if source != nil {
+		full.WriteString(source.FunctionCall + "\n" + source.Location + "\n")
 	}
-	return -1
+	if strings.HasSuffix(s.fullStack, "\n\n") {
+		// Keep the blank line that separates stacks in dumps.
+		full.WriteByte('\n')
+	}
+
+	trimmed := s
+	trimmed.fullStack = full.String()
+	trimmed.frames = &frames{ancestry: s.parsed().ancestry}
+	return trimmed
+}
+
+// SourceGoroutineID returns the goroutine ID of the source goroutine,
+// or -1 if it is unknown. See CreatorID.
+func (s Stack) SourceGoroutineID() int {
+	if s.creatorID == 0 {
+		return -1
+	}
+	return s.creatorID
+}
+
+// CreatorID returns the ID of the goroutine that created this one,
+// taken from the "created by ... in goroutine N" line of the stack.
+// It returns 0 if the stack has no such line,
+// e.g. for the main goroutine or in dumps from Go 1.20 and earlier.
+func (s Stack) CreatorID() int {
+	return s.creatorID
+}
+
+// Ancestors returns the goroutines that led to the creation of this
+// goroutine, starting with its creator.
+//
+// Ancestors are only available for stacks captured while
+// GODEBUG=tracebackancestors=N is set; otherwise this returns nil.
+func (s Stack) Ancestors() []Ancestor {
+	return s.parsed().ancestors
+}
+
+// Entries returns the frames of the stack, from the top,
+// followed by its "created by" entry if it has one.
+func (s Stack) Entries() []Entry {
+	return slices.Clone(s.parsed().entries)
 }
 
 // SourceEntry returns the source entry of the stack
 func (s Stack) SourceEntry() Entry {
-	for _, entry := range s.entries {
+	for _, entry := range s.parsed().entries {
 		if entry.IsSource {
 			return entry
 		}
@@ -154,6 +551,17 @@ func (s Stack) SourceEntry() Entry {
 	return Entry{}
 }
 
+// CreatedBy returns the function that created this goroutine,
+// e.g. "example.com/foo.startWorker", or an empty string
+// if the stack has no "created by" line.
+func (s Stack) CreatedBy() string {
+	name, creator, err := parseFuncName(s.SourceEntry().FunctionCall)
+	if err != nil || !creator {
+		return ""
+	}
+	return name
+}
+
 // PrettyPrint generates a formatted string representation of the stack and uses given filters
 // to highlight any matching entries.
 func (s Stack) PrettyPrint(filter ...func(s Stack) bool) string {
@@ -161,7 +569,7 @@ func (s Stack) PrettyPrint(filter ...func(s Stack) bool) string {
 
 	// Identify the source entry if present
 	var source *Entry
-	for _, entry := range s.entries {
+	for _, entry := range s.parsed().entries {
 		if entry.IsSource {
 			source = &entry
 			break
@@ -175,18 +583,29 @@ func (s Stack) PrettyPrint(filter ...func(s Stack) bool) string {
 
 	// Append source or first function information
 	if source != nil {
-		buff.WriteString(Colors.BrightBlue("Source Goroutine ID").String() + ": " + Colors.BrightRed(sourceGoroutineRe.FindStringSubmatch(source.FunctionCall)[1]).String() + "\n")
+		if s.creatorID > 0 {
+			buff.WriteString(Colors.BrightBlue("Source Goroutine ID").String() + ": " + Colors.BrightRed(s.creatorID).String() + "\n")
+		}
 		buff.WriteString(Colors.BrightBlue("Created At").String() + ": " + Colors.BrightRed(strings.TrimPrefix(source.FunctionCall, "created by ")).String() + "\n")
 		buff.WriteString(Colors.BrightBlue("Location").String() + ": " + Colors.BrightRed(strings.TrimSpace(source.Location)).String() + "\n")
 	} else {
 		buff.WriteString(Colors.BrightBlue("First Function").String() + ": " + Colors.BrightRed(s.firstFunction).String() + "\n")
 	}
 
+	// Append the ancestry chain if tracebackancestors was enabled,
+	// or at least the link to the creator if it's known.
+	if ancestors := s.Ancestors(); len(ancestors) > 0 || s.creatorID > 0 {
+		buff.WriteString(Colors.BrightBlue("Ancestry").String() + ": " + Colors.BrightYellow(s.ancestryChain()).String() + "\n")
+		for _, ancestor := range ancestors {
+			buff.WriteString("  goroutine " + strconv.Itoa(ancestor.ID) + ": " + ancestor.FirstFunction() + "\n")
+		}
+	}
+
 	// Append the full stack trace header
 	buff.WriteString(Colors.BrightBlue("Full Stack").String() + ": " + "\n\n")
 
 	// Append each stack entry, applying filters if provided
-	for _, entry := range s.entries {
+	for _, entry := range s.parsed().entries {
 		matched := false
 		for _, f := range filter {
 			if f(s) {
@@ -206,9 +625,45 @@ func (s Stack) PrettyPrint(filter ...func(s Stack) bool) string {
 	return buff.String()
 }
 
-func getStacks(all bool) []Stack {
-	trace := getStackBuffer(all)
-	stacks, err := newStackParser(bytes.NewReader(trace)).Parse()
+// ancestryChain renders the goroutine IDs from this goroutine
+// up to its oldest known ancestor, e.g. "24 <- 21 <- 1".
+// Without tracebackancestors, only the creator is known.
+func (s Stack) ancestryChain() string {
+	ancestors := s.Ancestors()
+	ids := make([]string, 0, len(ancestors)+1)
+	ids = append(ids, strconv.Itoa(s.id))
+	for _, ancestor := range ancestors {
+		ids = append(ids, strconv.Itoa(ancestor.ID))
+	}
+	if len(ancestors) == 0 && s.creatorID > 0 {
+		ids = append(ids, strconv.Itoa(s.creatorID))
+	}
+	return strings.Join(ids, " <- ")
+}
+
+func getStacks(all bool, maxBytes int) []Stack {
+	stacks, _ := getLimitedStacks(all, maxBytes)
+	return stacks
+}
+
+// getLimitedStacks is getStacks that also reports whether the traces
+// were cut off at maxBytes.
+func getLimitedStacks(all bool, maxBytes int) (stacks []Stack, truncated bool) {
+	bufp := _bufferPool.Get().(*[]byte)
+	defer _bufferPool.Put(bufp)
+
+	var trace []byte
+	trace, *bufp, truncated = getStackBuffer(*bufp, all, maxBytes)
+	return parseTrace(trace), truncated
+}
+
+// parseTrace parses a trace produced by runtime.Stack.
+func parseTrace(trace []byte) []Stack {
+	p := newStackParser(bytes.NewReader(trace))
+	// The runtime's traces are well-formed,
+	// so they can be parsed in full on demand.
+	p.lazy = true
+	stacks, err := p.Parse()
 	if err != nil {
 		// Well-formed stack traces should never fail to parse.
 		// If they do, it's a bug in this package.
@@ -218,15 +673,36 @@ func getStacks(all bool) []Stack {
 	return stacks
 }
 
+// ParseDump parses a goroutine dump in the format produced by
+// runtime.Stack with all set, or by the debug=2 goroutine profile
+// of net/http/pprof. Unlike All, it reports malformed stacks as errors
+// and returns the stacks that could be parsed along with them.
+func ParseDump(dump []byte) ([]Stack, error) {
+	return newStackParser(bytes.NewReader(dump)).Parse()
+}
+
 // ParseStack parses a stack trace from the given buffer.
+// It is equivalent to ParseDump.
 func ParseStack(buf []byte) ([]Stack, error) {
-	return newStackParser(bytes.NewReader(buf)).Parse()
+	return ParseDump(buf)
+}
+
+// ParseReader parses a stack trace from r as it is read,
+// without holding the whole trace in memory.
+// This is preferable to ParseStack for large dumps read from
+// the network or from files.
+func ParseReader(r io.Reader) ([]Stack, error) {
+	return newStackParser(r).Parse()
 }
 
 type stackParser struct {
 	scan   *scanner
 	stacks []Stack
 	errors []error
+
+	// lazy defers parsing the frames of each stack until they're used.
+	// Otherwise, stacks are parsed in full so that errors are reported.
+	lazy bool
 }
 
 func newStackParser(r io.Reader) *stackParser {
@@ -240,7 +716,7 @@ func (p *stackParser) Parse() ([]Stack, error) {
 		line := p.scan.Text()
 
 		// If we see the goroutine header, start a new stack.
-		if strings.HasPrefix(line, "goroutine ") {
+		if isHeader(line) {
 			stack, err := p.parseStack(line)
 			if err != nil {
 				p.errors = append(p.errors, err)
@@ -258,6 +734,9 @@ func (p *stackParser) Parse() ([]Stack, error) {
 // line is the first line of the stack trace, which should look like:
 //
 //	goroutine 123 [runnable]:
+//
+// Only the header and the first function are parsed right away;
+// the rest of the trace is recorded for frames.parse.
 func (p *stackParser) parseStack(line string) (Stack, error) {
 	id, state, err := parseGoStackHeader(line)
 	if err != nil {
@@ -267,15 +746,12 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 	// Read the rest of the stack trace.
 	var (
 		firstFunction string
+		creatorID     int
 		fullStack     bytes.Buffer
 	)
-	funcs := make(map[string]struct{})
-	entries := make([]Entry, 0)
-	currentEntry := Entry{}
-
 	for p.scan.Scan() {
 		line := p.scan.Text()
-		if strings.HasPrefix(line, "goroutine ") {
+		if isHeader(line) {
 			// If we see the goroutine header,
 			// it's the end of this stack.
 			// Unscan so the next Scan sees the same line.
@@ -286,13 +762,101 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 		fullStack.WriteString(line)
 		fullStack.WriteByte('\n') // scanner trims the newline
 
+		if len(line) == 0 || isElided(line) {
+			continue
+		}
+
+		creator := strings.HasPrefix(line, "created by ")
+		if !creator && firstFunction == "" {
+			firstFunction, _, err = parseFuncName(line)
+			if err != nil {
+				return Stack{}, fmt.Errorf("parse function: %w", err)
+			}
+		}
+
+		// The function name is followed by its location,
+		// which starts with a tab.
+		if p.scan.Scan() {
+			if bs := p.scan.Bytes(); len(bs) > 0 && bs[0] == '\t' {
+				fullStack.Write(bs)
+				fullStack.WriteByte('\n')
+			} else {
+				p.scan.Unscan()
+			}
+		}
+
+		if creator {
+			creatorID = parseCreatorID(line)
+			// The "created by" line is the last line of the stack.
+			// See frames.parse.
+			break
+		}
+	}
+
+	f := &frames{ancestry: p.scanAncestry()}
+	stack := Stack{
+		id:            id,
+		state:         state,
+		firstFunction: firstFunction,
+		creatorID:     creatorID,
+		fullStack:     fullStack.String(),
+		frames:        f,
+	}
+	stack.waitReason, stack.waitDuration, stack.lockedToThread = parseState(state)
+	if !p.lazy {
+		f.once.Do(func() { f.err = f.parse(stack.fullStack) })
+		if f.err != nil {
+			return Stack{}, f.err
+		}
+	}
+	return stack, nil
+}
+
+// scanAncestry returns the raw ancestor tracebacks that follow
+// the "created by" line of a stack, if any. See parseAncestors.
+func (p *stackParser) scanAncestry() string {
+	var ancestry strings.Builder
+	for p.scan.Scan() {
+		line := p.scan.Text()
+		if !strings.HasPrefix(line, _ancestorPrefix) {
+			p.scan.Unscan()
+			break
+		}
+		ancestry.WriteString(line)
+		ancestry.WriteByte('\n')
+
+		for p.scan.Scan() {
+			line := p.scan.Text()
+			if len(line) == 0 ||
+				isHeader(line) ||
+				strings.HasPrefix(line, _ancestorPrefix) {
+				p.scan.Unscan()
+				break
+			}
+			ancestry.WriteString(line)
+			ancestry.WriteByte('\n')
+		}
+	}
+	return ancestry.String()
+}
+
+// parse parses the frames of the given stack trace,
+// excluding its header, and the ancestry recorded with it.
+func (f *frames) parse(fullStack string) error {
+	scan := newScanner(strings.NewReader(fullStack))
+	funcs := make(map[string]struct{})
+	entries := make([]Entry, 0)
+	currentEntry := Entry{}
+
+	for scan.Scan() {
+		line := scan.Text()
 		if len(line) == 0 {
 			// Empty line usually marks the end of the stack
 			// but we don't want to have to rely on that.
 			// Just skip it.
 			continue
 		}
-		if strings.HasPrefix(line, "...") && strings.HasSuffix(line, " frames elided...") {
+		if isElided(line) {
 			// e.g. ...23 frames elided...
 			// This indicates frames were elided from the stack trace,
 			// attempting to parse them via parseFuncName will fail resulting in a panic
@@ -302,7 +866,7 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 
 		funcName, creator, err := parseFuncName(line)
 		if err != nil {
-			return Stack{}, fmt.Errorf("parse function: %w", err)
+			return fmt.Errorf("parse function: %w", err)
 		}
 		currentEntry.FunctionCall = line
 		if !creator {
@@ -312,9 +876,6 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 			// The creator function is part of a different stack.
 			// We don't care about it right now.
 			funcs[funcName] = struct{}{}
-			if firstFunction == "" {
-				firstFunction = funcName
-			}
 		} else {
 			currentEntry.IsSource = true
 		}
@@ -323,20 +884,17 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 		//
 		//	<tab>example.com/path/to/package/file.go:123 +0x123
 		//
-		// We don't care about the position so we can skip this line.
-		if p.scan.Scan() {
+		if scan.Scan() {
 			// Be defensive:
 			// Skip the line only if it starts with a tab.
-			bs := p.scan.Bytes()
+			bs := scan.Bytes()
 			if len(bs) > 0 && bs[0] == '\t' {
 				currentEntry.Location = string(bs)
 				entries = append(entries, currentEntry)
-				fullStack.Write(bs)
-				fullStack.WriteByte('\n')
 			} else {
 				// Put it back and let the next iteration handle it
 				// if it doesn't start with a tab.
-				p.scan.Unscan()
+				scan.Unscan()
 			}
 		}
 
@@ -356,37 +914,185 @@ func (p *stackParser) parseStack(line string) (Stack, error) {
 			// testing.(*T).Run(...)
 			//         /usr/lib/go/src/testing/testing.go:1649 +0x3ad
 			//
+			// Those are recorded separately as ancestors.
 			break
 		}
 	}
+	if err := scan.Err(); err != nil {
+		return err
+	}
 
-	return Stack{
-		id:            id,
-		state:         state,
-		firstFunction: firstFunction,
-		allFunctions:  funcs,
-		fullStack:     fullStack.String(),
-		entries:       entries,
-	}, nil
+	ancestors, err := newStackParser(strings.NewReader(f.ancestry)).parseAncestors()
+	if err != nil {
+		return fmt.Errorf("parse ancestors: %w", err)
+	}
+
+	f.allFunctions = funcs
+	f.entries = entries
+	f.ancestors = ancestors
+	return nil
+}
+
+// isElided reports whether line marks frames that were elided from
+// a stack trace, e.g. "...23 frames elided...",
+// or that the whole trace is missing, e.g.
+// "goroutine running on other thread; stack unavailable".
+func isElided(line string) bool {
+	return strings.HasPrefix(line, "...") && strings.HasSuffix(line, " frames elided...") ||
+		isUnavailable(line)
+}
+
+// isUnavailable reports whether line says that the runtime could not
+// print a goroutine's stack, which it does in place of the frames.
+func isUnavailable(line string) bool {
+	return strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, "stack unavailable")
+}
+
+// isHeader reports whether line starts the trace of a new goroutine.
+func isHeader(line string) bool {
+	return strings.HasPrefix(line, "goroutine ") && !isUnavailable(line)
+}
+
+// parseAncestors parses the ancestor tracebacks that follow
+// the "created by" line of a stack
+// when GODEBUG=tracebackancestors=N is set.
+// Each ancestor looks like:
+//
+//	[originating from goroutine 1]:
+//	main.start(...)
+//		/path/to/main.go:30 +0x87
+//
+// It returns nil if there are no ancestors.
+func (p *stackParser) parseAncestors() ([]Ancestor, error) {
+	var ancestors []Ancestor
+	for p.scan.Scan() {
+		id, ok, err := parseAncestorHeader(p.scan.Text())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Not an ancestor. Let the caller handle it.
+			p.scan.Unscan()
+			break
+		}
+
+		ancestor := Ancestor{ID: id}
+		for p.scan.Scan() {
+			line := p.scan.Text()
+			if len(line) == 0 ||
+				isHeader(line) ||
+				strings.HasPrefix(line, _ancestorPrefix) {
+				p.scan.Unscan()
+				break
+			}
+			if isElided(line) {
+				continue
+			}
+
+			_, creator, err := parseFuncName(line)
+			if err != nil {
+				return nil, fmt.Errorf("parse function: %w", err)
+			}
+			entry := Entry{FunctionCall: line, IsSource: creator}
+			if p.scan.Scan() {
+				bs := p.scan.Bytes()
+				if len(bs) > 0 && bs[0] == '\t' {
+					entry.Location = string(bs)
+				} else {
+					p.scan.Unscan()
+				}
+			}
+			ancestor.Entries = append(ancestor.Entries, entry)
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+	return ancestors, nil
 }
 
 // All returns the stacks for all running goroutines.
 func All() []Stack {
-	return getStacks(true)
+	return getStacks(true, 0)
+}
+
+// AllWithBuffer returns the stacks for all running goroutines,
+// using buf to capture their traces. If buf is too small,
+// larger buffers are allocated as needed, as with All.
+// The returned stacks don't refer to buf, so it can be reused
+// by callers that take stacks repeatedly.
+func AllWithBuffer(buf []byte) []Stack {
+	if len(buf) == 0 {
+		buf = buf[:cap(buf)]
+	}
+	trace, _, _ := getStackBuffer(buf, true, 0)
+	return parseTrace(trace)
+}
+
+// AllLimited returns the stacks for all running goroutines,
+// capturing at most maxBytes of stack traces.
+// If the traces of all goroutines don't fit, the goroutines that
+// don't fit are omitted, and truncated is true. A maxBytes of zero
+// or less means no limit, like All.
+func AllLimited(maxBytes int) (stacks []Stack, truncated bool) {
+	return getLimitedStacks(true, maxBytes)
 }
 
 // Current returns the stack for the current goroutine.
 func Current() Stack {
-	return getStacks(false)[0]
+	return getStacks(false, 0)[0]
+}
+
+// Buffers for stack traces are reused between calls,
+// since they can grow to tens of megabytes in busy programs.
+var _bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, _defaultBufferSize)
+		return &buf
+	},
 }
 
-func getStackBuffer(all bool) []byte {
-	for i := _defaultBufferSize; ; i *= 2 {
-		buf := make([]byte, i)
-		if n := runtime.Stack(buf, all); n < i {
-			return buf[:n]
+// getStackBuffer captures stack traces into buf, replacing it with larger
+// buffers until the traces fit or it reaches maxBytes, if positive.
+// It returns the traces, the last buffer it used, and whether
+// the traces were cut off at maxBytes.
+func getStackBuffer(buf []byte, all bool, maxBytes int) (trace, used []byte, truncated bool) {
+	if len(buf) == 0 {
+		buf = make([]byte, _defaultBufferSize)
+	}
+	used = buf
+	if maxBytes > 0 && len(buf) > maxBytes {
+		buf = buf[:maxBytes]
+	}
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return buf[:n], used, false
+		}
+		if maxBytes > 0 && len(buf) >= maxBytes {
+			return trimPartialStack(buf[:n]), used, true
+		}
+
+		size := 2 * len(buf)
+		if maxBytes > 0 && size > maxBytes {
+			size = maxBytes
 		}
+		buf = make([]byte, size)
+		used = buf
+	}
+}
+
+// trimPartialStack drops the last stack from a trace
+// that was cut off, since it may be incomplete.
+func trimPartialStack(trace []byte) []byte {
+	// Stacks are separated by blank lines. Looking for those
+	// avoids mistaking "goroutine running on other thread" for a header.
+	if i := bytes.LastIndex(trace, []byte("\n\ngoroutine ")); i >= 0 {
+		return trace[:i+2]
+	}
+	// A single stack didn't fit. Keep its complete lines.
+	if i := bytes.LastIndexByte(trace, '\n'); i >= 0 {
+		return trace[:i+1]
 	}
+	return trace[:0]
 }
 
 // Parses a single function from the given line.
@@ -420,25 +1126,92 @@ func parseFuncName(line string) (name string, creator bool, err error) {
 	return name, creator, nil
 }
 
+// parseCreatorID returns the goroutine ID from a "created by" line
+// that looks like:
+// created by example.com/path/to/package.funcName in goroutine 1
+// Go 1.20 and earlier don't report the ID; this returns 0 for those.
+func parseCreatorID(line string) int {
+	_, after, ok := strings.Cut(line, " in goroutine ")
+	if !ok {
+		return 0
+	}
+	id, err := strconv.Atoi(after)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+const _ancestorPrefix = "[originating from goroutine "
+
+// parseAncestorHeader parses an ancestor header that looks like:
+// [originating from goroutine 1]:
+// And returns the goroutine ID of the ancestor.
+// ok is false if the line is not an ancestor header.
+func parseAncestorHeader(line string) (goroutineID int, ok bool, err error) {
+	rest, ok := strings.CutPrefix(line, _ancestorPrefix)
+	if !ok {
+		return 0, false, nil
+	}
+	rest = strings.TrimSuffix(strings.TrimSuffix(rest, ":"), "]")
+	id, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false, fmt.Errorf("bad ancestor goroutine ID %q in line %q", rest, line)
+	}
+	return id, true, nil
+}
+
+// parseState splits the state of a goroutine into its wait reason and the
+// attributes that may follow it, e.g.:
+//
+//	chan receive, 5 minutes, locked to thread
+//
+// Unknown attributes are ignored.
+func parseState(state string) (waitReason string, waitDuration time.Duration, lockedToThread bool) {
+	waitReason, attrs, _ := strings.Cut(state, ", ")
+	for attrs != "" {
+		var attr string
+		attr, attrs, _ = strings.Cut(attrs, ", ")
+		switch {
+		case attr == "locked to thread":
+			lockedToThread = true
+		case strings.HasSuffix(attr, " minutes"):
+			if n, err := strconv.Atoi(strings.TrimSuffix(attr, " minutes")); err == nil {
+				waitDuration = time.Duration(n) * time.Minute
+			}
+		}
+	}
+	return waitReason, waitDuration, lockedToThread
+}
+
 // parseGoStackHeader parses a stack header that looks like:
 // goroutine 643 [runnable]:\n
 // And returns the goroutine ID, and the state.
+//
+// Fields between the ID and the state are ignored.
+// Go 1.23+ adds some with GOTRACEBACK=system, e.g.:
+//
+//	goroutine 643 gp=0xc000007a40 m=nil [runnable]:
 func parseGoStackHeader(line string) (goroutineID int, state string, err error) {
 	// The scanner will have already trimmed the "\n",
 	// but we'll guard against it just in case.
 	//
 	// Trimming them separately makes them both optional.
 	line = strings.TrimSuffix(strings.TrimSuffix(line, ":"), "\n")
-	parts := strings.SplitN(line, " ", 3)
-	if len(parts) != 3 {
+	rest := strings.TrimPrefix(line, "goroutine ")
+	idStr, rest, ok := strings.Cut(rest, " ")
+	if !ok {
 		return 0, "", fmt.Errorf("unexpected format: %q", line)
 	}
 
-	id, err := strconv.Atoi(parts[1])
+	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return 0, "", fmt.Errorf("bad goroutine ID %q in line %q", parts[1], line)
+		return 0, "", fmt.Errorf("bad goroutine ID %q in line %q", idStr, line)
 	}
 
-	state = strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]")
+	state = rest
+	if i := strings.IndexByte(rest, '['); i >= 0 {
+		state = strings.TrimSuffix(rest[i+1:], "]")
+	}
 	return id, state, nil
 }
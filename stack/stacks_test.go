@@ -29,6 +29,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -71,10 +72,10 @@ func TestAll(t *testing.T) {
 	sort.Sort(byGoroutineID(got))
 
 	assert.Contains(t, got[0].Full(), "testing.(*T).Run")
-	assert.Contains(t, got[0].allFunctions, "testing.(*T).Run")
+	assert.True(t, got[0].HasFunction("testing.(*T).Run"))
 
 	assert.Contains(t, got[1].Full(), "TestAll")
-	assert.Contains(t, got[1].allFunctions, "github.com/projectdiscovery/goleak/stack.TestAll")
+	assert.True(t, got[1].HasFunction("github.com/projectdiscovery/goleak/stack.TestAll"))
 
 	for i := 0; i < 5; i++ {
 		assert.Contains(t, got[2+i].Full(), "stack.waitForDone")
@@ -133,6 +134,31 @@ func TestCurrentCreatedBy(t *testing.T) {
 	assert.True(t,
 		stack.HasFunction("github.com/projectdiscovery/goleak/stack.TestCurrentCreatedBy.func1"),
 		"TestCurrentCreatedBy.func1 is not in stack:\n%s", stack.Full())
+
+	assert.Equal(t, "github.com/projectdiscovery/goleak/stack.TestCurrentCreatedBy", stack.CreatedBy())
+	assert.True(t, stack.HasFunctionFunc(func(name string) bool {
+		return strings.HasSuffix(name, "TestCurrentCreatedBy.func1")
+	}))
+	assert.False(t, stack.HasFunctionFunc(func(name string) bool {
+		return name == "github.com/projectdiscovery/goleak/stack.TestCurrentCreatedBy"
+	}), "the creator should not be matched")
+	assert.Empty(t, Stack{}.CreatedBy())
+
+	assert.Equal(t, Current().ID(), stack.CreatorID())
+	assert.Equal(t, Current().ID(), stack.SourceGoroutineID())
+	assert.Zero(t, Stack{}.CreatorID())
+	assert.Equal(t, -1, Stack{}.SourceGoroutineID())
+}
+
+func TestLazyFrames(t *testing.T) {
+	cur := Current()
+	assert.Equal(t, "github.com/projectdiscovery/goleak/stack.getStackBuffer", cur.FirstFunction())
+	assert.Nil(t, cur.frames.allFunctions, "frames should not be parsed before they are used")
+
+	copied := cur
+	assert.True(t, copied.HasFunction("github.com/projectdiscovery/goleak/stack.TestLazyFrames"))
+	assert.NotNil(t, cur.frames.allFunctions, "frames should be shared between copies")
+	assert.NotEmpty(t, cur.SourceEntry().FunctionCall)
 }
 
 func TestAllLargeStack(t *testing.T) {
@@ -159,7 +185,7 @@ func TestAllLargeStack(t *testing.T) {
 	}
 
 	started.Wait()
-	buf := getStackBuffer(true /* all */)
+	buf, _, _ := getStackBuffer(nil, true /* all */, 0 /* maxBytes */)
 	if len(buf) <= _defaultBufferSize {
 		t.Fatalf("Expected larger stack buffer")
 	}
@@ -177,6 +203,101 @@ func TestAllLargeStack(t *testing.T) {
 	close(done)
 }
 
+func TestAllWithBuffer(t *testing.T) {
+	cur := Current()
+	for _, buf := range [][]byte{nil, make([]byte, 16), make([]byte, 0, 1<<20)} {
+		stacks := AllWithBuffer(buf)
+		var found bool
+		for _, s := range stacks {
+			if s.ID() == cur.ID() {
+				found = true
+				assert.True(t, s.HasFunction("github.com/projectdiscovery/goleak/stack.TestAllWithBuffer"))
+			}
+		}
+		assert.True(t, found, "current goroutine not found with buffer of size %v", len(buf))
+	}
+}
+
+func TestParseDump(t *testing.T) {
+	dump := "goroutine 1 [running]:\nmain.main()\n\t/main.go:1 +0x1\n\n" +
+		"goroutine 2 [chan receive]:\nnot a function\n"
+	stacks, err := ParseDump([]byte(dump))
+	require.Error(t, err)
+	require.Len(t, stacks, 1, "stacks that could be parsed should be returned")
+	assert.Equal(t, "main.main", stacks[0].FirstFunction())
+}
+
+func TestAllLimited(t *testing.T) {
+	var started sync.WaitGroup
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 50; i++ {
+		started.Add(1)
+		go func() {
+			started.Done()
+			<-done
+		}()
+	}
+	started.Wait()
+
+	all := All()
+	limited, truncated := AllLimited(4096)
+	assert.True(t, truncated)
+	assert.NotEmpty(t, limited)
+	assert.Less(t, len(limited), len(all), "goroutines that don't fit should be omitted")
+	// Stacks that were kept must be complete.
+	// Compare goroutines that were blocked in both dumps,
+	// since their stacks can't have changed.
+	blocked := make(map[int]string)
+	for _, s := range all {
+		if s.State() == "chan receive" {
+			blocked[s.ID()] = s.Full()
+		}
+	}
+	for _, s := range limited {
+		if full, ok := blocked[s.ID()]; ok && s.State() == "chan receive" {
+			assert.Equal(t, full, s.Full(), "incomplete stack")
+		}
+	}
+
+	unlimited, truncated := AllLimited(0)
+	assert.False(t, truncated)
+	assert.Greater(t, len(unlimited), 50, "non-positive limits should not limit")
+
+	_, truncated = AllLimited(1 << 20)
+	assert.False(t, truncated, "dumps that fit should not be truncated")
+}
+
+func TestTrimPartialStack(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{
+			give: "goroutine 1 [running]:\nmain.main()\n\t/main.go:1\n\ngoroutine 2 [chan receive]:\nmain.wo",
+			want: "goroutine 1 [running]:\nmain.main()\n\t/main.go:1\n\n",
+		},
+		{
+			give: "goroutine 1 [running]:\nmain.main()\n\t/ma",
+			want: "goroutine 1 [running]:\nmain.main()\n",
+		},
+		{give: "gorout", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(trimPartialStack([]byte(tt.give))))
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	dump := "goroutine 1 [running]:\nmain.main()\n\t/main.go:1 +0x1\n\n" +
+		"goroutine 2 [chan receive]:\nmain.worker()\n\t/main.go:5 +0x1\ncreated by main.main in goroutine 1\n\t/main.go:2 +0x1\n"
+	stacks, err := ParseReader(strings.NewReader(dump))
+	require.NoError(t, err)
+	require.Len(t, stacks, 2)
+	assert.Equal(t, "main.worker", stacks[1].FirstFunction())
+	assert.Equal(t, "main.main", stacks[1].CreatedBy())
+}
+
 func TestParseFuncName(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -218,6 +339,127 @@ func TestParseFuncName(t *testing.T) {
 	}
 }
 
+func TestEntryFile(t *testing.T) {
+	tests := []struct {
+		give string
+		want string
+	}{
+		{"\t/app/main.go:20 +0x85", "/app/main.go"},
+		{"\t/app/main.go:40", "/app/main.go"},
+		{"\tC:/Users/foo/main.go:40 +0x1d fp=0xc00006ff50 sp=0xc00006ff38 pc=0x4a1b3d", "C:/Users/foo/main.go"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Entry{Location: tt.give}.File(), "location %q", tt.give)
+	}
+}
+
+func TestEntryLine(t *testing.T) {
+	tests := []struct {
+		give string
+		want int
+	}{
+		{"\t/app/main.go:20 +0x85", 20},
+		{"\t/app/main.go:40", 40},
+		{"\tC:/Program Files/Go/src/main.go:40 fp=0xc00006ff50 sp=0xc00006ff38 pc=0x4a1b3d", 40},
+		{"\t/app/main.go", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Entry{Location: tt.give}.Line(), "location %q", tt.give)
+	}
+}
+
+func TestEntryArgs(t *testing.T) {
+	tests := []struct {
+		give     string
+		wantFunc string
+		want     []Arg
+	}{
+		{
+			give:     "main.worker(0xc000010000, 0x2a)",
+			wantFunc: "main.worker",
+			want:     []Arg{{Value: 0xc000010000, Known: true}, {Value: 0x2a, Known: true}},
+		},
+		{
+			give:     "example.com/foo.(*Server).Serve(0xc0000a4000?, {0x6b3f20, 0xc000012345}, _)",
+			wantFunc: "example.com/foo.(*Server).Serve",
+			want: []Arg{
+				{Value: 0xc0000a4000, Known: true, Uncertain: true},
+				{Value: 0x6b3f20, Known: true},
+				{Value: 0xc000012345, Known: true},
+				{},
+			},
+		},
+		{
+			give:     "main.many(0x1, {0x2, {0x3}}, ...)",
+			wantFunc: "main.many",
+			want:     []Arg{{Value: 1, Known: true}, {Value: 2, Known: true}, {Value: 3, Known: true}},
+		},
+		{give: "main.inlined(...)", wantFunc: "main.inlined"},
+		{give: "main.noArgs()", wantFunc: "main.noArgs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			e := Entry{FunctionCall: tt.give}
+			assert.Equal(t, tt.wantFunc, e.Function())
+			assert.Equal(t, tt.want, e.Args())
+		})
+	}
+
+	t.Run("created by", func(t *testing.T) {
+		e := Entry{FunctionCall: "created by main.start in goroutine 1", IsSource: true}
+		assert.Equal(t, "main.start", e.Function())
+		assert.Nil(t, e.Args())
+	})
+}
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		give         string
+		wantReason   string
+		wantDuration time.Duration
+		wantLocked   bool
+		wantGC       bool
+	}{
+		{give: "running", wantReason: "running"},
+		{give: "chan receive (nil chan)", wantReason: "chan receive (nil chan)"},
+		{give: "chan receive, 5 minutes", wantReason: "chan receive", wantDuration: 5 * time.Minute},
+		{give: "syscall, locked to thread", wantReason: "syscall", wantLocked: true},
+		{
+			give:         "select, 12 minutes, locked to thread",
+			wantReason:   "select",
+			wantDuration: 12 * time.Minute,
+			wantLocked:   true,
+		},
+		{give: "select, some future attribute", wantReason: "select"},
+		{give: "GC worker (idle)", wantReason: "GC worker (idle)", wantGC: true},
+		{give: "GC sweep wait", wantReason: "GC sweep wait", wantGC: true},
+		{give: "GC scavenge wait", wantReason: "GC scavenge wait", wantGC: true},
+		{give: "finalizer wait, 3 minutes", wantReason: "finalizer wait", wantDuration: 3 * time.Minute, wantGC: true},
+		{give: "GC assist wait", wantReason: "GC assist wait"}, // user goroutines assist the GC
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			stacks, err := ParseDump([]byte(joinLines(
+				"goroutine 1 ["+tt.give+"]:",
+				"runtime.gopark(0x0)",
+				"	runtime/proc.go:1 +0x1",
+			)))
+			require.NoError(t, err)
+			require.Len(t, stacks, 1)
+
+			s := stacks[0]
+			assert.Equal(t, tt.give, s.State())
+			assert.Equal(t, tt.wantReason, s.WaitReason())
+			assert.Equal(t, tt.wantDuration, s.WaitDuration())
+			assert.Equal(t, tt.wantLocked, s.LockedToThread())
+			assert.Equal(t, tt.wantGC, s.IsRuntimeGC())
+		})
+	}
+}
+
 func TestParseStack(t *testing.T) {
 	tests := []struct {
 		name string
@@ -326,6 +568,18 @@ func TestParseStackErrors(t *testing.T) {
 			give:    "goroutine [running]:",
 			wantErr: `unexpected format`,
 		},
+		{
+			name: "bad ancestor ID",
+			give: joinLines(
+				"goroutine 1 [running]:",
+				"example.com/foo/bar.baz()",
+				"	example.com/foo/bar.go:123",
+				"created by example.com/foo/bar.qux in goroutine 2",
+				"	example.com/foo/bar.go:456",
+				"[originating from goroutine foo]:",
+			),
+			wantErr: `bad ancestor goroutine ID "foo"`,
+		},
 		{
 			name: "bad function name",
 			give: joinLines(
@@ -357,6 +611,12 @@ func TestParseStackFixtures(t *testing.T) {
 
 		HasFunctions    []string // non-exhaustive, in any order
 		NotHasFunctions []string
+
+		// IDs of ancestors, closest first.
+		// Only populated with tracebackancestors.
+		AncestorIDs []int
+		// First functions of ancestors, if checked.
+		AncestorFunctions []string
 	}
 
 	tests := []struct {
@@ -467,6 +727,7 @@ func TestParseStackFixtures(t *testing.T) {
 					ID:            20,
 					State:         "IO wait",
 					FirstFunction: "internal/poll.runtime_pollWait",
+					AncestorIDs:   []int{1},
 					HasFunctions: []string{
 						"internal/poll.runtime_pollWait",
 						"net/http.Serve",
@@ -480,6 +741,11 @@ func TestParseStackFixtures(t *testing.T) {
 					ID:            24,
 					State:         "select",
 					FirstFunction: "net/http.(*persistConn).readLoop",
+					AncestorIDs:   []int{21, 1},
+					AncestorFunctions: []string{
+						"net/http.(*Transport).dialConn",
+						"net/http.(*Transport).queueForDial",
+					},
 					NotHasFunctions: []string{
 						"net/http.(*Transport).dialConn", // created by
 						// tracebackancestors:
@@ -494,6 +760,7 @@ func TestParseStackFixtures(t *testing.T) {
 					ID:            4,
 					State:         "IO wait",
 					FirstFunction: "internal/poll.runtime_pollWait",
+					AncestorIDs:   []int{20, 1},
 					HasFunctions: []string{
 						"internal/poll.runtime_pollWait",
 						"net/http.(*conn).serve",
@@ -510,6 +777,7 @@ func TestParseStackFixtures(t *testing.T) {
 					ID:            25,
 					State:         "select",
 					FirstFunction: "net/http.(*persistConn).writeLoop",
+					AncestorIDs:   []int{21, 1},
 					NotHasFunctions: []string{
 						"net/http.(*Transport).dialConn", // created by
 						// tracebackancestors:
@@ -557,6 +825,17 @@ func TestParseStackFixtures(t *testing.T) {
 				for _, fn := range wantStack.NotHasFunctions {
 					assert.False(t, gotStack.HasFunction(fn), "unexpected in stack: %v\n%s", fn, gotStack.Full())
 				}
+
+				var ancestorIDs []int
+				var ancestorFunctions []string
+				for _, a := range gotStack.Ancestors() {
+					ancestorIDs = append(ancestorIDs, a.ID)
+					ancestorFunctions = append(ancestorFunctions, a.FirstFunction())
+				}
+				assert.Equal(t, wantStack.AncestorIDs, ancestorIDs, "ancestors of %v", wantStack.ID)
+				if wantStack.AncestorFunctions != nil {
+					assert.Equal(t, wantStack.AncestorFunctions, ancestorFunctions, "ancestors of %v", wantStack.ID)
+				}
 			}
 
 			for _, s := range stacksByID {
@@ -566,6 +845,105 @@ func TestParseStackFixtures(t *testing.T) {
 	}
 }
 
+// TestParseCompatFixtures checks that goroutine dumps in the formats of
+// different Go releases parse strictly, i.e. without any errors.
+// The fixtures in testdata/compat are trimmed from real dumps,
+// except for future.txt, which makes up fields, wait reasons and
+// attributes that newer releases could add.
+func TestParseCompatFixtures(t *testing.T) {
+	type goroutine struct {
+		ID             int
+		WaitReason     string
+		WaitDuration   time.Duration
+		LockedToThread bool
+		FirstFunction  string
+		CreatedBy      string
+		CreatorID      int
+	}
+
+	tests := map[string][]goroutine{
+		"go1.18.txt": {
+			{ID: 1, WaitReason: "running", FirstFunction: "main.main"},
+			{ID: 6, WaitReason: "chan receive", FirstFunction: "main.worker", CreatedBy: "main.main"},
+			{
+				ID:            7,
+				WaitReason:    "select",
+				WaitDuration:  3 * time.Minute,
+				FirstFunction: "net/http.(*persistConn).writeLoop",
+				CreatedBy:     "net/http.(*Transport).dialConn",
+			},
+			{ID: 8, WaitReason: "semacquire", FirstFunction: "sync.runtime_Semacquire", CreatedBy: "main.main"},
+		},
+		"go1.21.txt": {
+			{ID: 1, WaitReason: "running", FirstFunction: "main.main"},
+			{ID: 6, WaitReason: "chan receive", FirstFunction: "main.worker", CreatedBy: "main.main", CreatorID: 1},
+			{
+				ID:             7,
+				WaitReason:     "select",
+				WaitDuration:   3 * time.Minute,
+				LockedToThread: true,
+				FirstFunction:  "runtime.gopark",
+				CreatedBy:      "main.main",
+				CreatorID:      1,
+			},
+			{ID: 8, WaitReason: "IO wait", FirstFunction: "internal/poll.runtime_pollWait", CreatedBy: "main.serve", CreatorID: 1},
+		},
+		"go1.23-system.txt": {
+			{ID: 1, WaitReason: "running", FirstFunction: "main.main"},
+			{ID: 2, WaitReason: "force gc (idle)", FirstFunction: "runtime.gopark", CreatedBy: "runtime.init.7", CreatorID: 1},
+			{ID: 18, WaitReason: "GC worker (idle)", FirstFunction: "runtime.gopark", CreatedBy: "runtime.gcBgMarkStartWorkers", CreatorID: 1},
+			{ID: 6, WaitReason: "running", CreatedBy: "main.main", CreatorID: 1},
+		},
+		"future.txt": {
+			{ID: 1, WaitReason: "running", FirstFunction: "main.main"},
+			{ID: 6, WaitReason: "some new wait reason", FirstFunction: "main.worker", CreatedBy: "main.main", CreatorID: 1},
+			{
+				ID:             7,
+				WaitReason:     "chan receive",
+				WaitDuration:   12 * time.Minute,
+				LockedToThread: true,
+				FirstFunction:  "main.loop",
+				CreatedBy:      "main.main",
+				CreatorID:      1,
+			},
+			{ID: 8, WaitReason: "sync.WaitGroup.Wait (durable)", FirstFunction: "sync.(*WaitGroup).Wait", CreatedBy: "main.main", CreatorID: 1},
+		},
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "compat", "*.txt"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		name := filepath.Base(file)
+		want, ok := tests[name]
+		if !assert.True(t, ok, "no expectations for fixture %v", name) {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			dump, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			stacks, err := ParseDump(dump)
+			require.NoError(t, err)
+
+			var got []goroutine
+			for _, s := range stacks {
+				got = append(got, goroutine{
+					ID:             s.ID(),
+					WaitReason:     s.WaitReason(),
+					WaitDuration:   s.WaitDuration(),
+					LockedToThread: s.LockedToThread(),
+					FirstFunction:  s.FirstFunction(),
+					CreatedBy:      s.CreatedBy(),
+					CreatorID:      s.CreatorID(),
+				})
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
 func joinLines(lines ...string) string {
 	return strings.Join(lines, "\n") + "\n"
 }
@@ -591,3 +969,202 @@ func isBackgroundRunning(cur Stack, stacks []Stack) bool {
 
 	return false
 }
+
+func TestPrettyPrintCreator(t *testing.T) {
+	dump, err := os.ReadFile(filepath.Join("testdata", "compat", "go1.21.txt"))
+	require.NoError(t, err)
+	stacks, err := ParseDump(dump)
+	require.NoError(t, err)
+	out := stacks[1].PrettyPrint()
+	assert.Contains(t, out, "6 <- 1", "creator link missing:\n%s", out)
+
+	// Go 1.20 and earlier don't report the creator's ID.
+	dump, err = os.ReadFile(filepath.Join("testdata", "compat", "go1.18.txt"))
+	require.NoError(t, err)
+	stacks, err = ParseDump(dump)
+	require.NoError(t, err)
+	out = stacks[1].PrettyPrint()
+	assert.NotContains(t, out, "Source Goroutine ID", "unexpected creator:\n%s", out)
+	assert.Contains(t, out, "main.worker")
+}
+
+func TestPrettyPrintAncestry(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "http.tracebackancestors.txt"))
+	require.NoError(t, err)
+
+	stacks, err := ParseStack(fixture)
+	require.NoError(t, err)
+
+	for _, s := range stacks {
+		if s.ID() != 24 {
+			continue
+		}
+		out := s.PrettyPrint()
+		assert.Contains(t, out, "24 <- 21 <- 1")
+		assert.Contains(t, out, "goroutine 21: net/http.(*Transport).dialConn\n")
+		return
+	}
+	t.Fatal("goroutine 24 not found in fixture")
+}
+
+func TestFingerprint(t *testing.T) {
+	stacks, err := ParseStack([]byte(joinLines(
+		"goroutine 1 [chan receive]:",
+		"example.com/foo/bar.baz(0xc000010000)",
+		"	example.com/foo/bar.go:123 +0x1",
+		"created by example.com/foo/bar.qux in goroutine 7",
+		"	example.com/foo/bar.go:456 +0x2",
+		"",
+		"goroutine 2 [chan receive, 2 minutes]:",
+		"example.com/foo/bar.baz(0xc000020000)",
+		"	example.com/foo/bar.go:123 +0x1",
+		"created by example.com/foo/bar.qux in goroutine 8",
+		"	example.com/foo/bar.go:456 +0x2",
+		"",
+		"goroutine 3 [chan receive]:",
+		"example.com/foo/bar.other(0xc000010000)",
+		"	example.com/foo/bar.go:123 +0x1",
+		"created by example.com/foo/bar.qux in goroutine 7",
+		"	example.com/foo/bar.go:456 +0x2",
+	)))
+	require.NoError(t, err)
+	require.Len(t, stacks, 3)
+
+	assert.Equal(t, stacks[0].Fingerprint(), stacks[1].Fingerprint(),
+		"IDs, arguments and states should not affect the fingerprint")
+	assert.NotEqual(t, stacks[0].Fingerprint(), stacks[2].Fingerprint(),
+		"different functions should have different fingerprints")
+}
+
+func TestFingerprintNormalization(t *testing.T) {
+	stacks, err := ParseStack([]byte(joinLines(
+		// As shown by runtime.Stack.
+		"goroutine 7 [chan receive]:",
+		"example.com/foo.(*Server).Start.func1()",
+		"	/home/alice/foo/server.go:42 +0x25",
+		"created by example.com/foo.(*Server).Start in goroutine 1",
+		"	/home/alice/foo/server.go:40 +0x85",
+		"",
+		// The same leak as shown by GOTRACEBACK=system on another
+		// machine.
+		"goroutine 12 [chan receive]:",
+		"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/proc.go:402 +0xce fp=0xc00005ff20 sp=0xc00005ff00 pc=0x43a0ae",
+		"runtime.chanrecv1(0xc000010000?, 0x0?)",
+		"	/usr/local/go/src/runtime/chan.go:442 +0x12 fp=0xc00005ff48 sp=0xc00005ff20 pc=0x4059d2",
+		"example.com/foo.(*Server).Start.func1()",
+		"	C:/src/foo/server.go:47 +0x25 fp=0xc00005ffe0 sp=0xc00005ff48 pc=0x4d1c05",
+		"runtime.goexit({})",
+		"	/usr/local/go/src/runtime/asm_amd64.s:1695 +0x1 fp=0xc00005ffe8 sp=0xc00005ffe0 pc=0x46e0e1",
+		"created by example.com/foo.(*Server).Start in goroutine 3",
+		"	C:/src/foo/server.go:45 +0x85",
+		"",
+		// Blocked on another line of the same function.
+		"goroutine 8 [chan receive]:",
+		"example.com/foo.(*Server).Start.func1()",
+		"	/home/alice/foo/server.go:43 +0x25",
+		"created by example.com/foo.(*Server).Start in goroutine 1",
+		"	/home/alice/foo/server.go:40 +0x85",
+		"",
+		// Distinct nested closures and wrappers of go statements.
+		"goroutine 9 [select]:",
+		"example.com/foo.run.func3.2()",
+		"	/home/alice/foo/run.go:10 +0x25",
+		"created by example.com/foo.run.gowrap1 in goroutine 1",
+		"	/home/alice/foo/run.go:20 +0x85",
+		"",
+		"goroutine 10 [select]:",
+		"example.com/foo.run.func1.1()",
+		"	/home/alice/foo/run.go:12 +0x25",
+		"example.com/foo.(*T).Wait(0xc000010000)",
+		"	<autogenerated>:1 +0x25",
+		"created by example.com/foo.run.gowrap2 in goroutine 1",
+		"	/home/alice/foo/run.go:22 +0x85",
+	)))
+	require.NoError(t, err)
+	require.Len(t, stacks, 5)
+
+	assert.Equal(t, stacks[0].Fingerprint(), stacks[1].Fingerprint(),
+		"runtime frames, paths and addresses should not affect the fingerprint")
+	assert.Equal(t, stacks[0].Fingerprint(), stacks[2].Fingerprint(),
+		"line numbers should not affect the fingerprint")
+	assert.NotEqual(t, stacks[3].Fingerprint(), stacks[4].Fingerprint(),
+		"distinct closures should have distinct fingerprints")
+	assert.Equal(t, stacks[3].LooseFingerprint(), stacks[4].LooseFingerprint(),
+		"closure numbers and autogenerated wrappers should not affect the loose fingerprint")
+	assert.Equal(t, stacks[0].LooseFingerprint(), stacks[1].LooseFingerprint())
+
+	assert.NotEqual(t, stacks[0].LineFingerprint(), stacks[2].LineFingerprint(),
+		"line numbers should affect the line fingerprint")
+	assert.NotEqual(t, stacks[0].Fingerprint(), stacks[0].LineFingerprint())
+}
+
+func TestTrimmed(t *testing.T) {
+	stacks, err := ParseStack([]byte(joinLines(
+		"goroutine 7 [chan receive]:",
+		"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/proc.go:402 +0xce",
+		"runtime.chanrecv1(0xc000010000?, 0x0?)",
+		"	/usr/local/go/src/runtime/chan.go:442 +0x12",
+		"example.com/foo.worker(...)",
+		"	/app/foo/worker.go:20",
+		"example.com/foo.run(0xc000010000)",
+		"	/app/foo/run.go:12 +0x1d",
+		"example.com/foo.start.func1()",
+		"	/app/foo/run.go:5 +0x1d",
+		"created by example.com/foo.start in goroutine 1",
+		"	/app/foo/run.go:4 +0x25",
+	)))
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	s := stacks[0]
+
+	assert.Equal(t, s, s.Trimmed(0, false), "nothing to trim")
+	assert.Equal(t, s, s.Trimmed(5, false), "nothing to trim")
+
+	trimmed := s.Trimmed(2, true)
+	assert.Equal(t, joinLines(
+		"example.com/foo.worker(...)",
+		"	/app/foo/worker.go:20",
+		"example.com/foo.run(0xc000010000)",
+		"	/app/foo/run.go:12 +0x1d",
+		"...3 frames elided...",
+		"created by example.com/foo.start in goroutine 1",
+		"	/app/foo/run.go:4 +0x25",
+	), trimmed.Full())
+	assert.Equal(t, "example.com/foo.start", trimmed.CreatedBy())
+	assert.Equal(t, 7, trimmed.ID())
+	assert.Equal(t, "chan receive", trimmed.State())
+	assert.False(t, trimmed.HasFunction("runtime.gopark"))
+
+	trimmed = s.Trimmed(1, false)
+	assert.Equal(t, []string{"runtime.gopark", "example.com/foo.start"}, functions(trimmed))
+	assert.True(t, s.HasFunction("example.com/foo.run"), "the original stack should not change")
+}
+
+func TestTrimmedOnlyRuntime(t *testing.T) {
+	stacks, err := ParseStack([]byte(joinLines(
+		"goroutine 2 [force gc (idle)]:",
+		"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/proc.go:402 +0xce",
+		"runtime.forcegchelper()",
+		"	/usr/local/go/src/runtime/proc.go:326 +0xb3",
+		"created by runtime.init.6 in goroutine 1",
+		"	/usr/local/go/src/runtime/proc.go:314 +0x1a",
+	)))
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	assert.Equal(t, stacks[0], stacks[0].Trimmed(0, true), "stacks of the runtime should be kept")
+}
+
+// functions returns the functions of the entries of s, including its creator.
+func functions(s Stack) []string {
+	var names []string
+	for _, entry := range s.Entries() {
+		name, _, err := parseFuncName(entry.FunctionCall)
+		if err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _processStart approximates when the process started: package
+// initialization runs before main, so the difference is negligible.
+var _processStart = time.Now()
+
+// IgnoreStartupGoroutines ignores the goroutines that are alive at the
+// end of the given grace period after the process started, treating
+// them as the intended infrastructure of a service, such as its servers,
+// pools and background workers. Only goroutines started later are
+// reported, which suits long-running checks of services, e.g. with
+// [Handler], [StartTimeline] or [DetectGrowth], without an ignore
+// for every component:
+//
+//	http.Handle("/debug/goleak", goleak.Handler(
+//		goleak.IgnoreStartupGoroutines(30*time.Second),
+//	))
+//
+// Until the grace period ends, all goroutines are ignored. The option
+// should be created early, e.g. in main; if it's created after the grace
+// period, the goroutines alive at that time are ignored instead, like
+// with [IgnoreCurrent].
+func IgnoreStartupGoroutines(grace time.Duration) Option {
+	if grace < 0 {
+		invalidOption("IgnoreStartupGoroutines", "negative grace period %v", grace)
+	}
+	return ignoreStartup(time.Until(_processStart.Add(grace)))
+}
+
+// ignoreStartup records the goroutines that are alive after d.
+func ignoreStartup(d time.Duration) Option {
+	s := &startupGoroutines{}
+	if d > 0 {
+		time.AfterFunc(d, s.record)
+	} else {
+		s.record()
+	}
+	return addFilter("IgnoreStartupGoroutines()", s.contains)
+}
+
+// startupGoroutines is the set of goroutines
+// that are alive at the end of a grace period.
+type startupGoroutines struct {
+	recorded atomic.Bool
+	Baseline
+}
+
+func (s *startupGoroutines) record() {
+	s.Refresh()
+	s.recorded.Store(true)
+}
+
+// contains reports whether g is alive at the end of the grace period,
+// or whether the grace period hasn't ended yet.
+func (s *startupGoroutines) contains(g stack.Stack) bool {
+	return !s.recorded.Load() || s.Baseline.contains(g)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreStartupGoroutines(t *testing.T) {
+	t.Run("after grace period", func(t *testing.T) {
+		startup := startBlockedG()
+		defer startup.unblock()
+		opt := IgnoreStartupGoroutines(0)
+
+		require.NoError(t, Find(testOptions(), opt), "goroutines alive after the grace period are ignored")
+
+		later := startBlockedG()
+		defer later.unblock()
+		require.Error(t, Find(testOptions(), opt), "goroutines started later are reported")
+	})
+
+	t.Run("during grace period", func(t *testing.T) {
+		opt := ignoreStartup(50 * time.Millisecond)
+		startup := startBlockedG()
+		defer startup.unblock()
+
+		assert.NoError(t, Find(testOptions(), opt), "all goroutines are ignored during the grace period")
+
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, Find(testOptions(), opt), "goroutines alive at the end of the grace period are ignored")
+
+		later := startBlockedG()
+		defer later.unblock()
+		assert.Error(t, Find(testOptions(), opt), "goroutines started later are reported")
+	})
+
+	t.Run("negative grace period", func(t *testing.T) {
+		assert.Panics(t, func() { IgnoreStartupGoroutines(-time.Second) })
+	})
+}
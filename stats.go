@@ -0,0 +1,154 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats describes the work done by a leak check,
+// e.g. to monitor how much time leak checks add to CI
+// and to tune their retries with data.
+type Stats struct {
+	// Scanned is the number of goroutines in the last goroutine dump
+	// that was checked, including the goroutine running the check.
+	Scanned int
+	// Filtered counts the goroutines of the last dump that were excluded
+	// from the check, by the option that excluded them,
+	// e.g. `IgnoreTopFunction("example.com/foo.worker")`.
+	// Each goroutine is counted for the first option that excludes it;
+	// the built-in filters come first. Goroutines excluded because they
+	// don't match any Include option are counted as "Include options".
+	Filtered map[string]int
+	// Leaked is the number of goroutines reported as leaks.
+	Leaked int
+	// Retries is the number of times the check retried
+	// after its first attempt.
+	Retries int
+	// Duration is the wall time of the check, including retries.
+	Duration time.Duration
+}
+
+// OnStats registers a function that is called with the [Stats]
+// of every leak check once it completes, whether it found leaks or not.
+func OnStats(f func(Stats)) Option {
+	return optionFunc(func(opts *opts) {
+		opts.onStats = f
+	})
+}
+
+// Goroutines excluded by each filter in the leak checks of the process.
+var (
+	_filterUsageMu sync.Mutex
+	_filterUsage   = make(map[string]int)
+)
+
+// FilterUsage returns how many goroutines each option that ignores
+// goroutines excluded in the leak checks of the process so far, by the
+// name of the option as in [Stats]. Options that were given to checks
+// but never excluded a goroutine are included with a count of zero,
+// which helps to find suppressions that no longer match anything.
+//
+// Only the options given to checks, with [SetDefaults] or by profiles
+// are included, not the [DefaultFilters]. Like in Stats, each goroutine
+// is counted for the first option that excludes it, so an option that
+// only matches goroutines excluded by earlier options counts as unused.
+// The goroutines of the last attempt of each check are counted.
+func FilterUsage() map[string]int {
+	_filterUsageMu.Lock()
+	defer _filterUsageMu.Unlock()
+
+	usage := make(map[string]int, len(_filterUsage))
+	for name, n := range _filterUsage {
+		usage[name] = n
+	}
+	return usage
+}
+
+// FailOnUnusedFilters makes [VerifyTestMain] fail the test run if any
+// option that ignores goroutines, given to leak checks during the run,
+// never excluded a goroutine, as reported by [FilterUsage]. The run
+// fails the same way as on leaks, with the exit code of [ExitCodeOnLeak].
+func FailOnUnusedFilters() Option {
+	return optionFunc(func(opts *opts) {
+		opts.failOnUnusedFilters = true
+	})
+}
+
+// recordFilterUsage adds the goroutines counted for the filters
+// of a check to the usage of the process.
+func (o *opts) recordFilterUsage(counts map[string]int) {
+	names := o.filterNames()
+	if len(names) == 0 {
+		return
+	}
+
+	_filterUsageMu.Lock()
+	defer _filterUsageMu.Unlock()
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		// Filters that share a name share their count.
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			_filterUsage[name] += counts[name]
+		}
+	}
+}
+
+// filterNames returns the names of the filters and allowances of the
+// options, including those of the current rules of watched rules files.
+// Internal filters are left out.
+func (o *opts) filterNames() []string {
+	var names []string
+	for _, f := range o.filters {
+		if !f.internal {
+			names = append(names, f.name)
+		}
+	}
+	for _, a := range o.allowances {
+		names = append(names, a.name)
+	}
+	for _, w := range o.rulesWatchers {
+		names = append(names, w.rules().filterNames()...)
+	}
+	return names
+}
+
+// unusedFiltersError returns an error naming the filters that
+// excluded no goroutines in the process, if any.
+func unusedFiltersError() error {
+	var unused []string
+	for name, n := range FilterUsage() {
+		if n == 0 {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return errors.New("options that ignore goroutines matched none during the run:\n  " +
+		strings.Join(unused, "\n  "))
+}
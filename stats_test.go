@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnStats(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	t.Run("leak", func(t *testing.T) {
+		var stats []Stats
+		err := Find(testOptions(), StableAfter(0), OnStats(func(s Stats) { stats = append(stats, s) }))
+		require.Error(t, err)
+		require.Len(t, stats, 1, "stats should be reported once per check")
+
+		s := stats[0]
+		assert.Equal(t, 1, s.Leaked)
+		assert.Equal(t, _defaultRetries, s.Retries)
+		assert.Positive(t, s.Duration)
+		assert.GreaterOrEqual(t, s.Scanned, 2, "dump has at least the checker and the leak")
+		assert.Positive(t, s.Filtered["IgnoreTestStacks()"], "test runner goroutines are filtered")
+	})
+
+	t.Run("filtered by option", func(t *testing.T) {
+		// Goroutines of the testing package that are finishing up
+		// the previous subtest would cause a retry.
+		waitForStable(t)
+
+		const fn = "github.com/projectdiscovery/goleak.(*blockedG).block"
+		var stats Stats
+		require.NoError(t, Find(testOptions(), IgnoreTopFunction(fn), OnStats(func(s Stats) { stats = s })))
+		assert.Equal(t, 0, stats.Leaked)
+		assert.Equal(t, 0, stats.Retries)
+		assert.Equal(t, 1, stats.Filtered[`IgnoreTopFunction("`+fn+`")`])
+	})
+
+	t.Run("includes", func(t *testing.T) {
+		var stats Stats
+		require.NoError(t, FindOnly(testOptions(), IncludeTopFunction("example.com/foo.worker"), OnStats(func(s Stats) { stats = s })))
+		assert.Positive(t, stats.Filtered["Include options"])
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		dump := strings.Join([]string{
+			"goroutine 7 [chan receive]:",
+			"main.worker()",
+			"	/app/main.go:20 +0x19",
+			"created by main.main in goroutine 1",
+			"	/app/main.go:10 +0x1d",
+			"",
+			"goroutine 8 [select]:",
+			"main.poller()",
+			"	/app/main.go:30 +0x19",
+			"created by main.main in goroutine 1",
+			"	/app/main.go:11 +0x1d",
+			"",
+		}, "\n")
+		var stats Stats
+		err := FindInDump([]byte(dump), IgnoreTopFunction("main.poller"), OnStats(func(s Stats) { stats = s }))
+		require.Error(t, err)
+		assert.Equal(t, Stats{
+			Scanned:  2,
+			Filtered: map[string]int{`IgnoreTopFunction("main.poller")`: 1},
+			Leaked:   1,
+			Duration: stats.Duration,
+		}, stats)
+	})
+}
+
+// resetFilterUsage clears FilterUsage until the test ends.
+func resetFilterUsage(t *testing.T) {
+	_filterUsageMu.Lock()
+	saved := _filterUsage
+	_filterUsage = make(map[string]int)
+	_filterUsageMu.Unlock()
+
+	t.Cleanup(func() {
+		_filterUsageMu.Lock()
+		_filterUsage = saved
+		_filterUsageMu.Unlock()
+	})
+}
+
+func TestFilterUsage(t *testing.T) {
+	resetFilterUsage(t)
+	waitForStable(t)
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	const blockFn = "github.com/projectdiscovery/goleak.(*blockedG).block"
+	require.NoError(t, Find(testOptions(), IgnoreTopFunction(blockFn), IgnoreTopFunction("example.com/unused.worker")))
+	require.NoError(t, Find(testOptions(), IgnoreAnyFunction(blockFn), IgnoreTopFunction(blockFn)))
+	assert.Equal(t, map[string]int{
+		`IgnoreTopFunction("` + blockFn + `")`:           1,
+		`IgnoreTopFunction("example.com/unused.worker")`: 0,
+		`IgnoreAnyFunction("` + blockFn + `")`:           1,
+	}, FilterUsage(), "goroutines count for the first option that excludes them")
+
+	dump := []byte("goroutine 7 [chan receive]:\nexample.com/unused.worker()\n\t/app/worker.go:10 +0x25\n")
+	require.NoError(t, FindInDump(dump, IgnoreTopFunction("example.com/unused.worker")))
+	assert.Equal(t, 1, FilterUsage()[`IgnoreTopFunction("example.com/unused.worker")`], "dumps count too")
+}
+
+func TestFilterUsageInternal(t *testing.T) {
+	resetFilterUsage(t)
+	VerifyWithin(t, func() {}, testOptions())
+	t.Run("fuzz", func(t *testing.T) { VerifyNoneF(t, testOptions()) })
+	session := Begin(testOptions())
+	session.End(t)
+	assert.Empty(t, FilterUsage(), "filters added by checks should not be tracked")
+	assert.NoError(t, unusedFiltersError())
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// _childReportEnv names the environment variable with the path of the
+// file that child processes started with VerifySubprocess report to.
+const _childReportEnv = "GOLEAK_CHILD_REPORT"
+
+// childReport is the line a child process appends to the report file
+// of its parent.
+type childReport struct {
+	Args []string `json:"args"`
+	PID  int      `json:"pid"`
+	Report
+}
+
+// VerifySubprocess marks the given TestingF as failed if cmd, a Go
+// child process such as a test binary or a helper program, leaks
+// goroutines. Call it before starting cmd:
+//
+//	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperServer$")
+//	goleak.VerifySubprocess(t, cmd)
+//	require.NoError(t, cmd.Run())
+//
+// It sets the GOLEAK_CHILD_REPORT environment variable of cmd to a
+// temporary file, on top of os.Environ() if cmd.Env is nil. The child
+// checks for leaks when it exits and reports them to the file, if it
+// calls [ReportToParent], or [VerifyTestMain] for test binaries. Once
+// the test completes, the leaks of the child and of any processes it
+// started in turn are reported as errors of the test.
+//
+// Children that don't report, e.g. because they were killed, are not
+// checked.
+func VerifySubprocess(t TestingF, cmd *exec.Cmd) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	f, err := os.CreateTemp("", "goleak-child-*.jsonl")
+	if err != nil {
+		t.Error(fmt.Sprintf("goleak: create child report: %v", err))
+		return
+	}
+	path := f.Name()
+	f.Close()
+
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, _childReportEnv+"="+path)
+	t.Cleanup(func() {
+		defer os.Remove(path)
+		reports, err := readChildReports(path)
+		if err != nil {
+			t.Error(fmt.Sprintf("goleak: %v", err))
+			return
+		}
+		for _, r := range reports {
+			if len(r.Leaks) > 0 {
+				t.Error(r.String())
+			}
+		}
+	})
+}
+
+// ReportToParent checks for leaks like [Find] and reports them to the
+// test that started the process with [VerifySubprocess]. It does
+// nothing if the process wasn't started that way. Call it when the
+// child is about to exit, e.g. deferred in main:
+//
+//	func main() {
+//		defer goleak.ReportToParent()
+//		// ...
+//	}
+//
+// Note that deferred calls don't run if the program exits with
+// os.Exit or log.Fatal. [VerifyTestMain] reports to the parent on its
+// own, so test binaries don't need to call ReportToParent.
+func ReportToParent(options ...Option) {
+	path := os.Getenv(_childReportEnv)
+	if path == "" {
+		return
+	}
+	opts := buildOpts(options...)
+	leaks, check := findLeaks(stack.Current().ID(), opts)
+	writeChildReport(path, opts.report(leaks, check))
+}
+
+// recordChildReport prepares VerifyTestMain to report its findings to
+// the parent test named by GOLEAK_CHILD_REPORT, if it's set. It returns
+// a function to call once the check is done.
+func (o *opts) recordChildReport() func() {
+	path := os.Getenv(_childReportEnv)
+	if path == "" {
+		return func() {}
+	}
+
+	report := NewReport(nil)
+	onLeakReport := o.onLeakReport
+	o.onLeakReport = func(r Report) {
+		report = r
+		if onLeakReport != nil {
+			onLeakReport(r)
+		}
+	}
+	return func() {
+		writeChildReport(path, report)
+	}
+}
+
+// writeChildReport appends the report of this process to the report
+// file of the parent at path.
+func writeChildReport(path string, report Report) {
+	r := childReport{Args: os.Args, PID: os.Getpid(), Report: report}
+	if err := appendJSONLine(path, r); err != nil {
+		fmt.Fprintf(_osStderr, "goleak: report to parent: %v\n", err)
+	}
+}
+
+// readChildReports reads the reports that child processes appended
+// to the file at path.
+func readChildReports(path string) ([]childReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var reports []childReport
+	scan := bufio.NewScanner(f)
+	scan.Buffer(nil, 64<<20) // lines hold full stacks
+	for n := 1; scan.Scan(); n++ {
+		if len(strings.TrimSpace(scan.Text())) == 0 {
+			continue
+		}
+		var r childReport
+		if err := json.Unmarshal(scan.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("parse child report %v:%d: %w", path, n, err)
+		}
+		reports = append(reports, r)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, fmt.Errorf("read child report %v: %w", path, err)
+	}
+	return reports, nil
+}
+
+// String describes the leaks of the child process like the errors of
+// Find describe leaks.
+func (r childReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "found unexpected goroutines in child process %d %q:\n", r.PID, r.Args)
+	for _, leak := range r.Leaks {
+		fmt.Fprintf(&sb, "Goroutine %v in state %v, with %v on top of the stack:\n%s\n",
+			leak.ID, leak.State, leak.FirstFunction, leak.Stack)
+	}
+	return sb.String()
+}
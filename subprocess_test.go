@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// _subprocessHelperEnv makes TestSubprocessHelper run as the child
+// process of TestVerifySubprocess.
+const _subprocessHelperEnv = "GOLEAK_TEST_SUBPROCESS_HELPER"
+
+func TestSubprocessHelper(t *testing.T) {
+	if os.Getenv(_subprocessHelperEnv) == "" {
+		t.Skip("only runs as the child process of TestVerifySubprocess")
+	}
+	bg := startBlockedG()
+	defer bg.unblock()
+	ReportToParent(testOptions(), IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+}
+
+func TestVerifySubprocess(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("js can't start processes")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSubprocessHelper$")
+	cmd.Env = append(os.Environ(), _subprocessHelperEnv+"=1")
+	ff := &fakeF{}
+	VerifySubprocess(ff, cmd)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "child failed:\n%s", out)
+
+	require.Len(t, ff.cleanups, 1)
+	ff.cleanups[0]()
+	require.Len(t, ff.errors, 1)
+	assert.Contains(t, ff.errors[0], "found unexpected goroutines in child process ")
+	assert.Contains(t, ff.errors[0], "-test.run=^TestSubprocessHelper$")
+	assert.Contains(t, ff.errors[0], "with github.com/projectdiscovery/goleak.(*blockedG).block on top of the stack")
+
+	ff = &fakeF{}
+	VerifySubprocess(ff, exec.Command(os.Args[0], "-test.run=^$"))
+	require.Len(t, ff.cleanups, 1)
+	ff.cleanups[0]()
+	assert.Empty(t, ff.errors, "children that don't report should not fail the test")
+}
+
+func TestVerifyTestMainReportsToParent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "child.jsonl")
+	t.Setenv("GOLEAK_CHILD_REPORT", path)
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+
+	bg := startBlockedG()
+	VerifyTestMain(dummyTestMain(0), testOptions())
+	assert.Equal(t, 1, <-exitCode)
+	assert.Contains(t, <-stderr, "goleak: Errors")
+	bg.unblock()
+
+	VerifyTestMain(dummyTestMain(0), testOptions())
+	assert.Equal(t, 0, <-exitCode)
+	assert.Empty(t, <-stderr)
+
+	reports, err := readChildReports(path)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, os.Getpid(), reports[0].PID)
+	assert.Equal(t, os.Args, reports[0].Args)
+	require.Len(t, reports[0].Leaks, 1)
+	assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", reports[0].Leaks[0].FirstFunction)
+	assert.Empty(t, reports[1].Leaks)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// _summaryEnv names the environment variable with the path of the file
+// that VerifyTestMain appends its findings to.
+const _summaryEnv = "GOLEAK_SUMMARY"
+
+// Summary is the leak summary of a test run across packages,
+// e.g. of "go test ./...", read with [ReadSummary].
+//
+// To collect it, set the GOLEAK_SUMMARY environment variable to the
+// path of a file when running the tests:
+//
+//	GOLEAK_SUMMARY=$PWD/goleak-summary.jsonl go test ./...
+//
+// Every [VerifyTestMain] then appends a JSON encoded [PackageSummary]
+// line to the file, whether or not it found leaks. Test binaries of
+// different packages may run in parallel, so appends are serialized
+// with file locks where the OS supports them. The file isn't truncated,
+// so remove it before each run. The goleak-summary command renders it
+// as an HTML or JSON artifact:
+//
+//	go run github.com/projectdiscovery/goleak/cmd/goleak-summary \
+//		-html goleak-summary.html goleak-summary.jsonl
+type Summary struct {
+	Packages []PackageSummary `json:"packages"`
+}
+
+// PackageSummary holds the findings of VerifyTestMain for the tests
+// of one package.
+type PackageSummary struct {
+	// Package is the import path of the tested package.
+	Package string    `json:"package"`
+	Time    time.Time `json:"time"`
+	// ExitCode is the exit code of the tests, before checking for leaks.
+	ExitCode int `json:"exit_code"`
+	// Checked reports whether leaks were checked for. They aren't after
+	// failed tests, unless [OverrideFailedExitCode] is given.
+	Checked bool              `json:"checked"`
+	Leaks   []LeakedGoroutine `json:"leaks,omitempty"`
+}
+
+// Leaked returns the summaries of the packages that leaked goroutines.
+func (s Summary) Leaked() []PackageSummary {
+	var leaked []PackageSummary
+	for _, pkg := range s.Packages {
+		if len(pkg.Leaks) > 0 {
+			leaked = append(leaked, pkg)
+		}
+	}
+	return leaked
+}
+
+// ReadSummary reads the summary that VerifyTestMain calls wrote to the
+// file named by GOLEAK_SUMMARY.
+func ReadSummary(path string) (Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer f.Close()
+
+	var s Summary
+	scan := bufio.NewScanner(f)
+	scan.Buffer(nil, 64<<20) // lines hold full stacks
+	for n := 1; scan.Scan(); n++ {
+		if len(strings.TrimSpace(scan.Text())) == 0 {
+			continue
+		}
+		var pkg PackageSummary
+		if err := json.Unmarshal(scan.Bytes(), &pkg); err != nil {
+			return Summary{}, fmt.Errorf("parse summary %v:%d: %w", path, n, err)
+		}
+		s.Packages = append(s.Packages, pkg)
+	}
+	if err := scan.Err(); err != nil {
+		return Summary{}, fmt.Errorf("read summary %v: %w", path, err)
+	}
+	return s, nil
+}
+
+var _summaryHTML = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>goleak summary</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+.leaked { color: #b00; }
+pre { background: #f6f6f6; padding: 0.6em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>goleak summary</h1>
+<p>{{len .Leaked}} of {{len .Packages}} packages leaked goroutines.</p>
+<table>
+<tr><th>Package</th><th>Result</th></tr>
+{{- range .Packages}}
+<tr><td>{{.Package}}</td><td
+{{- if .Leaks}} class="leaked">{{len .Leaks}} leaked goroutines
+{{- else if .Checked}}>no leaks
+{{- else}}>not checked, tests exited with {{.ExitCode}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- range .Leaked}}
+<h2>{{.Package}}</h2>
+{{- range .Leaks}}
+<h3>goroutine {{.ID}} [{{.State}}]: {{.FirstFunction}}</h3>
+{{- if .Owner}}
+<p>Owner: {{.Owner}}</p>
+{{- end}}
+{{- if .Links}}
+<ul>
+{{- range .Links}}
+<li><a href="{{.URL}}">{{.Function}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+<pre>{{.Stack}}</pre>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the summary as an HTML page.
+func (s Summary) WriteHTML(w io.Writer) error {
+	return _summaryHTML.Execute(w, s)
+}
+
+// summaryPackage returns the import path of the package under test.
+func summaryPackage() string {
+	if info := _buildInfo(); info != nil && info.Path != "" {
+		// Test binaries are named after the package, e.g. "foo/bar.test".
+		return strings.TrimSuffix(info.Path, ".test")
+	}
+	return os.Args[0]
+}
+
+// recordSummary prepares VerifyTestMain to append its findings to the
+// summary file named by GOLEAK_SUMMARY, if it's set. It returns
+// a function to call with the exit code of the tests and whether
+// leaks were checked for, once the check is done.
+func (o *opts) recordSummary() func(exitCode int, checked bool) {
+	path := os.Getenv(_summaryEnv)
+	if path == "" {
+		return func(int, bool) {}
+	}
+
+	var leaks []LeakedGoroutine
+	onLeakReport := o.onLeakReport
+	o.onLeakReport = func(r Report) {
+		leaks = r.Leaks
+		if onLeakReport != nil {
+			onLeakReport(r)
+		}
+	}
+	return func(exitCode int, checked bool) {
+		pkg := PackageSummary{
+			Package:  summaryPackage(),
+			Time:     time.Now(),
+			ExitCode: exitCode,
+			Checked:  checked,
+			Leaks:    leaks,
+		}
+		if err := appendJSONLine(path, pkg); err != nil {
+			fmt.Fprintf(_osStderr, "goleak: write summary: %v\n", err)
+		}
+	}
+}
+
+// appendJSONLine appends v, JSON encoded, to the file at path as
+// a single line, holding a lock on the file if possible.
+func appendJSONLine(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("lock %v: %w", path, err)
+	}
+	_, err = f.Write(append(b, '\n'))
+	unlock()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
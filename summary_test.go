@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyTestMainSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.jsonl")
+	t.Setenv("GOLEAK_SUMMARY", path)
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+
+	blocked := startBlockedG()
+	VerifyTestMain(dummyTestMain(0), testOptions())
+	assert.Equal(t, 1, <-exitCode)
+	assert.Contains(t, <-stderr, "goleak: Errors")
+
+	VerifyTestMain(dummyTestMain(2), testOptions())
+	assert.Equal(t, 2, <-exitCode)
+	<-stderr
+
+	blocked.unblock()
+	VerifyTestMain(dummyTestMain(0), testOptions())
+	assert.Equal(t, 0, <-exitCode)
+	assert.Empty(t, <-stderr)
+
+	summary, err := ReadSummary(path)
+	require.NoError(t, err)
+	require.Len(t, summary.Packages, 3)
+	for _, pkg := range summary.Packages {
+		assert.Equal(t, "github.com/projectdiscovery/goleak", pkg.Package)
+		assert.False(t, pkg.Time.IsZero())
+	}
+
+	leaked := summary.Packages[0]
+	assert.True(t, leaked.Checked)
+	assert.Equal(t, 1, leaked.ExitCode, "tests that passed but leaked should be recorded with the exit code of the leak")
+	require.Len(t, leaked.Leaks, 1)
+	assert.Equal(t, "github.com/projectdiscovery/goleak.(*blockedG).block", leaked.Leaks[0].FirstFunction)
+
+	assert.False(t, summary.Packages[1].Checked, "failed tests should not be checked")
+	assert.Equal(t, 2, summary.Packages[1].ExitCode)
+	assert.True(t, summary.Packages[2].Checked)
+	assert.Empty(t, summary.Packages[2].Leaks)
+	assert.Equal(t, []PackageSummary{leaked}, summary.Leaked())
+
+	var html strings.Builder
+	require.NoError(t, summary.WriteHTML(&html))
+	assert.Contains(t, html.String(), "1 of 3 packages leaked goroutines.")
+	assert.Contains(t, html.String(), "goleak.(*blockedG).block")
+	assert.Contains(t, html.String(), "not checked, tests exited with 2")
+}
+
+func TestVerifyTestMainSummaryLeakExitCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.jsonl")
+	t.Setenv("GOLEAK_SUMMARY", path)
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+
+	blocked := startBlockedG()
+	defer blocked.unblock()
+	VerifyTestMain(dummyTestMain(0), testOptions(), ExitCodeOnLeak(3))
+	assert.Equal(t, 3, <-exitCode)
+	<-stderr
+
+	summary, err := ReadSummary(path)
+	require.NoError(t, err)
+	require.Len(t, summary.Packages, 1)
+	assert.Equal(t, 3, summary.Packages[0].ExitCode, "the summary should have the exit code set for the leak")
+	assert.NotEmpty(t, summary.Packages[0].Leaks)
+}
+
+func TestAppendSummaryConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.jsonl")
+	stack := strings.Repeat("main.worker()\n\t/app/main.go:42 +0x25\n", 1000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, appendJSONLine(path, PackageSummary{
+				Package: "example.com/pkg",
+				Checked: true,
+				Leaks:   []LeakedGoroutine{{ID: i, Stack: stack}},
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	summary, err := ReadSummary(path)
+	require.NoError(t, err)
+	assert.Len(t, summary.Packages, 20)
+	assert.Len(t, summary.Leaked(), 20)
+}
+
+func TestReadSummaryErrors(t *testing.T) {
+	_, err := ReadSummary(filepath.Join(t.TempDir(), "missing.jsonl"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "summary.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"package\":\"a\"}\n\nnot json\n"), 0o644))
+	_, err = ReadSummary(path)
+	assert.ErrorContains(t, err, "summary.jsonl:3")
+}
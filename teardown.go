@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Functions of package testing that run test cleanups.
+const (
+	_runCleanup = "testing.(*common).runCleanup"
+	_tRunner    = "testing.tRunner"
+)
+
+// DiagnoseCleanupOrder annotates leaks found by Find, VerifyNone and the
+// like with hints about the order in which the test tears down, since
+// goroutines that are stopped by cleanups that haven't run yet are
+// reported like goroutines that are never stopped. The hints say:
+//
+//   - if the check runs in a t.Cleanup function, that the cleanup
+//     functions registered before it run after it;
+//   - if the check runs in the test function, e.g. deferred, that the
+//     cleanup functions of the test run after it;
+//   - which other goroutines are running cleanup functions of tests,
+//     whose leaked goroutines may be stopped once they finish.
+//
+// Leaks that go away when the check runs last, e.g. with [Check] or
+// a t.Cleanup registered at the start of the test, are teardown
+// ordering issues rather than missing calls to Close.
+func DiagnoseCleanupOrder() Option {
+	return optionFunc(func(opts *opts) {
+		opts.diagnoseCleanupOrder = true
+	})
+}
+
+// teardownNotes returns hints about the teardown of the test,
+// if DiagnoseCleanupOrder was given.
+func (o *opts) teardownNotes() string {
+	if !o.diagnoseCleanupOrder {
+		return ""
+	}
+	return teardownHints(stack.Current(), stack.All())
+}
+
+// teardownHints describes how the check running on cur is ordered with
+// the cleanup functions of tests, and which of the goroutines in all
+// are running cleanup functions.
+func teardownHints(cur stack.Stack, all []stack.Stack) string {
+	var sb strings.Builder
+	header := func() {
+		if sb.Len() == 0 {
+			sb.WriteString("\nteardown hints:\n")
+		}
+	}
+	switch {
+	case cur.HasFunction(_runCleanup):
+		header()
+		sb.WriteString("the leak check runs in a t.Cleanup function. Cleanup functions run last in, first out, " +
+			"so those registered before the check haven't run yet. If they stop the leaked goroutines, " +
+			"this is a teardown ordering issue: register the check first, e.g. with goleak.Check at the start of the test.\n")
+	case cur.HasFunction(_tRunner):
+		header()
+		sb.WriteString("the leak check runs in the test function, before the t.Cleanup functions of the test. " +
+			"If they stop the leaked goroutines, this is a teardown ordering issue: check in a t.Cleanup " +
+			"registered at the start of the test instead, e.g. with goleak.Check.\n")
+	}
+
+	var running []int
+	for _, s := range all {
+		if s.ID() != cur.ID() && s.HasFunction(_runCleanup) {
+			running = append(running, s.ID())
+		}
+	}
+	if len(running) > 0 {
+		header()
+		fmt.Fprintf(&sb, "%v still running cleanup functions of tests; the leaked goroutines may be stopped once they finish.\n",
+			goroutineList(running)+isOrAre(len(running)))
+	}
+	return sb.String()
+}
+
+// isOrAre returns " is" for a single subject and " are" for more.
+func isOrAre(n int) string {
+	if n == 1 {
+		return " is"
+	}
+	return " are"
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnoseCleanupOrder(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+
+	t.Run("test function", func(t *testing.T) {
+		err := Find(testOptions(), include, DiagnoseCleanupOrder())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "\nteardown hints:\nthe leak check runs in the test function, before the t.Cleanup functions")
+		assert.Equal(t, 1, strings.Count(err.Error(), "teardown hints"))
+	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		var err error
+		t.Run("leaky", func(t *testing.T) {
+			t.Cleanup(func() { err = Find(testOptions(), include, DiagnoseCleanupOrder()) })
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "\nteardown hints:\nthe leak check runs in a t.Cleanup function.")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		err := Find(testOptions(), include)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "teardown hints")
+	})
+}
+
+func TestTeardownHints(t *testing.T) {
+	stacks, err := stack.ParseDump([]byte(strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.main()",
+		"	/app/main.go:10 +0x1",
+		"",
+		"goroutine 5 [chan receive]:",
+		"example.com/srv.(*Server).Close()",
+		"	/app/srv/srv.go:42 +0x25",
+		"example.com/srv.TestServe.func1()",
+		"	/app/srv/srv_test.go:20 +0x25",
+		"testing.(*common).Cleanup.func1()",
+		"	/usr/local/go/src/testing/testing.go:1175 +0x10f",
+		"testing.(*common).runCleanup(0xc000103040, 0x0)",
+		"	/usr/local/go/src/testing/testing.go:1353 +0xf0",
+		"testing.tRunner.func2()",
+		"	/usr/local/go/src/testing/testing.go:1684 +0x25",
+		"testing.tRunner(0xc000103040, 0x5c6a38)",
+		"	/usr/local/go/src/testing/testing.go:1695 +0x107",
+		"created by testing.(*T).Run in goroutine 1",
+		"	/usr/local/go/src/testing/testing.go:1742 +0x390",
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+	main, cleanup := stacks[0], stacks[1]
+
+	assert.Equal(t, "\nteardown hints:\n"+
+		"goroutine 5 is still running cleanup functions of tests; the leaked goroutines may be stopped once they finish.\n",
+		teardownHints(main, stacks))
+	assert.Empty(t, teardownHints(main, stacks[:1]))
+	assert.Contains(t, teardownHints(cleanup, stacks), "the leak check runs in a t.Cleanup function.",
+		"the checking goroutine should not be listed as running cleanups")
+	assert.NotContains(t, teardownHints(cleanup, stacks), "still running")
+}
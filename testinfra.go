@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// TestInfraProfile names the built-in profile that ignores the goroutines
+// of test infrastructure, with the options of [IgnoreTestMainGoroutines].
+// It can be selected for all leak checks of a test run with:
+//
+//	GOLEAK_PROFILE=testinfra go test ./...
+//
+// Registering another profile with this name replaces it.
+const TestInfraProfile = "testinfra"
+
+func init() {
+	Profile(TestInfraProfile, IgnoreTestMainGoroutines())
+}
+
+// Test infrastructure whose goroutines IgnoreTestMainGoroutines ignores,
+// by the packages that run or start them.
+var _testInfra = []struct {
+	name string
+	pkgs []string
+}{
+	{"httptest", []string{"net/http/httptest"}},
+	{"testcontainers-go", []string{"github.com/testcontainers/testcontainers-go"}},
+	{"dockertest", []string{"github.com/ory/dockertest"}},
+}
+
+// IgnoreTestMainGoroutines ignores the goroutines of test infrastructure
+// that integration test suites commonly share between tests, e.g. when
+// it's set up in TestMain, instead of an ignore per goroutine:
+//
+//   - servers started with net/http/httptest
+//   - containers, log producers and the Ryuk reaper of
+//     github.com/testcontainers/testcontainers-go
+//   - pools and resources of github.com/ory/dockertest
+//
+// A goroutine is ignored if any frame of its stack, or the function that
+// created it, is in a package of these libraries. Goroutines that they
+// start indirectly, such as the connections that an httptest server
+// accepts, are not ignored, since they are leaked if the test doesn't
+// close them. The same options are registered as the [TestInfraProfile].
+func IgnoreTestMainGoroutines() Option {
+	options := make(Options, 0, len(_testInfra))
+	for _, infra := range _testInfra {
+		pkgs := infra.pkgs
+		options = append(options, addFilter(
+			"IgnoreTestMainGoroutines("+infra.name+")",
+			func(s stack.Stack) bool { return isInPackages(s, pkgs) },
+		))
+	}
+	return options
+}
+
+// isInPackages reports whether any function in s, or the function
+// that created s, is in one of pkgs or in their subpackages.
+func isInPackages(s stack.Stack, pkgs []string) bool {
+	candidate := false
+	for _, pkg := range pkgs {
+		if strings.Contains(s.Full(), pkg) {
+			candidate = true
+			break
+		}
+	}
+	if !candidate {
+		// Avoid parsing the frames of stacks that can't match.
+		return false
+	}
+
+	in := func(name string) bool {
+		for _, pkg := range pkgs {
+			rest, ok := strings.CutPrefix(name, pkg)
+			if ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+				return true
+			}
+		}
+		return false
+	}
+	return in(s.CreatedBy()) || s.HasFunctionFunc(in)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreTestMainGoroutines(t *testing.T) {
+	t.Run("httptest server", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		require.Error(t, Find(testOptions()))
+		assert.NoError(t, Find(testOptions(), IgnoreTestMainGoroutines()))
+		assert.NoError(t, Find(testOptions(), UseProfile(TestInfraProfile)))
+	})
+
+	dump := []byte(`goroutine 10 [select]:
+github.com/testcontainers/testcontainers-go.(*Reaper).connect.func1()
+	/go/pkg/mod/github.com/testcontainers/testcontainers-go@v0.31.0/reaper.go:380 +0x1a5
+created by github.com/testcontainers/testcontainers-go.(*Reaper).connect in goroutine 1
+	/go/pkg/mod/github.com/testcontainers/testcontainers-go@v0.31.0/reaper.go:370 +0x1d9
+
+goroutine 11 [IO wait]:
+internal/poll.(*pollDesc).waitRead(...)
+	/usr/local/go/src/internal/poll/fd_poll_runtime.go:89
+net.(*conn).Read(0xc000124000, {0xc000200000, 0x1000, 0x1000})
+	/usr/local/go/src/net/net.go:179 +0x45
+created by github.com/ory/dockertest/v3.(*Pool).Retry in goroutine 1
+	/go/pkg/mod/github.com/ory/dockertest/v3@v3.10.0/dockertest.go:500 +0x85
+
+goroutine 12 [chan receive]:
+github.com/testcontainers/testcontainers-gofork.worker()
+	/app/worker.go:10 +0x25
+created by main.main in goroutine 1
+	/app/main.go:5 +0x25
+
+goroutine 13 [IO wait]:
+net/http.(*conn).serve(0xc000130000, {0x6b3f20, 0xc000012345})
+	/usr/local/go/src/net/http/server.go:2000 +0x5c5
+created by net/http.(*Server).Serve in goroutine 9
+	/usr/local/go/src/net/http/server.go:3000 +0x485
+`)
+	err := FindInDump(dump, IgnoreTestMainGoroutines())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "Goroutine 10 ", "frame in testcontainers-go")
+	assert.NotContains(t, err.Error(), "Goroutine 11 ", "created by dockertest")
+	assert.Contains(t, err.Error(), "Goroutine 12 ", "other module with the same prefix")
+	assert.Contains(t, err.Error(), "Goroutine 13 ", "connection of a server")
+}
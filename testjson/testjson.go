@@ -0,0 +1,233 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package testjson finds the tests that leak goroutines in the output
+// of "go test -json", to help large repositories find their leakiest
+// tests.
+//
+// It correlates the leak failures that goleak reports through
+// t.Error, e.g. with [goleak.VerifyNone], with the tests that reported
+// them and their durations. Leaks found by [goleak.VerifyTestMain] are
+// attributed to a "TestMain" pseudo-test of the package, and may be
+// merged from a [goleak.Summary] with [AddSummary]:
+//
+//	out, _ := os.Open("test.json") // go test -json ./... > test.json
+//	results, err := testjson.Analyze(out)
+//	if err != nil {
+//		return err
+//	}
+//	testjson.WriteTable(os.Stdout, results)
+//
+// The goleak-tests command does the same for the output of go test
+// piped to it.
+package testjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// TestMain is the name of the pseudo-test that leaks found by
+// VerifyTestMain are attributed to.
+const TestMain = "TestMain"
+
+// Event is an event of "go test -json"; see "go doc test2json".
+type Event struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // seconds
+	Output  string
+}
+
+// Result is the leaks reported by a test.
+type Result struct {
+	Package string `json:"package"`
+	// Test is the name of the test, or TestMain
+	// for leaks found by VerifyTestMain.
+	Test string `json:"test"`
+	// Elapsed is how long the test ran; for TestMain, how long the
+	// tests of the package ran.
+	Elapsed time.Duration `json:"elapsed"`
+	// Reports counts the leak failures the test reported,
+	// e.g. more than one if it checked for leaks more than once.
+	Reports int `json:"reports"`
+	// Goroutines counts the leaked goroutines of all reports.
+	Goroutines int `json:"goroutines"`
+	// Functions counts the leaked goroutines by the function
+	// on top of their stacks.
+	Functions map[string]int `json:"functions,omitempty"`
+}
+
+var (
+	_reportRE    = regexp.MustCompile(`found unexpected goroutines`)
+	_goroutineRE = regexp.MustCompile(`Goroutine \d+ in state [^,]+, with (\S+) on top of the stack:`)
+)
+
+type resultKey struct{ pkg, test string }
+
+// Analyze reads the events of "go test -json" from r and returns the
+// tests that reported leaks, leakiest first. Lines that aren't events,
+// such as build errors, are skipped.
+func Analyze(r io.Reader) ([]Result, error) {
+	var (
+		results = make(map[resultKey]*Result)
+		elapsed = make(map[resultKey]time.Duration)
+		scan    = bufio.NewScanner(r)
+	)
+	scan.Buffer(nil, 16<<20)
+	for scan.Scan() {
+		line := scan.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+
+		key := resultKey{e.Package, e.Test}
+		if e.Test == "" {
+			key.test = TestMain
+		}
+		switch e.Action {
+		case "pass", "fail", "skip":
+			elapsed[key] = time.Duration(e.Elapsed * float64(time.Second))
+		case "output":
+			res := results[key]
+			if _reportRE.MatchString(e.Output) {
+				if res == nil {
+					res = &Result{Package: key.pkg, Test: key.test}
+					results[key] = res
+				}
+				res.Reports++
+			}
+			if res == nil {
+				continue
+			}
+			for _, m := range _goroutineRE.FindAllStringSubmatch(e.Output, -1) {
+				res.Goroutines++
+				if res.Functions == nil {
+					res.Functions = make(map[string]int)
+				}
+				res.Functions[m[1]]++
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, fmt.Errorf("read go test output: %w", err)
+	}
+
+	sorted := make([]Result, 0, len(results))
+	for key, res := range results {
+		res.Elapsed = elapsed[key]
+		sorted = append(sorted, *res)
+	}
+	sortResults(sorted)
+	return sorted, nil
+}
+
+// AddSummary merges the leaks found by VerifyTestMain in the summary
+// into results, as TestMain results, and returns them sorted again.
+// TestMain results that were found in the test output are replaced,
+// since the summary has the leaked goroutines of all of them.
+func AddSummary(results []Result, summary goleak.Summary) []Result {
+	index := make(map[resultKey]int)
+	for i, res := range results {
+		index[resultKey{res.Package, res.Test}] = i
+	}
+	for _, pkg := range summary.Leaked() {
+		key := resultKey{pkg.Package, TestMain}
+		res := Result{
+			Package:    pkg.Package,
+			Test:       TestMain,
+			Reports:    1,
+			Goroutines: len(pkg.Leaks),
+			Functions:  make(map[string]int),
+		}
+		for _, leak := range pkg.Leaks {
+			res.Functions[leak.FirstFunction]++
+		}
+		if i, ok := index[key]; ok {
+			res.Elapsed = results[i].Elapsed
+			res.Reports = max(res.Reports, results[i].Reports)
+			results[i] = res
+			continue
+		}
+		index[key] = len(results)
+		results = append(results, res)
+	}
+	sortResults(results)
+	return results
+}
+
+// sortResults sorts results by the number of leaked goroutines,
+// most first, then by package and test.
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Goroutines != b.Goroutines {
+			return a.Goroutines > b.Goroutines
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Test < b.Test
+	})
+}
+
+// WriteTable writes results as a table with a row per test, e.g.:
+//
+//	PACKAGE         TEST       GOROUTINES  REPORTS  ELAPSED  TOP FUNCTION
+//	example.com/db  TestQuery  3           1        1.2s     example.com/db.(*Pool).reaper (2)
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tTEST\tGOROUTINES\tREPORTS\tELAPSED\tTOP FUNCTION")
+	for _, res := range results {
+		fmt.Fprintf(tw, "%v\t%v\t%d\t%d\t%v\t%v\n",
+			res.Package, res.Test, res.Goroutines, res.Reports,
+			res.Elapsed.Round(time.Millisecond), topFunction(res.Functions))
+	}
+	return tw.Flush()
+}
+
+// topFunction returns the function that most leaked goroutines
+// were in, with their count, e.g. "main.worker (3)".
+func topFunction(functions map[string]int) string {
+	var top string
+	for f, n := range functions {
+		if n > functions[top] || (n == functions[top] && f < top) {
+			top = f
+		}
+	}
+	if top == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%v (%d)", top, functions[top])
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package testjson
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyzeFixture(t *testing.T) []Result {
+	f, err := os.Open(filepath.Join("testdata", "leaks.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	results, err := Analyze(f)
+	require.NoError(t, err)
+	return results
+}
+
+func TestAnalyze(t *testing.T) {
+	results := analyzeFixture(t)
+	assert.Equal(t, []Result{
+		{
+			Package:    "example.com/tj",
+			Test:       "TestLeak",
+			Elapsed:    460 * time.Millisecond,
+			Reports:    1,
+			Goroutines: 2,
+			Functions:  map[string]int{"time.Sleep": 2},
+		},
+		{
+			Package:    "example.com/tj",
+			Test:       "TestSub/leaky",
+			Reports:    1,
+			Goroutines: 1,
+			Functions:  map[string]int{"example.com/tj.TestSub.func1.1": 1},
+		},
+		{
+			Package:    "example.com/tj/mainleak",
+			Test:       TestMain,
+			Elapsed:    5 * time.Millisecond,
+			Reports:    1,
+			Goroutines: 1,
+			Functions:  map[string]int{"example.com/tj/mainleak.TestStart.func1": 1},
+		},
+	}, results)
+}
+
+func TestAnalyzeSkipsNonEvents(t *testing.T) {
+	results, err := Analyze(strings.NewReader(strings.Join([]string{
+		"# example.com/broken",
+		"./broken.go:3:1: syntax error",
+		`{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"    a_test.go:9: found unexpected goroutines:\n"}`,
+		`{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"        [Goroutine 7 in state chan receive, with example.com/a.worker on top of the stack:\n"}`,
+		`{not json`,
+		`{"Action":"fail","Package":"example.com/a","Test":"TestA","Elapsed":1.5}`,
+	}, "\n")))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{
+		Package:    "example.com/a",
+		Test:       "TestA",
+		Elapsed:    1500 * time.Millisecond,
+		Reports:    1,
+		Goroutines: 1,
+		Functions:  map[string]int{"example.com/a.worker": 1},
+	}}, results)
+}
+
+func TestAddSummary(t *testing.T) {
+	results := AddSummary(analyzeFixture(t), goleak.Summary{Packages: []goleak.PackageSummary{
+		{Package: "example.com/tj/clean", Checked: true},
+		{Package: "example.com/tj/mainleak", Checked: true, Leaks: []goleak.LeakedGoroutine{
+			{FirstFunction: "example.com/tj/mainleak.TestStart.func1"},
+			{FirstFunction: "example.com/tj/mainleak.TestStart.func1"},
+			{FirstFunction: "example.com/tj/mainleak.other"},
+		}},
+		{Package: "example.com/tj/other", Checked: true, Leaks: []goleak.LeakedGoroutine{
+			{FirstFunction: "example.com/tj/other.worker"},
+		}},
+	}})
+
+	require.Len(t, results, 4)
+	assert.Equal(t, Result{
+		Package:    "example.com/tj/mainleak",
+		Test:       TestMain,
+		Elapsed:    5 * time.Millisecond,
+		Reports:    1,
+		Goroutines: 3,
+		Functions: map[string]int{
+			"example.com/tj/mainleak.TestStart.func1": 2,
+			"example.com/tj/mainleak.other":           1,
+		},
+	}, results[0], "the summary should replace the TestMain result")
+	assert.Equal(t, "TestLeak", results[1].Test)
+	assert.Equal(t, "example.com/tj/other", results[3].Package, "packages only in the summary should be added")
+}
+
+func TestWriteTable(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteTable(&sb, analyzeFixture(t)))
+	assert.Equal(t, `PACKAGE                  TEST           GOROUTINES  REPORTS  ELAPSED  TOP FUNCTION
+example.com/tj           TestLeak       2           1        460ms    time.Sleep (2)
+example.com/tj           TestSub/leaky  1           1        0s       example.com/tj.TestSub.func1.1 (1)
+example.com/tj/mainleak  TestMain       1           1        5ms      example.com/tj/mainleak.TestStart.func1 (1)
+`, sb.String())
+}
@@ -21,6 +21,7 @@
 package goleak
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -49,9 +50,20 @@ type TestingM interface {
 //
 // This will run all tests as per normal, and if they were successful, look
 // for any goroutine leaks and fail the tests if any leaks were found.
+// The exit code is then 1, or the code given with [ExitCodeOnLeak].
+// Failed test runs aren't checked unless [OverrideFailedExitCode] is given.
+// If the GOLEAK_SUMMARY environment variable is set, the findings are
+// also appended to a summary of all packages; see [Summary]. Test
+// binaries started with [VerifySubprocess] report to the parent test.
+// If the FailWith option is given, leaks are passed to its function instead,
+// and the exit code is left unchanged. The same goes for [ReportOnly].
+// With [DumpOnTimeout], tests that time out or are killed leave goleak's
+// view of the goroutines behind.
 func VerifyTestMain(m TestingM, options ...Option) {
-	exitCode := m.Run()
 	opts := buildOpts(options...)
+	stopTimeoutDump := opts.startTimeoutDump()
+	exitCode := m.Run()
+	stopTimeoutDump()
 
 	var cleanup func(int)
 	cleanup, opts.cleanup = opts.cleanup, nil
@@ -60,16 +72,56 @@ func VerifyTestMain(m TestingM, options ...Option) {
 	}
 	defer func() { cleanup(exitCode) }()
 
-	if exitCode == 0 {
-		var err error
-		if opts.pretty {
-			err = FindAndPrettyPrint(opts)
-		} else {
-			err = Find(opts)
-		}
-		if err != nil {
-			fmt.Fprintf(_osStderr, "goleak: Errors on successful test run: %v\n", err)
-			exitCode = 1
+	record := opts.recordSummary()
+	if exitCode != 0 && !opts.overrideFailedExitCode {
+		record(exitCode, false)
+		return
+	}
+	// The leak check below may change the exit code.
+	defer func() { record(exitCode, true) }()
+	reportChild := opts.recordChildReport()
+	defer reportChild()
+	err := find(opts)
+	if opts.failOnUnusedFilters {
+		err = errors.Join(err, unusedFiltersError())
+	}
+	if err != nil {
+		switch {
+		case opts.reportOnly:
+			opts.reportLeaks(nil, err)
+		case opts.failWith != nil:
+			opts.failWith(err)
+		default:
+			run := "successful"
+			if exitCode != 0 {
+				run = "failed"
+			}
+			fmt.Fprintf(_osStderr, "goleak: Errors on %v test run: %v\n", run, err)
+			exitCode = opts.leakExitCode
 		}
 	}
 }
+
+// ExitCodeOnLeak sets the exit code that [VerifyTestMain] exits with
+// if it finds leaks after the tests passed. It's 1 by default,
+// like failed tests; a distinct code lets CI tell
+// "tests failed" apart from "tests passed but leaked".
+// The code must be positive; use [ReportOnly] to not fail on leaks.
+func ExitCodeOnLeak(code int) Option {
+	if code <= 0 {
+		invalidOption("ExitCodeOnLeak", "exit code %d would not fail the run", code)
+	}
+	return optionFunc(func(opts *opts) {
+		opts.leakExitCode = code
+	})
+}
+
+// OverrideFailedExitCode makes [VerifyTestMain] check for leaks even if
+// the tests failed, and replace their exit code with the one given by
+// [ExitCodeOnLeak] if it finds any. By default, leaks aren't checked
+// after failed tests, since failed tests often leave goroutines behind.
+func OverrideFailedExitCode() Option {
+	return optionFunc(func(opts *opts) {
+		opts.overrideFailedExitCode = true
+	})
+}
@@ -22,9 +22,12 @@ package goleak
 
 import (
 	"bytes"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
@@ -84,4 +87,89 @@ func TestVerifyTestMain(t *testing.T) {
 	}))
 	assert.True(t, cleanupCalled)
 	assert.Equal(t, 3, cleanupExitcode)
+
+	var exitCodes []int
+	record := Cleanup(func(ec int) { exitCodes = append(exitCodes, ec) })
+	VerifyTestMain(dummyTestMain(4), record, record)
+	assert.Equal(t, []int{4, 4}, exitCodes, "Every cleanup should get the exit code")
+}
+
+func TestVerifyTestMainFailWith(t *testing.T) {
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+
+	blocked := startBlockedG()
+	defer blocked.unblock()
+
+	var got error
+	VerifyTestMain(dummyTestMain(0), testOptions(), FailWith(func(err error) { got = err }))
+	assert.Equal(t, 0, <-exitCode, "Exit code should not be modified with FailWith")
+	assert.Empty(t, <-stderr, "Nothing should be printed with FailWith")
+	assert.ErrorContains(t, got, "blockedG")
+}
+
+func TestVerifyTestMainBuildsOptionsOnce(t *testing.T) {
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+	stubTimeoutDeadline(t, time.Now().Add(time.Hour))
+
+	var applied int
+	count := optionFunc(func(*opts) { applied++ })
+	VerifyTestMain(dummyTestMain(0), testOptions(), count,
+		DumpOnTimeout(filepath.Join(t.TempDir(), "timeout.txt")))
+	assert.Equal(t, 0, <-exitCode)
+	assert.Empty(t, <-stderr)
+	assert.Equal(t, 1, applied, "options should be applied once")
+}
+
+func TestVerifyTestMainExitCode(t *testing.T) {
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+
+	blocked := startBlockedG()
+	defer blocked.unblock()
+
+	VerifyTestMain(dummyTestMain(0), testOptions(), ExitCodeOnLeak(3))
+	assert.Equal(t, 3, <-exitCode, "Expect the leak exit code on successful runs")
+	assert.Contains(t, <-stderr, "goleak: Errors on successful test run")
+
+	VerifyTestMain(dummyTestMain(1), testOptions(), ExitCodeOnLeak(3))
+	assert.Equal(t, 1, <-exitCode, "Failed runs should keep their exit code by default")
+	assert.Empty(t, <-stderr)
+
+	VerifyTestMain(dummyTestMain(1), testOptions(), ExitCodeOnLeak(3), OverrideFailedExitCode())
+	assert.Equal(t, 3, <-exitCode, "Leaks should override the exit code of failed runs")
+	assert.Contains(t, <-stderr, "goleak: Errors on failed test run")
+
+	VerifyTestMain(dummyTestMain(1), testOptions(), OverrideFailedExitCode(),
+		IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	assert.Equal(t, 1, <-exitCode, "Failed runs without leaks should keep their exit code")
+	assert.Empty(t, <-stderr)
+
+	_, err := CheckedOption(func() Option { return ExitCodeOnLeak(0) })
+	assert.ErrorContains(t, err, "would not fail the run")
+}
+
+func TestVerifyTestMainFailOnUnusedFilters(t *testing.T) {
+	defer clearOSStubs()
+	exitCode, stderr := osStubs()
+	resetFilterUsage(t)
+
+	blocked := startBlockedG()
+	defer blocked.unblock()
+	ignoreBlocked := IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+
+	VerifyTestMain(dummyTestMain(0), testOptions(), ignoreBlocked, FailOnUnusedFilters())
+	assert.Equal(t, 0, <-exitCode, "All filters were used")
+	assert.Empty(t, <-stderr)
+
+	require.NoError(t, Find(testOptions(), ignoreBlocked, IgnoreTopFunction("example.com/unused.worker")))
+	VerifyTestMain(dummyTestMain(0), testOptions(), ignoreBlocked, FailOnUnusedFilters(), ExitCodeOnLeak(3))
+	assert.Equal(t, 3, <-exitCode, "Unused filters should fail the run")
+	assert.Contains(t, <-stderr, `matched none during the run:
+  IgnoreTopFunction("example.com/unused.worker")`)
+
+	VerifyTestMain(dummyTestMain(0), testOptions(), ignoreBlocked)
+	assert.Equal(t, 0, <-exitCode, "Unused filters are only reported with FailOnUnusedFilters")
+	assert.Empty(t, <-stderr)
 }
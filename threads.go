@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import "fmt"
+
+// _threadCount is replaced in tests.
+var _threadCount = threadCount
+
+// AllowedThreadGrowth makes [VerifyNoThreadGrowth] accept up to n
+// more OS threads at the end of a test than at its start.
+// The runtime keeps idle threads around once it has created them,
+// e.g. for blocking system calls, so tests that start many such calls
+// may need some slack.
+func AllowedThreadGrowth(n int) Option {
+	checkNotNegative("AllowedThreadGrowth", n)
+	return optionFunc(func(opts *opts) {
+		opts.allowedThreadGrowth = n
+	})
+}
+
+// VerifyNoThreadGrowth marks the given TestingF as failed if the process
+// has more OS threads at the end of the test than when
+// VerifyNoThreadGrowth was called. Call it at the start of the test:
+//
+//	func TestFoo(t *testing.T) {
+//		goleak.VerifyNoThreadGrowth(t)
+//		// ...
+//	}
+//
+// This catches threads that goroutines don't show, such as those
+// leaked by cgo code, or by goroutines that called runtime.LockOSThread
+// and are blocked forever. Like [VerifyNone], the check is retried to
+// give threads time to exit; it's affected by the same options.
+// Use [AllowedThreadGrowth] to tolerate threads the runtime keeps.
+func VerifyNoThreadGrowth(t TestingF, options ...Option) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	opts := buildOpts(options...)
+	opts.capRetries(t)
+	before := _threadCount()
+	t.Cleanup(func() {
+		var after int
+		for i := 0; ; i++ {
+			after = _threadCount()
+			if after-before <= opts.allowedThreadGrowth || !opts.retry(i) {
+				break
+			}
+		}
+		if growth := after - before; growth > opts.allowedThreadGrowth {
+			opts.fail(t, fmt.Errorf("found %d new OS threads: %d at the start of the test, %d at the end",
+				growth, before, after))
+		}
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"runtime/pprof"
+	"strconv"
+)
+
+// threadCount returns the number of OS threads of the process,
+// which Linux and Android report in /proc. If it can't be read,
+// e.g. in sandboxes, the number of threads the runtime ever created
+// is used, since Go rarely terminates threads.
+func threadCount() int {
+	if b, err := os.ReadFile("/proc/self/status"); err == nil {
+		scan := bufio.NewScanner(bytes.NewReader(b))
+		for scan.Scan() {
+			if rest, ok := bytes.CutPrefix(scan.Bytes(), []byte("Threads:")); ok {
+				if n, err := strconv.Atoi(string(bytes.TrimSpace(rest))); err == nil {
+					return n
+				}
+			}
+		}
+	}
+	return pprof.Lookup("threadcreate").Count()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadCount(t *testing.T) {
+	assert.Positive(t, threadCount())
+}
+
+func TestVerifyNoThreadGrowth(t *testing.T) {
+	defer func(orig func() int) { _threadCount = orig }(_threadCount)
+
+	// run runs a test that starts with 10 threads,
+	// and then sees the given counts, the last one repeatedly.
+	run := func(counts []int, options ...Option) *fakeF {
+		_threadCount = func() int { return 10 }
+		ff := &fakeF{}
+		VerifyNoThreadGrowth(ff, append([]Option{testOptions()}, options...)...)
+
+		_threadCount = func() int {
+			n := counts[0]
+			if len(counts) > 1 {
+				counts = counts[1:]
+			}
+			return n
+		}
+		for _, f := range ff.cleanups {
+			f()
+		}
+		return ff
+	}
+
+	t.Run("no growth", func(t *testing.T) {
+		assert.Empty(t, run([]int{10}).errors)
+	})
+
+	t.Run("growth", func(t *testing.T) {
+		ff := run([]int{12})
+		require.Len(t, ff.errors, 1)
+		assert.Equal(t, "found 2 new OS threads: 10 at the start of the test, 12 at the end", ff.errors[0])
+	})
+
+	t.Run("threads exit while retrying", func(t *testing.T) {
+		assert.Empty(t, run([]int{12, 11, 10}).errors)
+	})
+
+	t.Run("allowed growth", func(t *testing.T) {
+		assert.Empty(t, run([]int{12}, AllowedThreadGrowth(2)).errors)
+		assert.Len(t, run([]int{13}, AllowedThreadGrowth(2)).errors, 1)
+	})
+}
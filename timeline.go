@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// TimelineSample is a single measurement taken by a Timeline.
+type TimelineSample struct {
+	// Elapsed is the time since the Timeline was started.
+	Elapsed time.Duration
+	// Count is the number of unexpected goroutines at that time.
+	Count int
+}
+
+// Timeline samples the number of unexpected goroutines at a fixed
+// interval while a test runs. When passed to a leak check with
+// [WithTimeline], the samples are included in the failure message,
+// which helps to find the phase of a long test that leaks.
+//
+//	tl := goleak.StartTimeline(10 * time.Millisecond)
+//	defer goleak.VerifyNone(t, goleak.WithTimeline(tl))
+type Timeline struct {
+	interval time.Duration
+	opts     *opts
+	owner    int // goroutine that started the timeline
+
+	mu      sync.Mutex
+	samples []TimelineSample
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// StartTimeline starts sampling the number of unexpected goroutines
+// every interval in the background. The given options are used
+// to filter goroutines the same way a leak check would.
+//
+// The goroutine that calls StartTimeline is not counted,
+// and the Timeline must be stopped with Stop or [WithTimeline].
+// It panics if interval isn't positive.
+func StartTimeline(interval time.Duration, options ...Option) *Timeline {
+	if interval <= 0 {
+		invalidOption("StartTimeline", "interval %v must be positive", interval)
+	}
+	tl := &Timeline{
+		interval: interval,
+		opts:     buildOpts(options...),
+		owner:    stack.Current().ID(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	started := make(chan int)
+	go tl.run(started)
+	self := <-started
+	tl.opts.filters = append(tl.opts.filters, filter{name: "timeline", match: func(s stack.Stack) bool {
+		return s.ID() == self
+	}})
+	close(started)
+	return tl
+}
+
+func (tl *Timeline) run(started chan int) {
+	defer close(tl.done)
+
+	started <- stack.Current().ID()
+	<-started // wait for the filter to be installed
+
+	budget := tl.opts.overheadBudget()
+	start := time.Now()
+	ticker := time.NewTicker(tl.interval)
+	defer ticker.Stop()
+	for {
+		if now := time.Now(); budget.allow(now) {
+			tl.sample(now.Sub(start))
+			budget.spend(now, time.Since(now))
+		}
+		select {
+		case <-tl.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (tl *Timeline) sample(elapsed time.Duration) {
+	count := len(filterStacks(tl.opts.stacks(), tl.owner, tl.opts))
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.samples = append(tl.samples, TimelineSample{Elapsed: elapsed, Count: count})
+}
+
+// Stop stops sampling and waits for the background goroutine to exit.
+// It is safe to call Stop multiple times.
+func (tl *Timeline) Stop() {
+	tl.stopOnce.Do(func() { close(tl.stop) })
+	<-tl.done
+}
+
+// Samples returns a copy of the samples recorded so far.
+func (tl *Timeline) Samples() []TimelineSample {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]TimelineSample(nil), tl.samples...)
+}
+
+// String renders the samples as a small time series,
+// marking the sample at which the count started to grow.
+func (tl *Timeline) String() string {
+	samples := tl.Samples()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "goroutine timeline (every %v):\n", tl.interval)
+	growthMarked := false
+	for _, sample := range samples {
+		fmt.Fprintf(&sb, "  +%-10v %d", sample.Elapsed.Round(time.Millisecond), sample.Count)
+		if !growthMarked && sample.Count > samples[0].Count {
+			sb.WriteString("  <- growth started")
+			growthMarked = true
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// WithTimeline attaches a Timeline to a leak check.
+// The Timeline is stopped before looking for leaks,
+// and its samples are included in the error if any leaks are found.
+func WithTimeline(tl *Timeline) Option {
+	return optionFunc(func(opts *opts) {
+		opts.timeline = tl
+	})
+}
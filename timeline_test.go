@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	t.Run("records growth", func(t *testing.T) {
+		waitForStable(t)
+		tl := StartTimeline(time.Millisecond)
+		waitForSamples(t, tl, 2)
+
+		bg := startBlockedG()
+		defer bg.unblock()
+		waitForSamples(t, tl, len(tl.Samples())+2)
+
+		err := Find(testOptions(), WithTimeline(tl))
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "goroutine timeline (every 1ms)")
+		assert.ErrorContains(t, err, "<- growth started")
+
+		samples := tl.Samples()
+		assert.Zero(t, samples[0].Count, "timeline should start without unexpected goroutines")
+		assert.Equal(t, 1, samples[len(samples)-1].Count, "timeline should end with the blocked goroutine")
+	})
+
+	t.Run("stops the sampler", func(t *testing.T) {
+		tl := StartTimeline(time.Millisecond)
+		VerifyNone(t, WithTimeline(tl))
+
+		// The sampler must be gone even without WithTimeline now.
+		VerifyNone(t)
+		tl.Stop() // no-op
+	})
+
+	t.Run("rejects non-positive intervals", func(t *testing.T) {
+		assert.PanicsWithError(t, "goleak: StartTimeline: interval 0s must be positive", func() {
+			StartTimeline(0)
+		})
+		assert.PanicsWithError(t, "goleak: StartTimeline: interval -1ms must be positive", func() {
+			StartTimeline(-time.Millisecond)
+		})
+	})
+}
+
+func waitForSamples(t *testing.T, tl *Timeline, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for len(tl.Samples()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v samples", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
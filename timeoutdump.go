@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Variables for stubbing in unit tests.
+var (
+	_timeoutDeadline = testTimeoutDeadline
+	_raiseSignal     = raiseSignal
+)
+
+// DumpOnTimeout makes [VerifyTestMain] write goleak's view of the
+// goroutines to the file at path if the test binary is about to be
+// killed while the tests run, since tests that hang on leaked
+// goroutines otherwise die with a raw dump of all goroutines:
+//
+//	func TestMain(m *testing.M) {
+//		goleak.VerifyTestMain(m, goleak.DumpOnTimeout("goleak-timeout.txt"))
+//	}
+//
+// The file lists the goroutines that the other options don't ignore,
+// like the errors of [Find], along with the goroutines of the tests
+// that are still running. It's written
+//
+//   - shortly before the -test.timeout of the binary expires, ahead of
+//     the panic of the testing package, which then happens as usual;
+//   - when the binary receives SIGTERM or SIGQUIT, which are then
+//     raised again so that the binary exits as it would without goleak.
+//
+// If the tests finish right after the file is written, the file is left
+// in place. The file isn't written if the tests finish in time.
+func DumpOnTimeout(path string) Option {
+	if path == "" {
+		invalidOption("DumpOnTimeout", "empty path")
+	}
+	return optionFunc(func(opts *opts) {
+		opts.timeoutDumpPath = path
+	})
+}
+
+// startTimeoutDump starts waiting for the test binary to time out or
+// be killed, if DumpOnTimeout was given. It returns a function that
+// stops waiting, to call once the tests finish.
+func (o *opts) startTimeoutDump() (stop func()) {
+	if o.timeoutDumpPath == "" {
+		return func() {}
+	}
+
+	// Without a deadline, the timer never fires.
+	timer := time.NewTimer(time.Duration(math.MaxInt64))
+	if deadline, ok := _timeoutDeadline(); ok {
+		timer.Reset(time.Until(deadline.Add(-_deadlineMargin)))
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGQUIT)
+
+	done := make(chan struct{})
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// The goroutine outlives the tests, so leak checks of the tests
+		// skip it like the goroutines running leak checks.
+		defer _dumps.startCheck(stack.Current().ID())()
+		close(started)
+
+		select {
+		case <-done:
+		case <-timer.C:
+			o.writeTimeoutDump("the test binary is about to time out")
+			signal.Stop(signals)
+		case sig := <-signals:
+			o.writeTimeoutDump(fmt.Sprintf("the test binary received %v", sig))
+			signal.Stop(signals)
+			_raiseSignal(sig)
+		}
+	}()
+	<-started
+	return func() {
+		timer.Stop()
+		signal.Stop(signals)
+		close(done)
+		<-stopped
+	}
+}
+
+// writeTimeoutDump writes the leaks of the test binary, which is about
+// to exit for the given reason, to the file given with DumpOnTimeout.
+func (o *opts) writeTimeoutDump(reason string) {
+	stacks := filterStacks(o.stacks(), stack.Current().ID(), o)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "goleak: %v\n", reason)
+	if len(stacks) == 0 {
+		sb.WriteString("no unexpected goroutines\n")
+	} else {
+		fmt.Fprintf(&sb, "found unexpected goroutines:\n%s%s\n", o.display(stacks), o.notes(stacks, leakCheck{}))
+	}
+	if err := os.WriteFile(o.timeoutDumpPath, []byte(sb.String()), 0o644); err != nil {
+		fmt.Fprintf(_osStderr, "goleak: write timeout dump: %v\n", err)
+	}
+}
+
+// testTimeoutDeadline returns when the test binary times out, if it has
+// a -test.timeout, counting from now. It parses the flags of the binary
+// if they haven't been yet, which testing.M.Run does otherwise.
+func testTimeoutDeadline() (time.Time, bool) {
+	f := flag.Lookup("test.timeout")
+	if f == nil {
+		return time.Time{}, false
+	}
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return time.Time{}, false
+	}
+	timeout, ok := getter.Get().(time.Duration)
+	if !ok || timeout <= 0 {
+		return time.Time{}, false
+	}
+	return time.Now().Add(timeout), true
+}
+
+// raiseSignal delivers sig to the process again, once it's no longer
+// notified, so that it has its default effect. If that's not possible,
+// the process exits like the Go runtime does on SIGQUIT.
+func raiseSignal(sig os.Signal) {
+	signal.Reset(sig)
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+}
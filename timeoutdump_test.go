@@ -0,0 +1,107 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubTimeoutDeadline(t *testing.T, deadline time.Time) {
+	t.Cleanup(func() { _timeoutDeadline = testTimeoutDeadline })
+	_timeoutDeadline = func() (time.Time, bool) { return deadline, true }
+}
+
+func TestDumpOnTimeout(t *testing.T) {
+	bg := startBlockedG()
+	defer bg.unblock()
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+
+	t.Run("about to time out", func(t *testing.T) {
+		stubTimeoutDeadline(t, time.Now().Add(_deadlineMargin+10*time.Millisecond))
+		path := filepath.Join(t.TempDir(), "timeout.txt")
+
+		stop := buildOpts(include, DumpOnTimeout(path)).startTimeoutDump()
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, 5*time.Second, time.Millisecond)
+		stop()
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "goleak: the test binary is about to time out\nfound unexpected goroutines:\n")
+		assert.Contains(t, string(b), "goleak.(*blockedG).block")
+	})
+
+	t.Run("armed", func(t *testing.T) {
+		stubTimeoutDeadline(t, time.Now().Add(time.Hour))
+		stop := buildOpts(DumpOnTimeout(filepath.Join(t.TempDir(), "timeout.txt"))).startTimeoutDump()
+		defer stop()
+
+		VerifyNone(t, IgnoreTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block"))
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		if runtime.GOOS == "windows" || runtime.GOOS == "js" || runtime.GOOS == "wasip1" {
+			t.Skip("can't signal the process on " + runtime.GOOS)
+		}
+		defer func() { _raiseSignal = raiseSignal }()
+		raised := make(chan os.Signal, 1)
+		_raiseSignal = func(sig os.Signal) { raised <- sig }
+		stubTimeoutDeadline(t, time.Now().Add(time.Hour))
+		path := filepath.Join(t.TempDir(), "timeout.txt")
+
+		stop := buildOpts(include, DumpOnTimeout(path)).startTimeoutDump()
+		defer stop()
+		p, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, p.Signal(syscall.SIGTERM))
+		select {
+		case sig := <-raised:
+			assert.Equal(t, syscall.SIGTERM, sig)
+		case <-time.After(5 * time.Second):
+			t.Fatal("signal was not raised again")
+		}
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "goleak: the test binary received terminated\nfound unexpected goroutines:\n")
+	})
+
+	t.Run("tests finish in time", func(t *testing.T) {
+		defer clearOSStubs()
+		exitCode, _ := osStubs()
+		stubTimeoutDeadline(t, time.Now().Add(time.Hour))
+		path := filepath.Join(t.TempDir(), "timeout.txt")
+
+		VerifyTestMain(dummyTestMain(0), testOptions(), DumpOnTimeout(path))
+		assert.Equal(t, 1, <-exitCode)
+		assert.NoFileExists(t, path)
+	})
+}
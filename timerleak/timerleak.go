@@ -0,0 +1,231 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package timerleak detects timers and tickers that are never stopped.
+//
+// Timers and tickers that haven't fired yet don't have goroutines,
+// so goleak can't see them. Create them with the constructors of this
+// package instead of those of the time package to have them tracked:
+//
+//	ticker := timerleak.NewTicker(time.Second)
+//	defer ticker.Stop()
+//
+// and check that all of them were stopped at the end of a test:
+//
+//	defer timerleak.VerifyNone(t)
+//
+// Timers count as stopped once they fire, so only timers that are
+// still pending need to be stopped. Tickers must always be stopped.
+package timerleak
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/goleak"
+)
+
+// _tracked holds the timers and tickers that are not stopped yet.
+var _tracked = tracker{active: make(map[*record]struct{})}
+
+type tracker struct {
+	mu     sync.Mutex
+	nextID int
+	active map[*record]struct{}
+}
+
+// record describes a tracked timer or ticker.
+type record struct {
+	id      int
+	kind    string // "timer" or "ticker"
+	d       time.Duration
+	created string // location of the constructor call
+
+	// Guarded by the tracker.
+	deadline time.Time // for timers; zero for tickers
+	fired    bool      // for AfterFunc timers
+}
+
+func (tr *tracker) add(kind string, d time.Duration) *record {
+	r := &record{kind: kind, d: d, created: caller(3)}
+	if kind == "timer" {
+		r.deadline = time.Now().Add(d)
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.nextID++
+	r.id = tr.nextID
+	tr.active[r] = struct{}{}
+	return r
+}
+
+func (tr *tracker) remove(r *record) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	delete(tr.active, r)
+}
+
+// reset starts tracking r again after Reset.
+func (tr *tracker) reset(r *record, d time.Duration) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	r.d = d
+	r.fired = false
+	if r.kind == "timer" {
+		r.deadline = time.Now().Add(d)
+	}
+	tr.active[r] = struct{}{}
+}
+
+// pending returns the timers and tickers that are neither stopped
+// nor fired, oldest first.
+func (tr *tracker) pending() []*record {
+	now := time.Now()
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	var rs []*record
+	for r := range tr.active {
+		if r.kind == "timer" && (r.fired || !now.Before(r.deadline)) {
+			// Fired timers don't need to be stopped.
+			delete(tr.active, r)
+			continue
+		}
+		rs = append(rs, r)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].id < rs[j].id })
+	return rs
+}
+
+// caller returns the location of the function skip frames up,
+// e.g. "example.com/foo.run (/home/user/foo/run.go:42)".
+func caller(skip int) string {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown location"
+	}
+	name := "unknown function"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = fn.Name()
+	}
+	return fmt.Sprintf("%v (%v:%v)", name, file, line)
+}
+
+// Timer is a [time.Timer] that is tracked until it is stopped or fires.
+type Timer struct {
+	*time.Timer
+
+	r *record
+}
+
+// NewTimer is like [time.NewTimer], but tracks the timer.
+func NewTimer(d time.Duration) *Timer {
+	return &Timer{Timer: time.NewTimer(d), r: _tracked.add("timer", d)}
+}
+
+// AfterFunc is like [time.AfterFunc], but tracks the timer.
+func AfterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{r: _tracked.add("timer", d)}
+	t.Timer = time.AfterFunc(d, func() {
+		_tracked.mu.Lock()
+		t.r.fired = true
+		_tracked.mu.Unlock()
+		f()
+	})
+	return t
+}
+
+// Stop stops the timer like [time.Timer.Stop] and stops tracking it.
+func (t *Timer) Stop() bool {
+	_tracked.remove(t.r)
+	return t.Timer.Stop()
+}
+
+// Reset changes the timer like [time.Timer.Reset]
+// and tracks it again until it is stopped or fires.
+func (t *Timer) Reset(d time.Duration) bool {
+	_tracked.reset(t.r, d)
+	return t.Timer.Reset(d)
+}
+
+// Ticker is a [time.Ticker] that is tracked until it is stopped.
+type Ticker struct {
+	*time.Ticker
+
+	r *record
+}
+
+// NewTicker is like [time.NewTicker], but tracks the ticker.
+func NewTicker(d time.Duration) *Ticker {
+	return &Ticker{Ticker: time.NewTicker(d), r: _tracked.add("ticker", d)}
+}
+
+// Stop stops the ticker like [time.Ticker.Stop] and stops tracking it.
+func (t *Ticker) Stop() {
+	_tracked.remove(t.r)
+	t.Ticker.Stop()
+}
+
+// Reset changes the period of the ticker like [time.Ticker.Reset]
+// and tracks it again until it is stopped.
+func (t *Ticker) Reset(d time.Duration) {
+	_tracked.reset(t.r, d)
+	t.Ticker.Reset(d)
+}
+
+// Find returns a descriptive error if any timers or tickers created
+// by this package are neither stopped nor fired.
+func Find() error {
+	rs := _tracked.pending()
+	if len(rs) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("found unstopped timers:\n")
+	for _, r := range rs {
+		fmt.Fprintf(&sb, "  %v (%v) created by %v\n", r.kind, r.d, r.created)
+	}
+	return errors.New(sb.String())
+}
+
+type testHelper interface {
+	Helper()
+}
+
+// VerifyNone marks the given TestingT as failed if any timers or tickers
+// created by this package are neither stopped nor fired.
+//
+//	defer timerleak.VerifyNone(t)
+func VerifyNone(t goleak.TestingT) {
+	if h, ok := t.(testHelper); ok {
+		h.Helper()
+	}
+
+	if err := Find(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package timerleak
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (ft *fakeT) Error(args ...interface{}) {
+	ft.errors = append(ft.errors, fmt.Sprint(args...))
+}
+
+func TestTicker(t *testing.T) {
+	ticker := NewTicker(time.Hour)
+	err := Find()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ticker (1h0m0s) created by github.com/projectdiscovery/goleak/timerleak.TestTicker")
+	assert.Contains(t, err.Error(), "timerleak_test.go:")
+
+	ticker.Stop()
+	assert.NoError(t, Find())
+
+	ticker.Reset(time.Minute)
+	assert.ErrorContains(t, Find(), "ticker (1m0s)")
+	ticker.Stop()
+	assert.NoError(t, Find())
+}
+
+func TestTimer(t *testing.T) {
+	timer := NewTimer(time.Hour)
+	assert.ErrorContains(t, Find(), "timer (1h0m0s) created by github.com/projectdiscovery/goleak/timerleak.TestTimer")
+	assert.True(t, timer.Stop())
+	assert.NoError(t, Find())
+
+	// Timers don't need to be stopped once they fired.
+	timer.Reset(time.Millisecond)
+	<-timer.C
+	assert.NoError(t, Find())
+}
+
+func TestAfterFunc(t *testing.T) {
+	fired := make(chan struct{})
+	timer := AfterFunc(time.Hour, func() { close(fired) })
+	assert.ErrorContains(t, Find(), "timer (1h0m0s)")
+
+	timer.Reset(0)
+	<-fired
+	assert.NoError(t, Find())
+}
+
+func TestVerifyNone(t *testing.T) {
+	ft := &fakeT{}
+	VerifyNone(ft)
+	assert.Empty(t, ft.errors)
+
+	ticker := NewTicker(time.Hour)
+	defer ticker.Stop()
+	VerifyNone(ft)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "found unstopped timers")
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/projectdiscovery/goleak/stack"
+)
+
+// Trends of the number of leaked goroutines with the same fingerprint
+// across the retries of a leak check, as reported by LeakedGoroutine.Trend.
+const (
+	TrendStable    = "stable"
+	TrendGrowing   = "growing"
+	TrendShrinking = "shrinking"
+	TrendChanging  = "changing"
+)
+
+// retryTrends records how many goroutines of each fingerprint remained
+// after each attempt of a leak check.
+type retryTrends struct {
+	attempts int
+	counts   map[string][]int // per fingerprint, one count per attempt
+}
+
+// observe records the goroutines remaining after an attempt.
+func (t *retryTrends) observe(stacks []stack.Stack) {
+	if t.counts == nil {
+		t.counts = make(map[string][]int)
+	}
+	for _, s := range stacks {
+		fp := s.Fingerprint()
+		counts, ok := t.counts[fp]
+		if !ok {
+			// None remained after the earlier attempts.
+			counts = make([]int, t.attempts)
+		}
+		if len(counts) == t.attempts {
+			counts = append(counts, 0)
+		}
+		counts[t.attempts]++
+		t.counts[fp] = counts
+	}
+	t.attempts++
+	for fp, counts := range t.counts {
+		if len(counts) < t.attempts {
+			t.counts[fp] = append(counts, 0)
+		}
+	}
+}
+
+// trend returns the trend of the goroutines with fingerprint fp, and
+// the counts it's based on. The trend is empty if there were no retries.
+func (t *retryTrends) trend(fp string) (trend string, counts []int) {
+	if t == nil || t.attempts < 2 {
+		return "", nil
+	}
+	counts = t.counts[fp]
+	if len(counts) == 0 {
+		return "", nil
+	}
+	first, last := counts[0], counts[len(counts)-1]
+	switch {
+	case last > first:
+		return TrendGrowing, counts
+	case last < first:
+		return TrendShrinking, counts
+	case slices.Min(counts) == slices.Max(counts):
+		return TrendStable, counts
+	default:
+		return TrendChanging, counts
+	}
+}
+
+// trendNotes describes how the number of leaked goroutines of each
+// fingerprint changed while the check retried, which tells goroutines
+// that are slowly shutting down apart from ones that never will.
+func trendNotes(stacks []stack.Stack, trends *retryTrends) string {
+	var sb strings.Builder
+	seen := make(map[string]bool)
+	for _, s := range stacks {
+		fp := s.Fingerprint()
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		trend, counts := trends.trend(fp)
+		if trend == "" {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\nretry trends:\n")
+		}
+
+		first, last := counts[0], counts[len(counts)-1]
+		retries := trends.retries()
+		fmt.Fprintf(&sb, "%v with %v on top: ", goroutineCount(last), s.FirstFunction())
+		switch trend {
+		case TrendStable:
+			fmt.Fprintf(&sb, "count stable across %v.\n", retries)
+		case TrendGrowing:
+			fmt.Fprintf(&sb, "count growing from %d across %v; more are still being started.\n", first, retries)
+		case TrendShrinking:
+			fmt.Fprintf(&sb, "count shrinking from %d across %v; this may be a slow shutdown rather than a leak.\n", first, retries)
+		case TrendChanging:
+			fmt.Fprintf(&sb, "count changing between %d and %d across %v.\n", slices.Min(counts), slices.Max(counts), retries)
+		}
+	}
+	return sb.String()
+}
+
+// retries describes the number of retries of the check, e.g. "20 retries".
+func (t *retryTrends) retries() string {
+	if t.attempts == 2 {
+		return "1 retry"
+	}
+	return fmt.Sprintf("%d retries", t.attempts-1)
+}
+
+// goroutineCount describes n goroutines, e.g. "3 goroutines".
+func goroutineCount(n int) string {
+	if n == 1 {
+		return "1 goroutine"
+	}
+	return fmt.Sprintf("%d goroutines", n)
+}
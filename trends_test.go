@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/goleak/stack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTrends(t *testing.T) {
+	stacks := rulesDump(t)
+	w1, w2, conn := stacks[0], stacks[1], stacks[2]
+	require.Equal(t, w1.Fingerprint(), w2.Fingerprint())
+
+	tests := []struct {
+		name     string
+		attempts [][]stack.Stack
+		want     string
+		counts   []int
+	}{
+		{"stable", [][]stack.Stack{{w1, w2}, {w1, w2}, {w2, w1}}, TrendStable, []int{2, 2, 2}},
+		{"growing", [][]stack.Stack{{conn}, {conn, w1}, {w1, w2}}, TrendGrowing, []int{0, 1, 2}},
+		{"shrinking", [][]stack.Stack{{w1, w2}, {w1}}, TrendShrinking, []int{2, 1}},
+		{"changing", [][]stack.Stack{{w1}, {conn}, {w1, w2}, {w1}}, TrendChanging, []int{1, 0, 2, 1}},
+		{"no retries", [][]stack.Stack{{w1, w2}}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trends := &retryTrends{}
+			for _, attempt := range tt.attempts {
+				trends.observe(attempt)
+			}
+			trend, counts := trends.trend(w1.Fingerprint())
+			assert.Equal(t, tt.want, trend)
+			assert.Equal(t, tt.counts, counts)
+		})
+	}
+
+	var trends *retryTrends
+	trend, _ := trends.trend(w1.Fingerprint())
+	assert.Empty(t, trend, "checks without attempts have no trends")
+}
+
+func TestTrendNotes(t *testing.T) {
+	stacks := rulesDump(t)
+	w1, w2, conn := stacks[0], stacks[1], stacks[2]
+
+	check := leakCheck{trends: &retryTrends{}}
+	for _, attempt := range [][]stack.Stack{{w1, w2}, {w1, w2, conn}, {w1, conn}} {
+		check.trends.observe(attempt)
+	}
+	assert.Equal(t, "\nretry trends:\n"+
+		"1 goroutine with main.worker on top: count shrinking from 2 across 2 retries; this may be a slow shutdown rather than a leak.\n"+
+		"1 goroutine with internal/poll.runtime_pollWait on top: count growing from 0 across 2 retries; more are still being started.\n",
+		trendNotes([]stack.Stack{w1, conn}, check.trends))
+
+	report := (&opts{}).report([]stack.Stack{w1, conn}, check)
+	assert.Equal(t, TrendShrinking, report.Leaks[0].Trend)
+	assert.Equal(t, TrendGrowing, report.Leaks[1].Trend)
+
+	assert.Empty(t, trendNotes(stacks, nil), "checks without retries have no trend notes")
+}
+
+func TestFindRetryTrends(t *testing.T) {
+	include := IncludeTopFunction("github.com/projectdiscovery/goleak.(*blockedG).block")
+	// Wait for those of other tests to exit, whose count would shrink.
+	require.NoError(t, Find(include))
+	bg := startBlockedG()
+	defer bg.unblock()
+
+	err := Find(testOptions(), include)
+	require.Error(t, err)
+	assert.Regexp(t, `\nretry trends:\n1 goroutine with github.com/projectdiscovery/goleak.\(\*blockedG\).block on top: `+
+		`count stable across \d+ retries.\n`, err.Error())
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import "github.com/projectdiscovery/goleak/stack"
+
+// MaxFramesPerStack limits each goroutine in leak reports to the n frames
+// at the top of its stack, in both plain and pretty output. Frames past
+// the limit are replaced by a line like "...12 frames elided...".
+// The "created by" line is always kept. Zero, the default, shows all
+// frames. Filters and hints still see the full stacks.
+func MaxFramesPerStack(n int) Option {
+	checkNotNegative("MaxFramesPerStack", n)
+	return optionFunc(func(opts *opts) {
+		opts.maxFrames = n
+	})
+}
+
+// HideRuntimeFrames drops the frames of functions of the runtime package,
+// such as runtime.gopark, from goroutines in leak reports, unless a stack
+// has no other frames. Combined with [MaxFramesPerStack], the limit
+// applies to the remaining frames.
+func HideRuntimeFrames() Option {
+	return optionFunc(func(opts *opts) {
+		opts.hideRuntimeFrames = true
+	})
+}
+
+// display returns the stacks trimmed for leak reports
+// according to MaxFramesPerStack and HideRuntimeFrames.
+func (o *opts) display(stacks []stack.Stack) []stack.Stack {
+	if o.maxFrames == 0 && !o.hideRuntimeFrames {
+		return stacks
+	}
+	trimmed := make([]stack.Stack, len(stacks))
+	for i, s := range stacks {
+		trimmed[i] = s.Trimmed(o.maxFrames, o.hideRuntimeFrames)
+	}
+	return trimmed
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrimStacks(t *testing.T) {
+	dump := []byte(strings.Join([]string{
+		"goroutine 7 [chan receive]:",
+		"runtime.gopark(0x0?, 0x0?, 0x0?, 0x0?, 0x0?)",
+		"	/usr/local/go/src/runtime/proc.go:402 +0xce",
+		"runtime.chanrecv1(0xc000010000?, 0x0?)",
+		"	/usr/local/go/src/runtime/chan.go:442 +0x12",
+		"main.worker()",
+		"	/app/main.go:20 +0x19",
+		"main.run()",
+		"	/app/main.go:30 +0x19",
+		"created by main.main in goroutine 1",
+		"	/app/main.go:10 +0x1d",
+		"",
+	}, "\n"))
+
+	err := FindInDump(dump)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "runtime.gopark(")
+	assert.NotContains(t, err.Error(), "elided")
+
+	err = FindInDump(dump, HideRuntimeFrames())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "runtime.gopark(")
+	assert.Contains(t, err.Error(), "main.run()")
+	assert.Contains(t, err.Error(), "...2 frames elided...")
+
+	err = FindInDump(dump, HideRuntimeFrames(), MaxFramesPerStack(1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main.worker()")
+	assert.NotContains(t, err.Error(), "main.run()")
+	assert.Contains(t, err.Error(), "...3 frames elided...")
+	assert.Contains(t, err.Error(), "created by main.main", "the creator should be kept")
+
+	err = FindInDump(dump, MaxFramesPerStack(1), Pretty())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "main.worker()")
+
+	err = FindInDump(dump, MaxFramesPerStack(1), IgnoreTopFunction("main.worker"))
+	assert.Error(t, err, "filters should see the full stack")
+}
+
+func TestMaxFramesPerStackInvalid(t *testing.T) {
+	_, err := CheckedOption(func() Option { return MaxFramesPerStack(-1) })
+	assert.ErrorContains(t, err, "MaxFramesPerStack: negative value -1")
+}
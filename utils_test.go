@@ -56,6 +56,23 @@ func (bg *blockedG) unblock() {
 	close(bg.wait)
 }
 
+// blockedStack returns the stack of a goroutine started by startBlockedG.
+func blockedStack(t *testing.T) stack.Stack {
+	for _, s := range stack.All() {
+		if s.FirstFunction() == "github.com/projectdiscovery/goleak.(*blockedG).block" {
+			return s
+		}
+	}
+	t.Fatal("blockedG goroutine not found")
+	return stack.Stack{}
+}
+
+// waitForStable waits for goroutines of earlier tests to finish exiting,
+// for tests that take a single snapshot of goroutines without retries.
+func waitForStable(t *testing.T) {
+	getStableAll(t, stack.Current())
+}
+
 func getStableAll(t *testing.T, cur stack.Stack) []stack.Stack {
 	all := stack.All()
 
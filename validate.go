@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OptionError describes an option that was constructed with invalid
+// arguments. Option constructors panic with an *OptionError when they
+// are misused, since such options would otherwise silently match
+// nothing, or everything. Use [CheckedOption] to get it as an error.
+//
+// Constructors that predate argument validation, like
+// [IgnoreTopFunction], don't panic, to stay compatible; their problems
+// are only reported by [CheckedOption].
+type OptionError struct {
+	// Option is the name of the constructor, e.g. "IgnoreTopFunction".
+	Option string
+	// Err describes what is wrong with its arguments.
+	Err error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("goleak: %v: %v", e.Option, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// invalidOption panics with an *OptionError for the given constructor.
+func invalidOption(option, format string, args ...interface{}) {
+	panic(&OptionError{Option: option, Err: fmt.Errorf(format, args...)})
+}
+
+// CheckedOption calls build, which should construct an option,
+// and returns an error instead of panicking if the arguments
+// of the option are invalid:
+//
+//	opt, err := goleak.CheckedOption(func() goleak.Option {
+//		return goleak.IgnoreFunctionGlob(cfg.Pattern)
+//	})
+//
+// This is useful for options built from configuration or user input.
+// Other panics are propagated.
+func CheckedOption(build func() Option) (opt Option, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var optErr *OptionError
+			if e, ok := r.(error); !ok || !errors.As(e, &optErr) {
+				panic(r)
+			}
+			opt, err = nil, optErr
+		}
+	}()
+	opt = build()
+	if o, ok := opt.(legacyOption); ok {
+		return nil, o.err
+	}
+	return opt, nil
+}
+
+// legacyOption is an option from a constructor that predates argument
+// validation, built with invalid arguments. It applies like before,
+// and CheckedOption reports err.
+type legacyOption struct {
+	Option
+
+	err *OptionError
+}
+
+// checkLegacy runs check, which validates the arguments of a
+// constructor that predates argument validation, and returns opt with
+// the *OptionError it panics with, if any, instead of panicking.
+func checkLegacy(opt Option, check func()) (checked Option) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*OptionError)
+			if !ok {
+				panic(r)
+			}
+			checked = legacyOption{Option: opt, err: err}
+		}
+	}()
+	check()
+	return opt
+}
+
+// checkFunctionName validates the function name given to option.
+func checkFunctionName(option, name string) {
+	checkName(option, "function", name)
+	if strings.HasSuffix(name, ")") {
+		invalidOption(option, "function name %q must not include arguments", name)
+	}
+}
+
+// checkName validates a package, struct or function name given to
+// option. Names in stack traces are never empty and don't contain spaces.
+func checkName(option, kind, name string) {
+	if name == "" {
+		invalidOption(option, "empty %v name", kind)
+	}
+	if strings.ContainsAny(name, " \t\n") {
+		invalidOption(option, "%v name %q contains whitespace", kind, name)
+	}
+}
+
+// checkNotNegative validates a count given to option.
+func checkNotNegative(option string, n int) {
+	if n < 0 {
+		invalidOption(option, "negative value %d", n)
+	}
+}
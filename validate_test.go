@@ -0,0 +1,118 @@
+// Copyright (c) 2026 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package goleak
+
+import (
+	"errors"
+	"expvar"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// An expvar that isn't a map, whose name PublishExpvar rejects.
+var _ = expvar.NewInt("goleak_test_int")
+
+func TestOptionValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() Option
+		want  string
+	}{
+		{"function with spaces", func() Option { return IncludeTopFunction("main. worker") }, `goleak: IncludeTopFunction: function name "main. worker" contains whitespace`},
+		{"empty creator", func() Option { return IncludeCreatedBy("") }, "goleak: IncludeCreatedBy: empty function name"},
+		{"empty included struct", func() Option { return IncludeAnyContainingStruct("") }, "goleak: IncludeAnyContainingStruct: empty struct name"},
+		{"empty module", func() Option { return IgnoreModule("") }, "goleak: IgnoreModule: empty module name"},
+		{"empty text", func() Option { return IgnoreStackContaining("") }, "goleak: IgnoreStackContaining: empty text would match every goroutine"},
+		{"multi-line text", func() Option { return IgnoreStackContaining("a\nb") }, `goleak: IgnoreStackContaining: text "a\nb" spans lines`},
+		{"nil regexp", func() Option { return IgnoreFunctionRegexp(nil) }, "goleak: IgnoreFunctionRegexp: nil regexp"},
+		{"empty glob", func() Option { return IgnoreFunctionGlob("") }, "goleak: IgnoreFunctionGlob: empty pattern"},
+		{"bad glob", func() Option { return IgnoreFile("[") }, `goleak: IgnoreFile: bad glob pattern "[": syntax error in pattern`},
+		{"empty state", func() Option { return FailFastStates("select", "") }, "goleak: FailFastStates: empty state"},
+		{"negative dump size", func() Option { return MaxDumpBytes(-1) }, "goleak: MaxDumpBytes: negative value -1"},
+		{"negative thread growth", func() Option { return AllowedThreadGrowth(-2) }, "goleak: AllowedThreadGrowth: negative value -2"},
+		{"unknown pool detector", func() Option { return IgnorePoolWorkers("ants", "workerpool") }, `goleak: IgnorePoolWorkers: unknown pool detector "workerpool"`},
+		{"negative age", func() Option { return (*AgeTracker)(nil).IgnoreYoungerThan(-time.Second) }, "goleak: IgnoreYoungerThan: negative age -1s"},
+		{"empty timeout dump path", func() Option { return DumpOnTimeout("") }, "goleak: DumpOnTimeout: empty path"},
+		{"empty expvar name", func() Option { return PublishExpvar("") }, "goleak: PublishExpvar: empty name"},
+		{"expvar name taken", func() Option { return PublishExpvar("goleak_test_int") }, `goleak: PublishExpvar: name "goleak_test_int" is already published as a *expvar.Int`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := CheckedOption(tt.build)
+			assert.Nil(t, opt)
+			require.EqualError(t, err, tt.want)
+
+			var optErr *OptionError
+			require.True(t, errors.As(err, &optErr))
+			assert.PanicsWithError(t, tt.want, func() { tt.build() })
+		})
+	}
+}
+
+func TestLegacyOptionValidation(t *testing.T) {
+	// Constructors that predate validation keep accepting bad arguments,
+	// and only CheckedOption reports them.
+	tests := []struct {
+		name  string
+		build func() Option
+		want  string
+	}{
+		{"empty top function", func() Option { return IgnoreTopFunction("") }, "goleak: IgnoreTopFunction: empty function name"},
+		{"function with args", func() Option { return IgnoreAnyFunction("main.worker(0x1)") }, `goleak: IgnoreAnyFunction: function name "main.worker(0x1)" must not include arguments`},
+		{"empty package", func() Option { return IgnoreAnyContainingPkg("") }, "goleak: IgnoreAnyContainingPkg: empty package name"},
+		{"empty included package", func() Option { return IncludeAllContainingPkg("") }, "goleak: IncludeAllContainingPkg: empty package name"},
+		{"empty struct", func() Option { return IgnoreAnyContainingStruct("") }, "goleak: IgnoreAnyContainingStruct: empty struct name"},
+		{"empty entry", func() Option { return IgnoreAnyEntry("") }, "goleak: IgnoreAnyEntry: empty text would match every goroutine"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := CheckedOption(tt.build)
+			assert.Nil(t, opt)
+			require.EqualError(t, err, tt.want)
+
+			var optErr *OptionError
+			require.True(t, errors.As(err, &optErr))
+			assert.NotPanics(t, func() {
+				assert.NoError(t, Find(testOptions(), tt.build()))
+			})
+		})
+	}
+}
+
+func TestCheckedOption(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		opt, err := CheckedOption(func() Option {
+			return IgnoreFunctionRegexp(regexp.MustCompile(`^example\.com/`))
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, opt)
+	})
+
+	t.Run("other panics", func(t *testing.T) {
+		assert.PanicsWithValue(t, "great sadness", func() {
+			_, _ = CheckedOption(func() Option { panic("great sadness") })
+		})
+	})
+}